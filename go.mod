@@ -3,6 +3,7 @@ module github.com/flarebyte/clingy-code-detective
 go 1.24.1
 
 require (
-	github.com/Masterminds/semver/v3 v3.3.1 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/Masterminds/semver/v3 v3.3.1
+	github.com/pelletier/go-toml/v2 v2.4.3
+	gopkg.in/yaml.v3 v3.0.1
 )
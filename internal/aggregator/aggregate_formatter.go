@@ -5,35 +5,70 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"html"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
 )
 
-// JSONAggregateRenderer implements AggregateRenderer for JSON output.
-type JSONAggregateRenderer struct{}
+// column is a column of a CSV or Markdown report.
+type column[T any] struct {
+	header string
+	value  func(T) string
+}
 
-func (r *JSONAggregateRenderer) Render(deps []AggregatedDependency) ([]byte, error) {
-	return json.MarshalIndent(deps, "", "  ")
+// groupColumns returns the columns of the group keys, or of the default
+// ones when empty, which come before and after the other columns.
+func groupColumns(keys []GroupKey) (leading, trailing []column[AggregatedDependency]) {
+	if hasGroupField(keys, GroupByName) {
+		leading = append(leading, column[AggregatedDependency]{"Name", func(d AggregatedDependency) string { return d.Name }})
+	}
+	if hasGroupField(keys, GroupByDirectory) {
+		leading = append(leading, column[AggregatedDependency]{"Directory", func(d AggregatedDependency) string { return d.Directory }})
+	}
+	if hasGroupField(keys, GroupByPath) {
+		leading = append(leading, column[AggregatedDependency]{"PathPrefix", func(d AggregatedDependency) string { return d.PathPrefix }})
+	}
+	if hasGroupField(keys, GroupByCategory) {
+		trailing = append(trailing, column[AggregatedDependency]{"Category", func(d AggregatedDependency) string { return d.Category }})
+	}
+	if hasGroupField(keys, GroupByPackaging) {
+		trailing = append(trailing, column[AggregatedDependency]{"Packaging", func(d AggregatedDependency) string { return d.Packaging }})
+	}
+	return leading, trailing
 }
 
-// CSVAggregateRenderer implements AggregateRenderer for CSV output.
-type CSVAggregateRenderer struct{}
+// aggregateColumns returns the columns of an aggregated report.
+func aggregateColumns(keys []GroupKey) []column[AggregatedDependency] {
+	leading, trailing := groupColumns(keys)
+	columns := append(leading,
+		column[AggregatedDependency]{"MinVersion", func(d AggregatedDependency) string { return d.MinVersion }},
+		column[AggregatedDependency]{"MaxVersion", func(d AggregatedDependency) string { return d.MaxVersion }},
+		column[AggregatedDependency]{"Satisfiable", func(d AggregatedDependency) string { return string(d.Satisfiable) }},
+		column[AggregatedDependency]{"Count", func(d AggregatedDependency) string { return fmt.Sprint(d.Count) }},
+		column[AggregatedDependency]{"Projects", func(d AggregatedDependency) string { return fmt.Sprint(d.Projects) }},
+	)
+	return append(columns, trailing...)
+}
 
-func (r *CSVAggregateRenderer) Render(deps []AggregatedDependency) ([]byte, error) {
+// renderCSV renders the rows as CSV with the given columns, followed by the
+// diagnostics as comment lines.
+func renderCSV[T any](columns []column[T], rows []T, diags []diagnostic.Diagnostic) ([]byte, error) {
 	var buf bytes.Buffer
 	writer := csv.NewWriter(&buf)
 
-	header := []string{"Name", "MinVersion", "MaxVersion", "Count", "Category", "Packaging"}
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.header
+	}
 	if err := writer.Write(header); err != nil {
 		return nil, fmt.Errorf("error writing CSV header: %w", err)
 	}
 
-	for _, dep := range deps {
-		record := []string{
-			dep.Name,
-			dep.MinVersion,
-			dep.MaxVersion,
-			fmt.Sprint(dep.Count),
-			dep.Category,
-			dep.Packaging,
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = c.value(row)
 		}
 		if err := writer.Write(record); err != nil {
 			return nil, fmt.Errorf("error writing CSV record: %w", err)
@@ -44,30 +79,119 @@ func (r *CSVAggregateRenderer) Render(deps []AggregatedDependency) ([]byte, erro
 	if err := writer.Error(); err != nil {
 		return nil, fmt.Errorf("error flushing CSV writer: %w", err)
 	}
+	writeCommentDiagnostics(&buf, diags, csvComment)
 
 	return buf.Bytes(), nil
 }
 
-// MarkdownAggregateRenderer implements AggregateRenderer for Markdown output.
-type MarkdownAggregateRenderer struct{}
+// writeMarkdownTable writes the rows as a Markdown table with the given
+// columns.
+func writeMarkdownTable[T any](buf *bytes.Buffer, columns []column[T], rows []T) {
+	headers := make([]string, len(columns))
+	separators := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.header
+		separators[i] = strings.Repeat("-", len(c.header))
+	}
+	buf.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	buf.WriteString("| " + strings.Join(separators, " | ") + " |\n")
+
+	cells := make([]string, len(columns))
+	for _, row := range rows {
+		for i, c := range columns {
+			cells[i] = EscapeMarkdown(c.value(row))
+		}
+		buf.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+}
+
+// JSONAggregateRenderer implements AggregateRenderer for JSON output.
+type JSONAggregateRenderer struct{}
+
+func (r *JSONAggregateRenderer) Render(deps []AggregatedDependency, diags []diagnostic.Diagnostic) ([]byte, error) {
+	return json.MarshalIndent(newJSONReport(deps, diags), "", "  ")
+}
+
+// CSVAggregateRenderer implements AggregateRenderer for CSV output, with a
+// column per group key.
+type CSVAggregateRenderer struct {
+	GroupBy []GroupKey // DefaultGroupKeys when empty
+}
+
+func (r *CSVAggregateRenderer) Render(deps []AggregatedDependency, diags []diagnostic.Diagnostic) ([]byte, error) {
+	return renderCSV(aggregateColumns(r.GroupBy), deps, diags)
+}
+
+// MarkdownAggregateRenderer implements AggregateRenderer for Markdown output,
+// with a column per group key.
+type MarkdownAggregateRenderer struct {
+	GroupBy []GroupKey // DefaultGroupKeys when empty
+}
 
-func (r *MarkdownAggregateRenderer) Render(deps []AggregatedDependency) ([]byte, error) {
+func (r *MarkdownAggregateRenderer) Render(deps []AggregatedDependency, diags []diagnostic.Diagnostic) ([]byte, error) {
 	var buf bytes.Buffer
 
-	buf.WriteString("| Name | MinVersion | MaxVersion | Count | Category | Packaging |\n")
-	buf.WriteString("| ---- | ---------- | ---------- | ----- | -------- | --------- |\n")
+	writeMarkdownTable(&buf, aggregateColumns(r.GroupBy), deps)
+	writeMarkdownVersions(&buf, deps)
+	writeMarkdownDiagnostics(&buf, diags)
+
+	return buf.Bytes(), nil
+}
+
+// groupLabel returns the label of an aggregated dependency, e.g.
+// "lodash (prod, node)".
+func groupLabel(dep AggregatedDependency) string {
+	var names, details []string
+	for _, v := range []string{dep.Name, dep.Directory, dep.PathPrefix} {
+		if v != "" {
+			names = append(names, v)
+		}
+	}
+	for _, v := range []string{dep.Category, dep.Packaging} {
+		if v != "" {
+			details = append(details, v)
+		}
+	}
+	label := strings.Join(names, " ")
+	if label == "" {
+		label = "all"
+	}
+	if len(details) > 0 {
+		label += " (" + strings.Join(details, ", ") + ")"
+	}
+	return label
+}
 
+// writeMarkdownVersions appends a Versions section with an expandable
+// breakdown of the versions of each dependency.
+func writeMarkdownVersions(buf *bytes.Buffer, deps []AggregatedDependency) {
+	if len(deps) == 0 {
+		return
+	}
+	buf.WriteString("\n## Versions\n")
 	for _, dep := range deps {
-		row := fmt.Sprintf("| %s | %s | %s | %d | %s | %s |\n",
-			EscapeMarkdown(dep.Name),
-			EscapeMarkdown(dep.MinVersion),
-			EscapeMarkdown(dep.MaxVersion),
-			dep.Count,
-			EscapeMarkdown(dep.Category),
-			EscapeMarkdown(dep.Packaging),
+		versions := "versions"
+		if len(dep.Versions) == 1 {
+			versions = "version"
+		}
+		fmt.Fprintf(buf, "\n<details>\n<summary>%s: %d %s</summary>\n\n",
+			html.EscapeString(groupLabel(dep)),
+			len(dep.Versions),
+			versions,
 		)
-		buf.WriteString(row)
+		buf.WriteString("| Version | Count | Paths |\n")
+		buf.WriteString("| ------- | ----- | ----- |\n")
+		for _, v := range dep.Versions {
+			paths := make([]string, 0, len(v.Paths))
+			for _, p := range v.Paths {
+				paths = append(paths, EscapeMarkdown(p))
+			}
+			fmt.Fprintf(buf, "| %s | %d | %s |\n",
+				EscapeMarkdown(v.Version),
+				v.Count,
+				strings.Join(paths, "<br>"),
+			)
+		}
+		buf.WriteString("\n</details>\n")
 	}
-
-	return buf.Bytes(), nil
 }
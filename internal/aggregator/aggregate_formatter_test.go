@@ -2,59 +2,79 @@ package aggregator
 
 import (
 	"encoding/json"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
 )
 
 var sampleDeps = []AggregatedDependency{
 	{
-		Name:       "dep1",
-		MinVersion: "1.0.0",
-		MaxVersion: "2.0.0",
-		Count:      3,
-		Category:   "prod",
-		Packaging:  "node",
+		Name:        "dep1",
+		MinVersion:  "1.0.0",
+		MaxVersion:  "2.0.0",
+		Satisfiable: Unsatisfiable,
+		Count:       3,
+		Projects:    3,
+		Category:    "prod",
+		Packaging:   "node",
+		Versions: []VersionUsage{
+			{Version: "1.0.0", Count: 1, Paths: []string{"a/package.json:3"}},
+			{Version: "2.0.0", Count: 2, Paths: []string{"b/package.json:4", "c/package.json:4"}},
+		},
 	},
 	{
-		Name:       "dep2",
-		MinVersion: "0.1.0",
-		MaxVersion: "1.2.3",
-		Count:      1,
-		Category:   "dev",
-		Packaging:  "python",
+		Name:        "dep2",
+		MinVersion:  "0.1.0",
+		MaxVersion:  "1.2.3",
+		Satisfiable: Satisfiable,
+		Count:       1,
+		Projects:    1,
+		Category:    "dev",
+		Packaging:   "python",
 	},
 }
 
 func TestJSONAggregateRenderer_Render(t *testing.T) {
 	r := &JSONAggregateRenderer{}
-	out, err := r.Render(sampleDeps)
+	out, err := r.Render(sampleDeps, sampleDiagnostics())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	var unmarshalled []AggregatedDependency
+	var unmarshalled jsonReport[AggregatedDependency]
 	if err := json.Unmarshal(out, &unmarshalled); err != nil {
 		t.Fatalf("failed to unmarshal output: %v", err)
 	}
 
-	if len(unmarshalled) != len(sampleDeps) {
-		t.Errorf("expected %d dependencies, got %d", len(sampleDeps), len(unmarshalled))
+	if len(unmarshalled.Dependencies) != len(sampleDeps) {
+		t.Errorf("expected %d dependencies, got %d", len(sampleDeps), len(unmarshalled.Dependencies))
+	}
+	if len(unmarshalled.Diagnostics) != 1 || unmarshalled.Diagnostics[0].Code != diagnostic.CodeParse {
+		t.Errorf("expected the parse error in diagnostics, got %+v", unmarshalled.Diagnostics)
+	}
+	if !reflect.DeepEqual(unmarshalled.Dependencies[0].Versions, sampleDeps[0].Versions) {
+		t.Errorf("expected the versions breakdown, got %+v", unmarshalled.Dependencies[0].Versions)
 	}
 }
 
 func TestCSVAggregateRenderer_Render(t *testing.T) {
 	r := &CSVAggregateRenderer{}
-	out, err := r.Render(sampleDeps)
+	out, err := r.Render(sampleDeps, sampleDiagnostics())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	if len(lines) != len(sampleDeps)+1 {
-		t.Errorf("expected %d lines, got %d", len(sampleDeps)+1, len(lines))
+	if len(lines) != len(sampleDeps)+2 {
+		t.Errorf("expected %d lines, got %d", len(sampleDeps)+2, len(lines))
+	}
+	if !strings.HasPrefix(lines[len(lines)-1], "# error[parse-error]") {
+		t.Errorf("expected the parse error as a trailing comment, got %q", lines[len(lines)-1])
 	}
 
-	header := "Name,MinVersion,MaxVersion,Count,Category,Packaging"
+	header := "Name,MinVersion,MaxVersion,Satisfiable,Count,Projects,Category,Packaging"
 	if lines[0] != header {
 		t.Errorf("expected header %q, got %q", header, lines[0])
 	}
@@ -62,12 +82,16 @@ func TestCSVAggregateRenderer_Render(t *testing.T) {
 
 func TestMarkdownAggregateRenderer_Render(t *testing.T) {
 	r := &MarkdownAggregateRenderer{}
-	out, err := r.Render(sampleDeps)
+	out, err := r.Render(sampleDeps, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	table, versions, found := strings.Cut(string(out), "\n## Versions\n")
+	if !found {
+		t.Fatalf("expected a Versions section, got:\n%s", out)
+	}
+	lines := strings.Split(strings.TrimSpace(table), "\n")
 	expectedLineCount := len(sampleDeps) + 2 // header + separator + rows
 	if len(lines) != expectedLineCount {
 		t.Errorf("expected %d lines, got %d", expectedLineCount, len(lines))
@@ -76,4 +100,14 @@ func TestMarkdownAggregateRenderer_Render(t *testing.T) {
 	if !strings.HasPrefix(lines[0], "| Name |") {
 		t.Errorf("unexpected markdown header: %s", lines[0])
 	}
+
+	for _, want := range []string{
+		"<summary>dep1 (prod, node): 2 versions</summary>",
+		"| 2.0.0 | 2 | b/package.json:4<br>c/package.json:4 |",
+		"<summary>dep2 (dev, python): 0 versions</summary>",
+	} {
+		if !strings.Contains(versions, want) {
+			t.Errorf("expected %q in the Versions section, got:\n%s", want, versions)
+		}
+	}
 }
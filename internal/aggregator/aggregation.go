@@ -2,94 +2,184 @@ package aggregator
 
 import (
 	"sort"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
 )
 
-// AggregateDependencies aggregates a slice of FlatDependency into AggregatedDependency.
+// AggregateDependencies aggregates a slice of FlatDependency into
+// AggregatedDependency, grouping the dependencies with the same name,
+// category and packaging.
 func AggregateDependencies(deps []FlatDependency) []AggregatedDependency {
-	type aggState struct {
-		minVersion string
-		maxVersion string
-		count      uint
-	}
+	return GroupDependencies(deps, Grouping{})
+}
 
-	// Grouping key: name + category + packaging
-	keyFor := func(d FlatDependency) string {
-		return d.Name + "|" + d.Category + "|" + d.Packaging
+// GroupDependencies aggregates the dependencies with the same group keys.
+// Versions are compared with the VersionComparator of their packaging, or as
+// semantic versions when a group mixes packagings.
+func GroupDependencies(deps []FlatDependency, grouping Grouping) []AggregatedDependency {
+	keys := grouping.keys()
+
+	groups := make(map[string][]FlatDependency)
+	var order []string
+	for _, d := range deps {
+		values := make([]string, len(keys))
+		for i, k := range keys {
+			values[i] = k.value(d, grouping.Roots)
+		}
+		key := strings.Join(values, "\x00")
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], d)
 	}
 
-	state := make(map[string]*aggState)
-	meta := make(map[string]struct {
-		Name      string
-		Category  string
-		Packaging string
-	})
+	result := make([]AggregatedDependency, 0, len(groups))
+	for _, key := range order {
+		result = append(result, aggregateGroup(groups[key], keys, grouping.Roots))
+	}
 
-	for _, d := range deps {
-		key := keyFor(d)
-
-		if _, ok := state[key]; !ok {
-			state[key] = &aggState{}
-			meta[key] = struct {
-				Name      string
-				Category  string
-				Packaging string
-			}{
-				Name:      d.Name,
-				Category:  d.Category,
-				Packaging: d.Packaging,
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		for _, c := range [][2]string{
+			{a.Name, b.Name},
+			{a.Directory, b.Directory},
+			{a.PathPrefix, b.PathPrefix},
+			{a.Category, b.Category},
+			{a.Packaging, b.Packaging},
+		} {
+			if c[0] != c[1] {
+				return c[0] < c[1]
 			}
 		}
+		return false
+	})
 
-		agg := state[key]
+	return result
+}
 
-		if agg.minVersion == "" {
-			agg.minVersion = d.Version
-		} else {
-			minV, err := MinVersion(agg.minVersion, d.Version)
-			if err == nil {
-				agg.minVersion = minV.String()
-			}
+// aggregateGroup aggregates the dependencies of a group.
+func aggregateGroup(deps []FlatDependency, keys []GroupKey, roots []string) AggregatedDependency {
+	first := deps[0]
+	ad := AggregatedDependency{
+		Name:      first.Name,
+		Category:  first.Category,
+		Packaging: first.Packaging,
+		Count:     uint(len(deps)),
+	}
+	for _, k := range keys {
+		switch k.Field {
+		case GroupByDirectory:
+			ad.Directory = k.value(first, roots)
+		case GroupByPath:
+			ad.PathPrefix = k.value(first, roots)
 		}
+	}
 
-		if agg.maxVersion == "" {
-			agg.maxVersion = d.Version
-		} else {
-			maxV, err := MaxVersion(agg.maxVersion, d.Version)
-			if err == nil {
-				agg.maxVersion = maxV.String()
-			}
+	versions := make(map[string]*VersionUsage)
+	projects := make(map[[2]string]struct{})
+	for _, d := range deps {
+		projects[[2]string{parser.ProjectRoot(d.Path, d.Packaging), d.Packaging}] = struct{}{}
+
+		// Fields which are not keys are cleared unless shared by the group
+		if d.Name != ad.Name {
+			ad.Name = ""
+		}
+		if d.Category != ad.Category {
+			ad.Category = ""
+		}
+		if d.Packaging != ad.Packaging {
+			ad.Packaging = ""
 		}
 
-		agg.count++
+		usage, ok := versions[d.Version]
+		if !ok {
+			usage = &VersionUsage{Version: d.Version}
+			versions[d.Version] = usage
+		}
+		usage.Count++
+		usage.Paths = append(usage.Paths, d.Location())
 	}
-
-	// Build final result
-	var result []AggregatedDependency
-	for key, agg := range state {
-		m := meta[key]
-
-		ad := AggregatedDependency{
-			Name:       m.Name,
-			Category:   m.Category,
-			Packaging:  m.Packaging,
-			Count:      agg.count,
-			MinVersion: agg.minVersion,
-			MaxVersion: agg.maxVersion,
+	ad.Projects = uint(len(projects))
+	comparator := ComparatorFor(ad.Packaging)
+	ad.Versions = sortedVersions(comparator, versions)
+	ad.MinVersion = ad.Versions[0].Version
+	ad.MaxVersion = ad.Versions[len(ad.Versions)-1].Version
+	for _, v := range ad.Versions {
+		// Declarations which are not versions are only reported when none is
+		if _, ok := declaredLowerBound(comparator, v.Version); ok {
+			ad.MinVersion = v.Version
+			break
 		}
+	}
+	ad.Satisfiable = satisfiability(comparator, ad.Versions)
+	return ad
+}
 
-		result = append(result, ad)
+// satisfiability tells whether one version satisfies every declared version.
+func satisfiability(comparator VersionComparator, versions []VersionUsage) Satisfiability {
+	var common *Constraint
+	for _, v := range versions {
+		c, err := comparator.ParseConstraint(v.Version)
+		if err != nil {
+			return UnknownSatisfiability
+		}
+		if common == nil {
+			common = &c
+		} else {
+			intersection := common.Intersect(c)
+			common = &intersection
+		}
 	}
+	if common != nil && common.Satisfiable() {
+		return Satisfiable
+	}
+	return Unsatisfiable
+}
 
-	// Optional: sort result by name for stability
+// sortedVersions returns the usages of the versions of a dependency, sorted
+// like MinVersion and MaxVersion, with their paths sorted.
+func sortedVersions(comparator VersionComparator, versions map[string]*VersionUsage) []VersionUsage {
+	result := make([]VersionUsage, 0, len(versions))
+	for _, usage := range versions {
+		sort.Strings(usage.Paths)
+		result = append(result, *usage)
+	}
 	sort.Slice(result, func(i, j int) bool {
-		if result[i].Name != result[j].Name {
-			return result[i].Name < result[j].Name
+		return compareDeclared(comparator, result[i].Version, result[j].Version) < 0
+	})
+	return result
+}
+
+// compareDeclared compares two declared versions or ranges by their lower
+// bounds. Declarations which are not versions come first, in the order of
+// the comparator.
+func compareDeclared(comparator VersionComparator, a, b string) int {
+	boundA, okA := declaredLowerBound(comparator, a)
+	boundB, okB := declaredLowerBound(comparator, b)
+	switch {
+	case okA && okB:
+		if c := compareLower(boundA, boundB); c != 0 {
+			return c
 		}
-		if result[i].Category != result[j].Category {
-			return result[i].Category < result[j].Category
+	case okA:
+		return 1
+	case okB:
+		return -1
+	default:
+		if c := comparator.Compare(a, b); c != 0 {
+			return c
 		}
-		return result[i].Packaging < result[j].Packaging
-	})
+	}
+	return strings.Compare(a, b)
+}
 
-	return result
+// declaredLowerBound returns the lower bound of a declaration, or false when
+// it is not a version or allows none.
+func declaredLowerBound(comparator VersionComparator, declared string) (Version, bool) {
+	c, err := comparator.ParseConstraint(declared)
+	if err != nil {
+		return nil, false
+	}
+	return c.LowerBound()
 }
@@ -21,7 +21,7 @@ func TestAggregateDependencies(t *testing.T) {
 			want: []AggregatedDependency{
 				{
 					Name: "foo", Category: "prod", Packaging: "node",
-					Count: 3, MinVersion: "1.0.0", MaxVersion: "1.2.0",
+					Count: 3, Projects: 1, MinVersion: "1.0.0", MaxVersion: "1.2.0", Satisfiable: Unsatisfiable,
 				},
 			},
 		},
@@ -36,11 +36,97 @@ func TestAggregateDependencies(t *testing.T) {
 			want: []AggregatedDependency{
 				{
 					Name: "bar", Category: "dev", Packaging: "python",
-					Count: 2, MinVersion: "2.0.0", MaxVersion: "2.1.0",
+					Count: 2, Projects: 1, MinVersion: "2.0.0", MaxVersion: "2.1.0", Satisfiable: Unsatisfiable,
 				},
 				{
 					Name: "foo", Category: "prod", Packaging: "node",
-					Count: 2, MinVersion: "1.0.0", MaxVersion: "1.2.0",
+					Count: 2, Projects: 1, MinVersion: "1.0.0", MaxVersion: "1.2.0", Satisfiable: Unsatisfiable,
+				},
+			},
+		},
+		{
+			name: "ranges compared by lower bound",
+			input: []FlatDependency{
+				{Name: "foo", Version: "^1.4.0", Category: "prod", Packaging: "node"},
+				{Name: "foo", Version: ">=1.2 <2", Category: "prod", Packaging: "node"},
+				{Name: "foo", Version: "~1.5.2", Category: "prod", Packaging: "node"},
+			},
+			want: []AggregatedDependency{
+				{
+					Name: "foo", Category: "prod", Packaging: "node",
+					Count: 3, Projects: 1, MinVersion: ">=1.2 <2", MaxVersion: "~1.5.2", Satisfiable: Satisfiable,
+				},
+			},
+		},
+		{
+			name: "ranges without a common version",
+			input: []FlatDependency{
+				{Name: "foo", Version: "^1.4.0", Category: "prod", Packaging: "node"},
+				{Name: "foo", Version: "^2.0.0", Category: "prod", Packaging: "node"},
+			},
+			want: []AggregatedDependency{
+				{
+					Name: "foo", Category: "prod", Packaging: "node",
+					Count: 2, Projects: 1, MinVersion: "^1.4.0", MaxVersion: "^2.0.0", Satisfiable: Unsatisfiable,
+				},
+			},
+		},
+		{
+			name: "declarations which are not versions",
+			input: []FlatDependency{
+				{Name: "foo", Version: "git+https://example.com/foo.git", Category: "prod", Packaging: "node"},
+				{Name: "foo", Version: "^1.0.0", Category: "prod", Packaging: "node"},
+				{Name: "bar", Version: "file:../bar", Category: "prod", Packaging: "node"},
+			},
+			want: []AggregatedDependency{
+				{
+					Name: "bar", Category: "prod", Packaging: "node",
+					Count: 1, Projects: 1, MinVersion: "file:../bar", MaxVersion: "file:../bar", Satisfiable: UnknownSatisfiability,
+				},
+				{
+					Name: "foo", Category: "prod", Packaging: "node",
+					Count: 2, Projects: 1, MinVersion: "^1.0.0", MaxVersion: "^1.0.0", Satisfiable: UnknownSatisfiability,
+				},
+			},
+		},
+		{
+			name: "declarations which are all opaque",
+			input: []FlatDependency{
+				{Name: "foo", Version: "file:../foo-10", Category: "prod", Packaging: "node"},
+				{Name: "foo", Version: "file:../foo-9", Category: "prod", Packaging: "node"},
+			},
+			want: []AggregatedDependency{
+				{
+					Name: "foo", Category: "prod", Packaging: "node",
+					Count: 2, Projects: 1, MinVersion: "file:../foo-9", MaxVersion: "file:../foo-10", Satisfiable: UnknownSatisfiability,
+				},
+			},
+		},
+		{
+			name: "versions compared with the scheme of the packaging",
+			input: []FlatDependency{
+				{Name: "requests", Version: "2.0rc1", Category: "prod", Packaging: "python"},
+				{Name: "requests", Version: "1.0.post1", Category: "prod", Packaging: "python"},
+				{Name: "requests", Version: "1.0", Category: "prod", Packaging: "python"},
+				{Name: "golang.org/x/mod", Version: "v0.0.0-20230202000000-abcdef123456", Category: "prod", Packaging: "go"},
+				{Name: "golang.org/x/mod", Version: "v0.0.0-20230101000000-123456abcdef", Category: "prod", Packaging: "go"},
+				{Name: "http", Version: "^0.13.1", Category: "prod", Packaging: "dart"},
+				{Name: "http", Version: "^0.13.5", Category: "prod", Packaging: "dart"},
+			},
+			want: []AggregatedDependency{
+				{
+					Name: "golang.org/x/mod", Category: "prod", Packaging: "go", Count: 2, Projects: 1,
+					MinVersion:  "v0.0.0-20230101000000-123456abcdef",
+					MaxVersion:  "v0.0.0-20230202000000-abcdef123456",
+					Satisfiable: Satisfiable,
+				},
+				{
+					Name: "http", Category: "prod", Packaging: "dart",
+					Count: 2, Projects: 1, MinVersion: "^0.13.1", MaxVersion: "^0.13.5", Satisfiable: Satisfiable,
+				},
+				{
+					Name: "requests", Category: "prod", Packaging: "python",
+					Count: 3, Projects: 1, MinVersion: "1.0", MaxVersion: "2.0rc1", Satisfiable: Unsatisfiable,
 				},
 			},
 		},
@@ -59,15 +145,15 @@ func TestAggregateDependencies(t *testing.T) {
 			want: []AggregatedDependency{
 				{
 					Name: "foo", Category: "dev", Packaging: "node",
-					Count: 1, MinVersion: "1.1.0", MaxVersion: "1.1.0",
+					Count: 1, Projects: 1, MinVersion: "1.1.0", MaxVersion: "1.1.0", Satisfiable: Satisfiable,
 				},
 				{
 					Name: "foo", Category: "prod", Packaging: "node",
-					Count: 1, MinVersion: "1.0.0", MaxVersion: "1.0.0",
+					Count: 1, Projects: 1, MinVersion: "1.0.0", MaxVersion: "1.0.0", Satisfiable: Satisfiable,
 				},
 				{
 					Name: "foo", Category: "prod", Packaging: "python",
-					Count: 1, MinVersion: "1.2.0", MaxVersion: "1.2.0",
+					Count: 1, Projects: 1, MinVersion: "1.2.0", MaxVersion: "1.2.0", Satisfiable: Satisfiable,
 				},
 			},
 		},
@@ -85,7 +171,43 @@ func TestAggregateDependencies(t *testing.T) {
 	}
 }
 
-// Helper to compare slices ignoring order
+func TestAggregateDependencies_Versions(t *testing.T) {
+	input := []FlatDependency{
+		{Name: "foo", Version: "^1.4.0", Category: "prod", Packaging: "node", Path: "b/package.json", Line: 3},
+		{Name: "foo", Version: "file:../foo", Category: "prod", Packaging: "node", Path: "c/package.json"},
+		{Name: "foo", Version: "^1.4.0", Category: "prod", Packaging: "node", Path: "a/package.json", Line: 5},
+		{Name: "foo", Version: "1.2.0", Category: "prod", Packaging: "node", Path: "d/package.json", Line: 2},
+	}
+	want := []VersionUsage{
+		{Version: "file:../foo", Count: 1, Paths: []string{"c/package.json"}},
+		{Version: "1.2.0", Count: 1, Paths: []string{"d/package.json:2"}},
+		{Version: "^1.4.0", Count: 2, Paths: []string{"a/package.json:5", "b/package.json:3"}},
+	}
+
+	got := AggregateDependencies(input)
+	if len(got) != 1 {
+		t.Fatalf("expected a single dependency, got %+v", got)
+	}
+	if !reflect.DeepEqual(got[0].Versions, want) {
+		t.Errorf("Versions = %+v, want %+v", got[0].Versions, want)
+	}
+}
+
+func TestAggregateDependencies_Projects(t *testing.T) {
+	input := []FlatDependency{
+		{Name: "pytest", Version: "8.0.0", Category: "dev", Packaging: "python", Path: "api/requirements.txt"},
+		{Name: "pytest", Version: "8.1.0", Category: "dev", Packaging: "python", Path: "api/requirements-dev.txt"},
+		{Name: "pytest", Version: "8.1.0", Category: "dev", Packaging: "python", Path: "worker/requirements.txt"},
+	}
+
+	got := AggregateDependencies(input)
+	if len(got) != 1 || got[0].Count != 3 || got[0].Projects != 2 {
+		t.Errorf("AggregateDependencies() = %+v, want 3 declarations in 2 projects", got)
+	}
+}
+
+// Helper to compare slices ignoring order, and the versions breakdown, see
+// TestAggregateDependencies_Versions
 func equalAggregatedDependencies(a, b []AggregatedDependency) bool {
 	if len(a) != len(b) {
 		return false
@@ -96,6 +218,7 @@ func equalAggregatedDependencies(a, b []AggregatedDependency) bool {
 
 	for _, x := range a {
 		key := x.Name + "|" + x.Category + "|" + x.Packaging
+		x.Versions = nil
 		aMap[key] = x
 	}
 	for _, x := range b {
@@ -1,10 +1,10 @@
 package aggregator
 
 import (
-	"fmt"
-	"os"
+	"context"
 	"sort"
 
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
 	"github.com/flarebyte/clingy-code-detective/internal/parser"
 )
 
@@ -21,18 +21,96 @@ func sortFlatDependencies(deps []FlatDependency) {
 	})
 }
 
-// collectResults reads DependencyFile results and processes them.
-// Once resultChan is closed and drained, it signals completion on done chan.
-func CollectDependencies(resultChan <-chan parser.DependencyFile, done chan<- []FlatDependency) {
+// collectResults reads DependencyFile results and processes them, reporting
+// the files which failed to diags. Once resultChan is closed and drained, it
+// signals completion on done chan. When ctx is done first, it sends the
+// dependencies collected so far and reports the scan as incomplete.
+// Dependencies on projects of the scan are marked as internal.
+func CollectDependencies(ctx context.Context, resultChan <-chan parser.DependencyFile, diags *diagnostic.Collector, done chan<- []FlatDependency) {
+	projectsDone := make(chan []ProjectDependencies, 1)
+	CollectProjects(ctx, resultChan, diags, projectsDone)
 	var flatDependencies []FlatDependency
-	for depFile := range resultChan {
-		if depFile.Err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", depFile.Path, depFile.Err)
-			continue
-		} else {
-			flatDependencies = append(flatDependencies, DenormaliseDependencyFile(depFile)...)
-		}
+	for _, project := range <-projectsDone {
+		flatDependencies = append(flatDependencies, project.Dependencies...)
 	}
 	sortFlatDependencies(flatDependencies)
 	done <- flatDependencies
 }
+
+// StreamDependencies is CollectDependencies writing the dependencies to the
+// stream in the order the files are parsed, instead of collecting them. It
+// returns once resultChan is closed and drained, or ctx is done, without
+// closing the stream.
+func StreamDependencies(ctx context.Context, resultChan <-chan parser.DependencyFile, diags *diagnostic.Collector, stream FlatStream) error {
+	return collect(ctx, resultChan, diags, func(file parser.DependencyFile) error {
+		for _, dep := range DenormaliseDependencyFile(file) {
+			if err := stream.Write(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// collect hands over every file parsed successfully, or partially, to emit,
+// until resultChan is closed, ctx is done or emit fails.
+func collect(ctx context.Context, resultChan <-chan parser.DependencyFile, diags *diagnostic.Collector, emit func(parser.DependencyFile) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			diags.Report(diagnostic.Errorf(diagnostic.CodeIncomplete, "", "scan stopped before completion: %v", context.Cause(ctx)))
+			return nil
+		case depFile, ok := <-resultChan:
+			if !ok {
+				return nil
+			}
+			if d, ok := depFile.Diagnostic(); ok {
+				diags.Report(d)
+			}
+			if depFile.LockfileConflict != nil {
+				diags.Report(*depFile.LockfileConflict)
+			}
+			if depFile.Err != nil && !depFile.Partial {
+				continue
+			}
+			if err := emit(depFile); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// CollectProjects is CollectDependencies grouping the dependencies by the
+// project owning their manifest. Projects are sorted by root directory then
+// ecosystem, and include those without dependencies.
+func CollectProjects(ctx context.Context, resultChan <-chan parser.DependencyFile, diags *diagnostic.Collector, done chan<- []ProjectDependencies) {
+	type projectKey struct{ root, ecosystem string }
+	byKey := make(map[projectKey]*ProjectDependencies)
+	collect(ctx, resultChan, diags, func(file parser.DependencyFile) error {
+		key := projectKey{file.Project.Root, file.Project.Ecosystem}
+		project, ok := byKey[key]
+		if !ok {
+			project = &ProjectDependencies{Project: file.Project}
+			byKey[key] = project
+		} else {
+			project.Merge(file.Project)
+		}
+		project.Dependencies = append(project.Dependencies, DenormaliseDependencyFile(file)...)
+		return nil
+	})
+
+	projects := make([]ProjectDependencies, 0, len(byKey))
+	for _, project := range byKey {
+		sort.Strings(project.Manifests)
+		sortFlatDependencies(project.Dependencies)
+		projects = append(projects, *project)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Root != projects[j].Root {
+			return projects[i].Root < projects[j].Root
+		}
+		return projects[i].Ecosystem < projects[j].Ecosystem
+	})
+	LinkInternalPackages(projects)
+	done <- projects
+}
@@ -0,0 +1,112 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+)
+
+func TestCollectDependencies(t *testing.T) {
+	resultChan := make(chan parser.DependencyFile, 2)
+	resultChan <- parser.DependencyFile{Path: "b/package.json", Packaging: "node", Dependencies: []parser.Dependency{{Name: "b", Category: "prod"}}}
+	resultChan <- parser.DependencyFile{Path: "a/package.json", Packaging: "node", Err: errors.New("broken")}
+	close(resultChan)
+
+	diags := &diagnostic.Collector{}
+	done := make(chan []FlatDependency, 1)
+	CollectDependencies(context.Background(), resultChan, diags, done)
+
+	if got := <-done; len(got) != 1 || got[0].Name != "b" {
+		t.Errorf("CollectDependencies() = %+v, want the dependency of b", got)
+	}
+	if got := diags.Diagnostics(); len(got) != 1 || got[0].Code != diagnostic.CodeParse {
+		t.Errorf("diagnostics = %+v, want a parse error", got)
+	}
+}
+
+func TestCollectDependencies_Cancelled(t *testing.T) {
+	resultChan := make(chan parser.DependencyFile) // Never closed, as with a hung worker
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("interrupted"))
+
+	diags := &diagnostic.Collector{}
+	done := make(chan []FlatDependency, 1)
+	CollectDependencies(ctx, resultChan, diags, done)
+
+	<-done
+	got := diags.Diagnostics()
+	if len(got) != 1 || got[0].Code != diagnostic.CodeIncomplete || got[0].Message != "scan stopped before completion: interrupted" {
+		t.Errorf("diagnostics = %+v, want an incomplete error", got)
+	}
+}
+
+func TestStreamDependencies(t *testing.T) {
+	resultChan := make(chan parser.DependencyFile, 2)
+	resultChan <- parser.DependencyFile{Path: "b/package.json", Packaging: "node", Dependencies: []parser.Dependency{{Name: "b", Category: "prod"}}}
+	resultChan <- parser.DependencyFile{Path: "a/package.json", Packaging: "node", Dependencies: []parser.Dependency{{Name: "a", Category: "prod"}}}
+	close(resultChan)
+
+	var buf bytes.Buffer
+	stream := NewCSVStream(&buf)
+	if err := StreamDependencies(context.Background(), resultChan, nil, stream); err != nil {
+		t.Fatalf("StreamDependencies() error: %v", err)
+	}
+	if err := stream.Close(nil); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	// Dependencies are written in the order the files are received
+	want := "Name,Version,Category,Path,Packaging\nb,,prod,b/package.json,node\na,,prod,a/package.json,node\n"
+	if buf.String() != want {
+		t.Errorf("StreamDependencies() wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestCollectProjects(t *testing.T) {
+	web := parser.Project{Root: "web", Ecosystem: "node", Name: "web", Version: "1.0.0", Manifests: []string{"web/package.json"}, Lockfile: "web/yarn.lock"}
+	files := []parser.DependencyFile{
+		{Path: "web/package.json", Packaging: "node", Project: web, Dependencies: []parser.Dependency{{Name: "react", Category: "prod"}, {Name: "jest", Category: "dev"}}},
+		{Path: "api/requirements.txt", Packaging: "python", Project: parser.Project{Root: "api", Ecosystem: "python", Manifests: []string{"api/requirements.txt"}}, Dependencies: []parser.Dependency{{Name: "flask", Category: "prod"}}},
+		{Path: "api/go.mod", Packaging: "go", Project: parser.Project{Root: "api", Ecosystem: "go", Name: "example.com/api", Manifests: []string{"api/go.mod"}}},
+		{Path: "api/requirements-dev.txt", Packaging: "python", Project: parser.Project{Root: "api", Ecosystem: "python", Manifests: []string{"api/requirements-dev.txt"}, Lockfile: "api/requirements.lock"}, Dependencies: []parser.Dependency{{Name: "pytest", Category: "dev"}}},
+	}
+	resultChan := make(chan parser.DependencyFile, len(files))
+	for _, f := range files {
+		resultChan <- f
+	}
+	close(resultChan)
+
+	done := make(chan []ProjectDependencies, 1)
+	CollectProjects(context.Background(), resultChan, &diagnostic.Collector{}, done)
+	got := <-done
+
+	want := []struct {
+		label     string
+		ecosystem string
+		manifests int
+		lockfile  string
+		deps      []string
+	}{
+		{"example.com/api", "go", 1, "", nil},
+		{"api", "python", 2, "api/requirements.lock", []string{"pytest", "flask"}},
+		{"web", "node", 1, "web/yarn.lock", []string{"jest", "react"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("CollectProjects() = %+v, want %d projects", got, len(want))
+	}
+	for i, w := range want {
+		p := got[i]
+		var names []string
+		for _, d := range p.Dependencies {
+			names = append(names, d.Name)
+		}
+		if p.Label() != w.label || p.Ecosystem != w.ecosystem || len(p.Manifests) != w.manifests || p.Lockfile != w.lockfile || !slices.Equal(names, w.deps) {
+			t.Errorf("project %d = %+v, want %+v", i, p, w)
+		}
+	}
+}
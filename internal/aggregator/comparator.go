@@ -0,0 +1,183 @@
+package aggregator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Version is a parsed version. It can only be compared with versions parsed
+// by the same VersionComparator.
+type Version interface {
+	// Compare returns -1, 0 or +1 when the version is lower than, equal to or
+	// higher than other.
+	Compare(other Version) int
+	// Release returns the major, minor and patch numbers, 0 when missing.
+	Release() [3]uint64
+	String() string
+}
+
+// VersionComparator orders the versions and parses the declared ranges of a
+// packaging.
+type VersionComparator interface {
+	// Compare returns -1, 0 or +1 when v1 is lower than, equal to or higher
+	// than v2. Opaque strings, which are not versions of the packaging such
+	// as git URLs or paths, are lower than versions and in natural order
+	// among themselves.
+	Compare(v1, v2 string) int
+	// ParseConstraint parses a declared version or range.
+	ParseConstraint(declared string) (Constraint, error)
+}
+
+// scheme implements VersionComparator with the parsing functions of a
+// versioning scheme.
+type scheme struct {
+	parseVersion    func(version string) (Version, error)
+	parseConstraint func(declared string) (Constraint, error)
+}
+
+func (s scheme) Compare(v1, v2 string) int {
+	a, errA := s.parseVersion(v1)
+	b, errB := s.parseVersion(v2)
+	switch {
+	case errA != nil && errB != nil:
+		return compareOpaque(v1, v2)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return a.Compare(b)
+}
+
+func (s scheme) ParseConstraint(declared string) (Constraint, error) {
+	return s.parseConstraint(declared)
+}
+
+var (
+	npmScheme = scheme{
+		parseVersion:    parseSemver,
+		parseConstraint: ParseConstraint,
+	}
+	comparators = map[string]VersionComparator{
+		"node": npmScheme,
+		"dart": scheme{
+			parseVersion: parseSemver,
+			parseConstraint: func(declared string) (Constraint, error) {
+				return parseSemverConstraint(declared, pubCaret)
+			},
+		},
+		"go":     scheme{parseVersion: parseGoVersion, parseConstraint: parseGoConstraint},
+		"python": scheme{parseVersion: parsePEP440, parseConstraint: parsePEP440Constraint},
+		// No built-in ecosystem is named maven or ruby, these schemes are
+		// those of the plugins and declarative parsers given these names
+		"maven": scheme{parseVersion: parseMavenVersion, parseConstraint: parseMavenConstraint},
+		"ruby":  scheme{parseVersion: parseGemVersion, parseConstraint: parseGemConstraint},
+	}
+)
+
+// ComparatorFor returns the comparator of a packaging. Packagings without a
+// specific comparator, such as those of plugins, use semantic versions and
+// the range syntax of npm.
+func ComparatorFor(packaging string) VersionComparator {
+	if c, ok := comparators[packaging]; ok {
+		return c
+	}
+	return npmScheme
+}
+
+// semverVersion is a semantic version.
+type semverVersion struct {
+	*semver.Version
+}
+
+func (v semverVersion) Compare(other Version) int {
+	return v.Version.Compare(other.(semverVersion).Version)
+}
+
+func (v semverVersion) Release() [3]uint64 {
+	return [3]uint64{v.Major(), v.Minor(), v.Patch()}
+}
+
+func parseSemver(version string) (Version, error) {
+	v, err := semver.NewVersion(strings.TrimSpace(version))
+	if err != nil {
+		return nil, err
+	}
+	return semverVersion{v}, nil
+}
+
+// parseGoVersion parses a module version of go.mod, such as v1.2.3, the
+// pseudo-version v0.0.0-20230102150405-abcdef123456 or v2.0.0+incompatible.
+// Pseudo-versions are pre-releases ordered by their timestamp.
+func parseGoVersion(version string) (Version, error) {
+	if !strings.HasPrefix(version, "v") {
+		return nil, fmt.Errorf("invalid module version %q: missing v prefix", version)
+	}
+	v, err := semver.StrictNewVersion(version[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid module version %q: %w", version, err)
+	}
+	return semverVersion{v}, nil
+}
+
+// parseGoConstraint parses a requirement of go.mod. With minimal version
+// selection, the required version is a minimum and the build uses the
+// highest one required, so requirements are never incompatible.
+func parseGoConstraint(declared string) (Constraint, error) {
+	v, err := parseGoVersion(strings.TrimSpace(declared))
+	if err != nil {
+		return Constraint{}, err
+	}
+	return Constraint{intervals: []interval{{lower: bound{version: v, inclusive: true}}}}, nil
+}
+
+// compareOpaque compares two strings in natural order, with runs of digits
+// compared by their numeric value, e.g. rev-9 before rev-10.
+func compareOpaque(a, b string) int {
+	for a != "" && b != "" {
+		runA, restA := leadingRun(a)
+		runB, restB := leadingRun(b)
+		if c := compareRuns(runA, runB); c != 0 {
+			return c
+		}
+		a, b = restA, restB
+	}
+	return strings.Compare(a, b)
+}
+
+// leadingRun splits s after its leading run of digits or non-digits.
+func leadingRun(s string) (string, string) {
+	digits := isDigit(s[0])
+	i := 1
+	for i < len(s) && isDigit(s[i]) == digits {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// compareRuns compares two runs numerically when both are digits.
+func compareRuns(a, b string) int {
+	if isDigit(a[0]) && isDigit(b[0]) {
+		return compareNumeric(a, b)
+	}
+	return strings.Compare(a, b)
+}
+
+// compareNumeric compares two strings of digits by their value, whatever
+// their length.
+func compareNumeric(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,223 @@
+package aggregator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/config"
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+)
+
+func TestVersionComparator_Compare(t *testing.T) {
+	tests := []struct {
+		packaging string
+		lower     string
+		higher    string
+	}{
+		{"node", "1.2.3", "1.10.0"},
+		{"node", "2.0.0-beta", "2.0.0"},
+		{"node", "file:../lib", "0.0.1"}, // opaque strings are lower than versions
+		{"dart", "1.0.0-dev.1", "1.0.0"},
+		{"go", "v0.0.0-20230101000000-abcdef123456", "v0.0.0-20230202000000-123456abcdef"},
+		{"go", "v1.2.3", "v1.2.4-0.20230101000000-abcdef123456"},
+		{"go", "v1.9.0", "v2.0.0+incompatible"},
+		{"python", "1.0.dev1", "1.0a1"},
+		{"python", "1.0a1", "1.0b2"},
+		{"python", "1.0b2", "1.0rc1"},
+		{"python", "2.0rc1", "2.0"},
+		{"python", "1.0", "1.0.post1"},
+		{"python", "1.0.post1.dev1", "1.0.post1"},
+		{"python", "1.9", "1.10"},
+		{"python", "2.0", "1!1.0"},
+		{"maven", "1.0-alpha-1", "1.0-beta"},
+		{"maven", "1.0-beta", "1.0-M1"},
+		{"maven", "1.0-RC1", "1.0-SNAPSHOT"},
+		{"maven", "1.0-SNAPSHOT", "1.0"},
+		{"maven", "1.0", "1.0-sp1"},
+		{"maven", "1.0.Final", "1.0.1"},
+		{"maven", "1.9", "1.10"},
+		{"ruby", "1.2.0.pre", "1.2.0"},
+		{"ruby", "1.2.0.rc1", "1.2.0.rc2"},
+		{"ruby", "1.9", "1.10"},
+		{"ruby", "2.0.0-beta", "2.0.0"},
+		{"cargo", "rev-9", "rev-10"}, // natural order of opaque strings
+	}
+	for _, tt := range tests {
+		c := ComparatorFor(tt.packaging)
+		if got := c.Compare(tt.lower, tt.higher); got != -1 {
+			t.Errorf("%s: Compare(%q, %q) = %d, want -1", tt.packaging, tt.lower, tt.higher, got)
+		}
+		if got := c.Compare(tt.higher, tt.lower); got != 1 {
+			t.Errorf("%s: Compare(%q, %q) = %d, want 1", tt.packaging, tt.higher, tt.lower, got)
+		}
+	}
+}
+
+func TestVersionComparator_CompareEqual(t *testing.T) {
+	tests := []struct {
+		packaging string
+		v1, v2    string
+	}{
+		{"python", "1.0", "1.0.0"},
+		{"python", "1.0rc1", "1.0-RC-1"},
+		{"python", "1.0-1", "1.0.post1"},
+		{"maven", "1.0", "1.0.0-GA"},
+		{"maven", "1.0-cr1", "1.0-rc-1"},
+		{"ruby", "1.0", "1.0.0"},
+	}
+	for _, tt := range tests {
+		if got := ComparatorFor(tt.packaging).Compare(tt.v1, tt.v2); got != 0 {
+			t.Errorf("%s: Compare(%q, %q) = %d, want 0", tt.packaging, tt.v1, tt.v2, got)
+		}
+	}
+}
+
+func TestVersionComparator_ParseConstraint(t *testing.T) {
+	tests := []struct {
+		packaging  string
+		declared   string
+		lowerBound string   // Empty when not bounded below
+		allowed    []string // Versions the constraint allows
+		rejected   []string // Versions the constraint rejects
+	}{
+		{"node", "^0.0.3", "0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"dart", "^0.0.3", "0.0.3", []string{"0.0.9"}, []string{"0.1.0"}},
+		{"dart", "^1.2.0", "1.2.0", []string{"1.9.0"}, []string{"2.0.0"}},
+		{"go", "v1.2.3", "1.2.3", []string{"v1.2.3", "v1.9.0"}, nil}, // requirements are minimums
+		{"python", "2.31.0", "2.31.0", []string{"2.31.0", "2.31"}, []string{"2.31.0.post1"}},
+		{"python", ">=1.0,<2.0", "1.0", []string{"1.0", "1.9.post1"}, []string{"2.0rc1", "1.0rc1"}},
+		{"python", "~=1.4.2", "1.4.2", []string{"1.4.9"}, []string{"1.5.0", "1.5.0.dev1"}},
+		{"python", "~=1.4", "1.4", []string{"1.9"}, []string{"2.0"}},
+		{"python", "==1.4.*", "1.4.dev0", []string{"1.4.0rc1", "1.4.9"}, []string{"1.5.0a1"}},
+		{"python", "!=1.5", "", []string{"1.5"}, nil},
+		{"maven", "1.0", "1.0", []string{"1.0.0"}, []string{"1.0.1"}},
+		{"maven", "[1.0,2.0)", "1.0", []string{"1.0", "1.9.9"}, []string{"2.0", "0.9"}},
+		{"maven", "(,1.0],[1.2,)", "", []string{"1.0", "1.2"}, []string{"1.1"}},
+		{"maven", "[1.5]", "1.5", []string{"1.5"}, []string{"1.5.1"}},
+		{"ruby", "~> 1.2", "1.2", []string{"1.9"}, []string{"2.0"}},
+		{"ruby", "~> 1.2.3", "1.2.3", []string{"1.2.9"}, []string{"1.3"}},
+		{"ruby", "'>= 1.0', '< 3'", "1.0", []string{"2.9"}, []string{"3.0"}},
+		{"ruby", "1.5.0", "1.5.0", []string{"1.5"}, []string{"1.5.1"}},
+	}
+	for _, tt := range tests {
+		c := ComparatorFor(tt.packaging)
+		constraint, err := c.ParseConstraint(tt.declared)
+		if err != nil {
+			t.Errorf("%s: ParseConstraint(%q) error: %v", tt.packaging, tt.declared, err)
+			continue
+		}
+		got := ""
+		if bound, _ := constraint.LowerBound(); bound != nil {
+			got = bound.String()
+		}
+		if got != tt.lowerBound {
+			t.Errorf("%s: %q lower bound = %q, want %q", tt.packaging, tt.declared, got, tt.lowerBound)
+		}
+		for _, v := range tt.allowed {
+			if !allowsIn(t, c, constraint, v) {
+				t.Errorf("%s: %q should allow %s", tt.packaging, tt.declared, v)
+			}
+		}
+		for _, v := range tt.rejected {
+			if allowsIn(t, c, constraint, v) {
+				t.Errorf("%s: %q should reject %s", tt.packaging, tt.declared, v)
+			}
+		}
+	}
+}
+
+// allowsIn returns true if the constraint allows an exact version of the
+// comparator, written as a requirement of the packaging.
+func allowsIn(t *testing.T, c VersionComparator, constraint Constraint, version string) bool {
+	t.Helper()
+	exact, err := c.ParseConstraint(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return constraint.Intersect(exact).Satisfiable()
+}
+
+func TestVersionComparator_ParseConstraintInvalid(t *testing.T) {
+	tests := []struct {
+		packaging string
+		declared  string
+	}{
+		{"go", "../lib"},
+		{"go", "1.2.3"},
+		{"python", "~=1"},
+		{"python", "latest"},
+		{"maven", "${project.version}"},
+		{"maven", "[1.0,2.0) junk"},
+		{"ruby", "~> x"},
+	}
+	for _, tt := range tests {
+		if _, err := ComparatorFor(tt.packaging).ParseConstraint(tt.declared); err == nil {
+			t.Errorf("%s: ParseConstraint(%q) should fail", tt.packaging, tt.declared)
+		}
+	}
+}
+
+// TestComparatorFor_Plugins runs plugins named maven and ruby from a
+// configuration file, whose versions are aggregated with their comparators
+// rather than as semantic versions.
+func TestComparatorFor_Plugins(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+cat >/dev/null
+case "$1" in
+maven) echo '{"dependencies":[{"name":"junit","version":"1.0"},{"name":"junit","version":"1.0-sp1"}]}' ;;
+ruby) echo '{"dependencies":[{"name":"rails","version":"1.2.0"},{"name":"rails","version":"1.2.0.rc1"}]}' ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "plugin.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "clingy.yaml")
+	configContent := `
+plugins:
+  - name: maven
+    command: ./plugin.sh
+    args: [maven]
+    patterns: ["pom.xml"]
+  - name: ruby
+    command: ./plugin.sh
+    args: [ruby]
+    patterns: ["Gemfile"]
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if err := cfg.Register(); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	opts := parser.Options{
+		Read:   func(string) ([]byte, error) { return []byte("content"), nil },
+		Exists: func(string) bool { return false },
+	}
+	tests := []struct {
+		path     string
+		min, max string
+	}{
+		// Semantic versions would order 1.0-sp1 before 1.0
+		{"app/pom.xml", "1.0", "1.0-sp1"},
+		// Semantic versions would leave 1.2.0.rc1 out as an opaque string
+		{"app/Gemfile", "1.2.0.rc1", "1.2.0"},
+	}
+	for _, tt := range tests {
+		file := parser.ParseDependencyFileWith(context.Background(), tt.path, opts)
+		if file.Err != nil {
+			t.Fatalf("%s: ParseDependencyFileWith() error: %v", tt.path, file.Err)
+		}
+		aggregated := GroupDependencies(DenormaliseDependencyFile(file), Grouping{})
+		if len(aggregated) != 1 || aggregated[0].MinVersion != tt.min || aggregated[0].MaxVersion != tt.max {
+			t.Errorf("%s: GroupDependencies() = %+v, want %s to %s", tt.path, aggregated, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package aggregator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Constraint is a declared version or version range, as the union of the
+// intervals of versions it allows. Its versions come from a single
+// VersionComparator.
+type Constraint struct {
+	intervals []interval
+}
+
+// bound is one end of an interval, unbounded when version is nil.
+type bound struct {
+	version   Version
+	inclusive bool
+}
+
+type interval struct {
+	lower, upper bound
+}
+
+var anyInterval = interval{}
+
+// exactly returns the constraint allowing a single version.
+func exactly(v Version) Constraint {
+	exact := bound{version: v, inclusive: true}
+	return Constraint{intervals: []interval{{lower: exact, upper: exact}}}
+}
+
+// ParseConstraint parses a declared semantic version or range with the npm
+// syntax. It understands exact and partial versions (1.2.3, 1.2, 1.x),
+// comparisons (>=1.2 <2, also separated by commas), caret and tilde ranges
+// (^1.2.0, ~1.2), hyphen ranges (1.2 - 2.0), alternatives (^1 || ^2), and
+// the Ruby pessimistic (~> 1.2) and PEP 440 compatible (~=1.4.2) operators.
+// An exclusion such as !=1.2.3 allows every version, as do an empty string,
+// * and the latest and any keywords.
+func ParseConstraint(declared string) (Constraint, error) {
+	return parseSemverConstraint(declared, npmCaret)
+}
+
+// parseSemverConstraint parses a declared semantic version or range, with
+// the upper bound of caret ranges given by caret.
+func parseSemverConstraint(declared string, caret func(partial) bound) (Constraint, error) {
+	var c Constraint
+	for _, branch := range strings.Split(declared, "||") {
+		in, err := parseBranch(strings.TrimSpace(branch), caret)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid constraint %q: %w", declared, err)
+		}
+		if !in.empty() {
+			c.intervals = append(c.intervals, in)
+		}
+	}
+	return c, nil
+}
+
+// LowerBound returns the lowest version allowed by the constraint, nil when
+// it is not bounded below, or false when no version is allowed.
+func (c Constraint) LowerBound() (Version, bool) {
+	var lowest Version
+	for i, in := range c.intervals {
+		if i == 0 || compareLower(in.lower.version, lowest) < 0 {
+			lowest = in.lower.version
+		}
+	}
+	return lowest, len(c.intervals) > 0
+}
+
+// compareLower compares two lower bounds, nil being the lowest.
+func compareLower(a, b Version) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return a.Compare(b)
+}
+
+// Intersect returns the constraint allowing the versions allowed by both.
+func (c Constraint) Intersect(other Constraint) Constraint {
+	var result Constraint
+	for _, a := range c.intervals {
+		for _, b := range other.intervals {
+			if in := a.intersect(b); !in.empty() {
+				result.intervals = append(result.intervals, in)
+			}
+		}
+	}
+	return result
+}
+
+// Satisfiable returns true if at least one version satisfies the constraint.
+func (c Constraint) Satisfiable() bool {
+	return len(c.intervals) > 0
+}
+
+func (a interval) intersect(b interval) interval {
+	return interval{lower: higherLower(a.lower, b.lower), upper: lowerUpper(a.upper, b.upper)}
+}
+
+func higherLower(a, b bound) bound {
+	switch {
+	case a.version == nil:
+		return b
+	case b.version == nil:
+		return a
+	}
+	switch cmp := a.version.Compare(b.version); {
+	case cmp > 0:
+		return a
+	case cmp < 0:
+		return b
+	}
+	return bound{version: a.version, inclusive: a.inclusive && b.inclusive}
+}
+
+func lowerUpper(a, b bound) bound {
+	switch {
+	case a.version == nil:
+		return b
+	case b.version == nil:
+		return a
+	}
+	switch cmp := a.version.Compare(b.version); {
+	case cmp < 0:
+		return a
+	case cmp > 0:
+		return b
+	}
+	return bound{version: a.version, inclusive: a.inclusive && b.inclusive}
+}
+
+func (a interval) empty() bool {
+	if a.lower.version == nil || a.upper.version == nil {
+		return false
+	}
+	cmp := a.lower.version.Compare(a.upper.version)
+	return cmp > 0 || (cmp == 0 && !(a.lower.inclusive && a.upper.inclusive))
+}
+
+// parseBranch parses the comparators of one alternative, all of which apply.
+func parseBranch(branch string, caret func(partial) bound) (interval, error) {
+	if branch == "latest" || branch == "any" {
+		return anyInterval, nil
+	}
+	if lower, upper, found := strings.Cut(branch, " - "); found {
+		return hyphenRange(strings.TrimSpace(lower), strings.TrimSpace(upper))
+	}
+
+	// Operators may be separated from their version, as in ">= 1.2, < 2"
+	var comparators []string
+	pending := ""
+	for _, field := range strings.Fields(strings.ReplaceAll(branch, ",", " ")) {
+		if strings.Trim(field, "<>=~^!") == "" {
+			pending += field
+			continue
+		}
+		comparators = append(comparators, pending+field)
+		pending = ""
+	}
+	if pending != "" {
+		return interval{}, fmt.Errorf("operator %q without a version", pending)
+	}
+
+	result := anyInterval
+	for _, comparator := range comparators {
+		in, err := parseComparator(comparator, caret)
+		if err != nil {
+			return interval{}, err
+		}
+		result = result.intersect(in)
+	}
+	return result, nil
+}
+
+func hyphenRange(lower, upper string) (interval, error) {
+	from, err := parsePartial(lower)
+	if err != nil {
+		return interval{}, err
+	}
+	to, err := parsePartial(upper)
+	if err != nil {
+		return interval{}, err
+	}
+	in := interval{lower: from.lower()}
+	if to.parts == 3 {
+		in.upper = bound{version: to.version(), inclusive: true}
+	} else {
+		in.upper = to.next()
+	}
+	return in, nil
+}
+
+// operators are sorted so that longer operators are matched first.
+var operators = []string{"===", "~>", "~=", "==", ">=", "<=", "!=", "^", "~", ">", "<", "="}
+
+func parseComparator(comparator string, caret func(partial) bound) (interval, error) {
+	op := ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(comparator, candidate) {
+			op = candidate
+			break
+		}
+	}
+	p, err := parsePartial(strings.TrimSpace(comparator[len(op):]))
+	if err != nil {
+		return interval{}, err
+	}
+	if p.parts == 0 || op == "!=" {
+		return anyInterval, nil
+	}
+
+	switch op {
+	case "", "=", "==", "===":
+		if p.parts == 3 {
+			exact := bound{version: p.version(), inclusive: true}
+			return interval{lower: exact, upper: exact}, nil
+		}
+		return interval{lower: p.lower(), upper: p.next()}, nil
+	case ">=":
+		return interval{lower: p.lower()}, nil
+	case ">":
+		if p.parts == 3 {
+			return interval{lower: bound{version: p.version()}}, nil
+		}
+		next := p.next()
+		next.inclusive = true
+		return interval{lower: next}, nil
+	case "<":
+		return interval{upper: bound{version: p.version()}}, nil
+	case "<=":
+		if p.parts == 3 {
+			return interval{upper: bound{version: p.version(), inclusive: true}}, nil
+		}
+		return interval{upper: p.next()}, nil
+	case "^":
+		return interval{lower: p.lower(), upper: caret(p)}, nil
+	case "~":
+		if p.parts == 1 {
+			return interval{lower: p.lower(), upper: p.bump(1)}, nil
+		}
+		return interval{lower: p.lower(), upper: p.bump(2)}, nil
+	default:
+		// ~> and ~= allow changes of the last specified part only
+		if p.parts <= 2 {
+			return interval{lower: p.lower(), upper: p.bump(1)}, nil
+		}
+		return interval{lower: p.lower(), upper: p.bump(2)}, nil
+	}
+}
+
+// npmCaret returns the upper bound of a caret range of npm, which allows
+// changes of the parts after the first non-zero one: ^0.2.3 is <0.3.0 and
+// ^0.0.3 is <0.0.4.
+func npmCaret(p partial) bound {
+	switch {
+	case p.major != 0 || p.parts == 1:
+		return p.bump(1)
+	case p.minor != 0 || p.parts == 2:
+		return p.bump(2)
+	default:
+		return p.bump(3)
+	}
+}
+
+// pubCaret returns the upper bound of a caret range of pub, which allows
+// changes of the minor and patch for 1.0.0 and later, and of the patch
+// before: ^0.0.3 is <0.1.0.
+func pubCaret(p partial) bound {
+	if p.major != 0 {
+		return p.bump(1)
+	}
+	return p.bump(2)
+}
+
+// partial is a version whose minor and patch may be missing or wildcards.
+type partial struct {
+	major, minor, patch uint64
+	parts               int // Number of numeric parts specified, from 0 to 3
+	prerelease          string
+}
+
+func parsePartial(s string) (partial, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	s, _, _ = strings.Cut(s, "+")
+	core, prerelease, _ := strings.Cut(s, "-")
+	p := partial{prerelease: prerelease}
+	if core == "" {
+		return partial{}, fmt.Errorf("missing version")
+	}
+	segments := strings.Split(core, ".")
+	if len(segments) > 3 {
+		return partial{}, fmt.Errorf("too many parts in %q", s)
+	}
+	numbers := []*uint64{&p.major, &p.minor, &p.patch}
+	for i, segment := range segments {
+		if segment == "x" || segment == "X" || segment == "*" {
+			break
+		}
+		n, err := strconv.ParseUint(segment, 10, 64)
+		if err != nil {
+			return partial{}, fmt.Errorf("invalid version %q", s)
+		}
+		*numbers[i] = n
+		p.parts = i + 1
+	}
+	return p, nil
+}
+
+// version returns the version with missing parts set to 0.
+func (p partial) version() Version {
+	return semverVersion{semver.New(p.major, p.minor, p.patch, p.prerelease, "")}
+}
+
+// lower returns the inclusive bound at the version.
+func (p partial) lower() bound {
+	return bound{version: p.version(), inclusive: true}
+}
+
+// next returns the exclusive bound above every version matching the partial
+// version, e.g. 1.3.0 for 1.2 and 2.0.0 for 1.
+func (p partial) next() bound {
+	return p.bump(p.parts)
+}
+
+// bump returns the exclusive bound incrementing the given part, 1 for the
+// major, and resetting the following parts.
+func (p partial) bump(part int) bound {
+	switch part {
+	case 1:
+		return bound{version: semverVersion{semver.New(p.major+1, 0, 0, "", "")}}
+	case 2:
+		return bound{version: semverVersion{semver.New(p.major, p.minor+1, 0, "", "")}}
+	default:
+		return bound{version: semverVersion{semver.New(p.major, p.minor, p.patch+1, "", "")}}
+	}
+}
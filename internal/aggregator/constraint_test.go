@@ -0,0 +1,110 @@
+package aggregator
+
+import (
+	"testing"
+)
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		declared   string
+		lowerBound string   // Empty when not bounded below, none when no version is allowed
+		allowed    []string // Versions the constraint allows
+		rejected   []string // Versions the constraint rejects
+	}{
+		{"1.2.3", "1.2.3", []string{"1.2.3"}, []string{"1.2.4", "1.2.2"}},
+		{"v1.2.3", "1.2.3", []string{"1.2.3"}, []string{"1.3.0"}},
+		{"=1.2.3", "1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"1.2", "1.2.0", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{"1.x", "1.0.0", []string{"1.9.9"}, []string{"2.0.0"}},
+		{"*", "", []string{"0.0.1", "9.0.0"}, nil},
+		{"", "", []string{"1.0.0"}, nil},
+		{"latest", "", []string{"1.0.0"}, nil},
+		{"^1.2.3", "1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}},
+		{"^0.2.3", "0.2.3", []string{"0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", "0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^0.0", "0.0.0", []string{"0.0.9"}, []string{"0.1.0"}},
+		{"~1.2.3", "1.2.3", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"~1", "1.0.0", []string{"1.9.0"}, []string{"2.0.0"}},
+		{">=1.2 <2", "1.2.0", []string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0"}},
+		{">= 1.2, < 2", "1.2.0", []string{"1.5.0"}, []string{"2.0.0"}},
+		{">1.2.3", "1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{">1.2", "1.3.0", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", "", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"<2.0.0", "", []string{"1.9.9"}, []string{"2.0.0"}},
+		{"1.2.3 - 2.3", "1.2.3", []string{"2.3.9"}, []string{"2.4.0", "1.2.2"}},
+		{"1.2.3 - 2.3.4", "1.2.3", []string{"2.3.4"}, []string{"2.3.5"}},
+		{"^1.0.0 || ^3.0.0", "1.0.0", []string{"1.5.0", "3.1.0"}, []string{"2.0.0"}},
+		{"~> 1.2", "1.2.0", []string{"1.9.0"}, []string{"2.0.0"}},
+		{"~> 1.2.3", "1.2.3", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"~=1.4.2", "1.4.2", []string{"1.4.9"}, []string{"1.5.0"}},
+		{"==2.0", "2.0.0", []string{"2.0.1"}, []string{"2.1.0"}},
+		{">=1.0,!=1.5.0", "1.0.0", []string{"1.5.0"}, []string{"0.9.0"}},
+		{">2 <1", "none", nil, []string{"1.5.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.declared, func(t *testing.T) {
+			c, err := ParseConstraint(tt.declared)
+			if err != nil {
+				t.Fatalf("ParseConstraint() error: %v", err)
+			}
+			bound, ok := c.LowerBound()
+			got := "none"
+			switch {
+			case ok && bound == nil:
+				got = ""
+			case ok:
+				got = bound.String()
+			}
+			if got != tt.lowerBound {
+				t.Errorf("LowerBound() = %q, want %q", got, tt.lowerBound)
+			}
+			for _, v := range tt.allowed {
+				if !allows(t, c, v) {
+					t.Errorf("%q should allow %s", tt.declared, v)
+				}
+			}
+			for _, v := range tt.rejected {
+				if allows(t, c, v) {
+					t.Errorf("%q should reject %s", tt.declared, v)
+				}
+			}
+		})
+	}
+}
+
+func allows(t *testing.T, c Constraint, version string) bool {
+	t.Helper()
+	exact, err := ParseConstraint(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Intersect(exact).Satisfiable()
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	for _, declared := range []string{"git+https://example.com/x.git", "file:../lib", "1.2.3.4", ">=", "workspace:*"} {
+		if _, err := ParseConstraint(declared); err == nil {
+			t.Errorf("ParseConstraint(%q) should fail", declared)
+		}
+	}
+}
+
+func TestConstraint_Intersect(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"^1.2.0", "~1.4.0", true},
+		{"^1.2.0", "^2.0.0", false},
+		{">=1.0 <1.5", ">=1.5", false},
+		{"<=1.5.0", ">=1.5.0", true},
+		{"^1.0.0 || ^2.0.0", "~2.3", true},
+	}
+	for _, tt := range tests {
+		a, _ := ParseConstraint(tt.a)
+		b, _ := ParseConstraint(tt.b)
+		if got := a.Intersect(b).Satisfiable(); got != tt.want {
+			t.Errorf("%q and %q satisfiable = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -4,7 +4,7 @@ import "github.com/flarebyte/clingy-code-detective/internal/parser"
 
 // Denormalise the dependency file into an array of FlatDependency
 func DenormaliseDependencyFile(file parser.DependencyFile) []FlatDependency {
-	if file.Err != nil || len(file.Dependencies) == 0 {
+	if (file.Err != nil && !file.Partial) || len(file.Dependencies) == 0 {
 		return []FlatDependency{}
 	}
 
@@ -15,7 +15,10 @@ func DenormaliseDependencyFile(file parser.DependencyFile) []FlatDependency {
 			Version:   dep.Version,
 			Category:  dep.Category,
 			Path:      file.Path,
+			Line:      dep.Line,
+			Column:    dep.Column,
 			Packaging: file.Packaging,
+			LocalPath: dep.LocalPath,
 		})
 	}
 
@@ -12,7 +12,7 @@ func TestDenormaliseDependencyFile(t *testing.T) {
 		file := parser.DependencyFile{
 			Path: "deps.txt",
 			Dependencies: []parser.Dependency{
-				{Name: "foo", Version: "1.0.0", Category: "prod"},
+				{Name: "foo", Version: "1.0.0", Category: "prod", Line: 1, Column: 1},
 				{Name: "bar", Version: "2.3.4", Category: "dev"},
 			},
 		}
@@ -20,7 +20,7 @@ func TestDenormaliseDependencyFile(t *testing.T) {
 		got := DenormaliseDependencyFile(file)
 
 		want := []FlatDependency{
-			{Name: "foo", Version: "1.0.0", Category: "prod", Path: "deps.txt"},
+			{Name: "foo", Version: "1.0.0", Category: "prod", Path: "deps.txt", Line: 1, Column: 1},
 			{Name: "bar", Version: "2.3.4", Category: "dev", Path: "deps.txt"},
 		}
 
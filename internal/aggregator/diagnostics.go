@@ -0,0 +1,75 @@
+package aggregator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+// jsonReport is the JSON document of a flat or aggregated report.
+type jsonReport[T any] struct {
+	Dependencies []T
+	Diagnostics  []diagnostic.Diagnostic
+	Incomplete   bool `json:",omitempty"`
+}
+
+func newJSONReport[T any](deps []T, diags []diagnostic.Diagnostic) jsonReport[T] {
+	if deps == nil {
+		deps = []T{}
+	}
+	if diags == nil {
+		diags = []diagnostic.Diagnostic{}
+	}
+	return jsonReport[T]{Incomplete: diagnostic.Incomplete(diags), Dependencies: deps, Diagnostics: diags}
+}
+
+// hasErrors returns true if a diagnostic is an error, such as a manifest whose
+// project is missing from a report because it could not be parsed.
+func hasErrors(diags []diagnostic.Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == diagnostic.Error {
+			return true
+		}
+	}
+	return false
+}
+
+// writeMarkdownDiagnostics appends a Diagnostics section when there are any.
+func writeMarkdownDiagnostics(buf *bytes.Buffer, diags []diagnostic.Diagnostic) {
+	if len(diags) == 0 {
+		return
+	}
+	buf.WriteString("\n## Diagnostics\n\n")
+	if diagnostic.Incomplete(diags) {
+		buf.WriteString("**Incomplete report:** the scan stopped before completion.\n\n")
+	}
+	buf.WriteString("| Severity | Code | Location | Message |\n")
+	buf.WriteString("| -------- | ---- | -------- | ------- |\n")
+	for _, d := range diags {
+		fmt.Fprintf(buf, "| %s | %s | %s | %s |\n",
+			d.Severity,
+			d.Code,
+			EscapeMarkdown(d.Location()),
+			EscapeMarkdown(d.Message),
+		)
+	}
+}
+
+// writeCommentDiagnostics appends the diagnostics as comments of a graph
+// format, a line each.
+func writeCommentDiagnostics(buf *bytes.Buffer, diags []diagnostic.Diagnostic, comment func(text string) string) {
+	if diagnostic.Incomplete(diags) {
+		buf.WriteString(comment("Incomplete report: the scan stopped before completion.") + "\n")
+	}
+	for _, d := range diags {
+		buf.WriteString(comment(strings.Join(strings.Fields(d.String()), " ")) + "\n")
+	}
+}
+
+// csvComment returns a comment line of the CSV outputs, which readers such as
+// encoding/csv with Comment set to '#' skip.
+func csvComment(text string) string {
+	return "# " + text
+}
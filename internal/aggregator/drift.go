@@ -0,0 +1,153 @@
+package aggregator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DriftTarget selects the version suggested to align a drifting dependency.
+type DriftTarget string
+
+const (
+	// TargetCommon suggests the most declared version, the highest one on a
+	// tie.
+	TargetCommon DriftTarget = "common"
+	// TargetMax suggests the version with the highest lower bound.
+	TargetMax DriftTarget = "max"
+)
+
+// Drift is an aggregated dependency declared with several distinct versions.
+type Drift struct {
+	AggregatedDependency
+	Spread Spread // Distance between the lowest and highest bounded versions
+	Target string // Suggested version to align on
+}
+
+// Spread is the distance between the lower bounds of two versions: the
+// difference of their majors, of their minors when the majors are equal, or
+// of their patches.
+type Spread struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+}
+
+// String returns the spread, e.g. "2 major", or "none" between versions of
+// the same release.
+func (s Spread) String() string {
+	switch {
+	case s.Major > 0:
+		return fmt.Sprintf("%d major", s.Major)
+	case s.Minor > 0:
+		return fmt.Sprintf("%d minor", s.Minor)
+	case s.Patch > 0:
+		return fmt.Sprintf("%d patch", s.Patch)
+	}
+	return "none"
+}
+
+// compare orders spreads by major, minor then patch distance.
+func (s Spread) compare(o Spread) int {
+	for _, c := range []int{
+		compareUint(s.Major, o.Major),
+		compareUint(s.Minor, o.Minor),
+		compareUint(s.Patch, o.Patch),
+	} {
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// DetectDrift returns the aggregated dependencies declared with more than
+// one distinct version, from the widest spread, with the suggested target.
+func DetectDrift(deps []AggregatedDependency, target DriftTarget) []Drift {
+	var drifts []Drift
+	for _, dep := range deps {
+		if len(dep.Versions) < 2 {
+			continue
+		}
+		comparator := ComparatorFor(dep.Packaging)
+		drift := Drift{
+			AggregatedDependency: dep,
+			Spread:               boundedSpread(comparator, dep.Versions),
+			Target:               dep.MaxVersion,
+		}
+		if target == TargetCommon {
+			drift.Target = mostCommonVersion(dep.Versions)
+		}
+		drifts = append(drifts, drift)
+	}
+
+	sort.SliceStable(drifts, func(i, j int) bool {
+		if c := drifts[i].Spread.compare(drifts[j].Spread); c != 0 {
+			return c > 0
+		}
+		if len(drifts[i].Versions) != len(drifts[j].Versions) {
+			return len(drifts[i].Versions) > len(drifts[j].Versions)
+		}
+		return drifts[i].Name < drifts[j].Name
+	})
+	return drifts
+}
+
+// boundedSpread returns the spread between the lowest and the highest lower
+// bounds of the declared versions. Declarations which are not versions, or
+// allow any version such as * or any, are left out rather than counted from
+// 0.0.0.
+func boundedSpread(comparator VersionComparator, versions []VersionUsage) Spread {
+	var low, high Version
+	for _, v := range versions {
+		bound, ok := declaredLowerBound(comparator, v.Version)
+		if !ok || bound == nil {
+			continue
+		}
+		if low == nil || bound.Compare(low) < 0 {
+			low = bound
+		}
+		if high == nil || bound.Compare(high) > 0 {
+			high = bound
+		}
+	}
+	if low == nil {
+		return Spread{}
+	}
+	lowRelease, highRelease := low.Release(), high.Release()
+	switch {
+	case highRelease[0] != lowRelease[0]:
+		return Spread{Major: distance(highRelease[0], lowRelease[0])}
+	case highRelease[1] != lowRelease[1]:
+		return Spread{Minor: distance(highRelease[1], lowRelease[1])}
+	}
+	return Spread{Patch: distance(highRelease[2], lowRelease[2])}
+}
+
+// mostCommonVersion returns the most declared version, the last one on a tie
+// as versions are sorted from the lowest.
+func mostCommonVersion(versions []VersionUsage) string {
+	best := versions[0]
+	for _, v := range versions[1:] {
+		if v.Count >= best.Count {
+			best = v
+		}
+	}
+	return best.Version
+}
+
+func distance(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package aggregator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+// driftColumns returns the columns of a drift report.
+func driftColumns(keys []GroupKey) []column[Drift] {
+	lift := func(columns []column[AggregatedDependency]) []column[Drift] {
+		lifted := make([]column[Drift], len(columns))
+		for i, c := range columns {
+			value := c.value
+			lifted[i] = column[Drift]{c.header, func(d Drift) string { return value(d.AggregatedDependency) }}
+		}
+		return lifted
+	}
+	leading, trailing := groupColumns(keys)
+	columns := append(lift(leading),
+		column[Drift]{"Spread", func(d Drift) string { return d.Spread.String() }},
+		column[Drift]{"MinVersion", func(d Drift) string { return d.MinVersion }},
+		column[Drift]{"MaxVersion", func(d Drift) string { return d.MaxVersion }},
+		column[Drift]{"Versions", func(d Drift) string { return fmt.Sprint(len(d.Versions)) }},
+		column[Drift]{"Target", func(d Drift) string { return d.Target }},
+		column[Drift]{"Count", func(d Drift) string { return fmt.Sprint(d.Count) }},
+		column[Drift]{"Projects", func(d Drift) string { return fmt.Sprint(d.Projects) }},
+	)
+	return append(columns, lift(trailing)...)
+}
+
+// JSONDriftRenderer implements DriftRenderer for JSON output.
+type JSONDriftRenderer struct{}
+
+func (r *JSONDriftRenderer) Render(drifts []Drift, diags []diagnostic.Diagnostic) ([]byte, error) {
+	return json.MarshalIndent(newJSONReport(drifts, diags), "", "  ")
+}
+
+// CSVDriftRenderer implements DriftRenderer for CSV output, with a column per
+// group key.
+type CSVDriftRenderer struct {
+	GroupBy []GroupKey // DefaultGroupKeys when empty
+}
+
+func (r *CSVDriftRenderer) Render(drifts []Drift, diags []diagnostic.Diagnostic) ([]byte, error) {
+	return renderCSV(driftColumns(r.GroupBy), drifts, diags)
+}
+
+// MarkdownDriftRenderer implements DriftRenderer for Markdown output, with a
+// column per group key.
+type MarkdownDriftRenderer struct {
+	GroupBy []GroupKey // DefaultGroupKeys when empty
+}
+
+func (r *MarkdownDriftRenderer) Render(drifts []Drift, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writeMarkdownTable(&buf, driftColumns(r.GroupBy), drifts)
+	deps := make([]AggregatedDependency, 0, len(drifts))
+	for _, drift := range drifts {
+		deps = append(deps, drift.AggregatedDependency)
+	}
+	writeMarkdownVersions(&buf, deps)
+	writeMarkdownDiagnostics(&buf, diags)
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,77 @@
+package aggregator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectDrift(t *testing.T) {
+	deps := AggregateDependencies([]FlatDependency{
+		{Name: "aligned", Version: "^1.0.0", Category: "prod", Packaging: "node", Path: "a/package.json"},
+		{Name: "aligned", Version: "^1.0.0", Category: "prod", Packaging: "node", Path: "b/package.json"},
+		{Name: "minor", Version: "^1.2.0", Category: "prod", Packaging: "node", Path: "a/package.json"},
+		{Name: "minor", Version: "^1.2.0", Category: "prod", Packaging: "node", Path: "b/package.json"},
+		{Name: "minor", Version: "^1.5.0", Category: "prod", Packaging: "node", Path: "c/package.json"},
+		{Name: "major", Version: "^16.0.0", Category: "prod", Packaging: "node", Path: "a/package.json"},
+		{Name: "major", Version: "^18.2.0", Category: "prod", Packaging: "node", Path: "b/package.json"},
+		{Name: "patch", Version: "1.0.post1", Category: "prod", Packaging: "python", Path: "requirements.txt"},
+		{Name: "patch", Version: "1.0.2", Category: "prod", Packaging: "python", Path: "requirements-dev.txt"},
+		{Name: "unpinned", Version: "*", Category: "prod", Packaging: "node", Path: "a/package.json"},
+		{Name: "unpinned", Version: "^1.13.0", Category: "prod", Packaging: "node", Path: "b/package.json"},
+		{Name: "unpinned", Version: "^1.14.0", Category: "prod", Packaging: "node", Path: "c/package.json"},
+		{Name: "opaque", Version: "file:../a", Category: "prod", Packaging: "node", Path: "a/package.json"},
+		{Name: "opaque", Version: "file:../b", Category: "prod", Packaging: "node", Path: "b/package.json"},
+	})
+
+	tests := []struct {
+		target DriftTarget
+		want   []string // Name, spread and target of each drift
+	}{
+		// The spread of unpinned leaves * out
+		{TargetCommon, []string{"major 2 major ^18.2.0", "minor 3 minor ^1.2.0", "unpinned 1 minor ^1.14.0", "patch 2 patch 1.0.2", "opaque none file:../b"}},
+		{TargetMax, []string{"major 2 major ^18.2.0", "minor 3 minor ^1.5.0", "unpinned 1 minor ^1.14.0", "patch 2 patch 1.0.2", "opaque none file:../b"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.target), func(t *testing.T) {
+			var got []string
+			for _, d := range DetectDrift(deps, tt.target) {
+				got = append(got, strings.Join([]string{d.Name, d.Spread.String(), d.Target}, " "))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectDrift() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownDriftRenderer_Render(t *testing.T) {
+	drifts := []Drift{{
+		AggregatedDependency: sampleDeps[0],
+		Spread:               Spread{Major: 1},
+		Target:               "2.0.0",
+	}}
+	out, err := (&MarkdownDriftRenderer{}).Render(drifts, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"| dep1 | 1 major | 1.0.0 | 2.0.0 | 2 | 2.0.0 | 3 | 3 | prod | node |",
+		"<summary>dep1 (prod, node): 2 versions</summary>",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in the output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestCSVDriftRenderer_Render(t *testing.T) {
+	out, err := (&CSVDriftRenderer{}).Render(nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	header := "Name,Spread,MinVersion,MaxVersion,Versions,Target,Count,Projects,Category,Packaging"
+	if got := strings.TrimSpace(string(out)); got != header {
+		t.Errorf("expected header %q, got %q", header, got)
+	}
+}
@@ -0,0 +1,80 @@
+package aggregator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+// duplicateRow is an installed version of a duplicate package, a row of the
+// CSV and Markdown outputs.
+type duplicateRow struct {
+	pkg *DuplicatePackage
+	InstalledVersion
+}
+
+// dependentsLabel lists the packages pulling a version, after "direct" for a
+// direct dependency of the project.
+func dependentsLabel(v InstalledVersion) string {
+	var labels []string
+	if v.Direct {
+		labels = append(labels, "direct")
+	}
+	for _, d := range v.Dependents {
+		labels = append(labels, packageLabel(d.Name, d.Version))
+	}
+	return strings.Join(labels, ", ")
+}
+
+// duplicateColumns are the columns of a duplicates report, a row per
+// installed version.
+var duplicateColumns = []column[duplicateRow]{
+	{"Name", func(r duplicateRow) string { return r.pkg.Name }},
+	{"Versions", func(r duplicateRow) string { return strconv.Itoa(len(r.pkg.Versions)) }},
+	{"Version", func(r duplicateRow) string { return r.Version }},
+	{"Copies", func(r duplicateRow) string { return strconv.Itoa(r.Copies) }},
+	{"Dependents", func(r duplicateRow) string { return dependentsLabel(r.InstalledVersion) }},
+	{"Project", func(r duplicateRow) string { return r.pkg.Project }},
+	{"Lockfile", func(r duplicateRow) string { return r.pkg.Lockfile }},
+	{"Packaging", func(r duplicateRow) string { return r.pkg.Packaging }},
+}
+
+// duplicateRows flattens the installed versions of the duplicate packages.
+func duplicateRows(duplicates []DuplicatePackage) []duplicateRow {
+	var rows []duplicateRow
+	for i := range duplicates {
+		for _, v := range duplicates[i].Versions {
+			rows = append(rows, duplicateRow{pkg: &duplicates[i], InstalledVersion: v})
+		}
+	}
+	return rows
+}
+
+// JSONDuplicateRenderer implements DuplicateRenderer for JSON output.
+type JSONDuplicateRenderer struct{}
+
+func (r *JSONDuplicateRenderer) Render(duplicates []DuplicatePackage, diags []diagnostic.Diagnostic) ([]byte, error) {
+	return json.MarshalIndent(newJSONReport(duplicates, diags), "", "  ")
+}
+
+// CSVDuplicateRenderer implements DuplicateRenderer for CSV output.
+type CSVDuplicateRenderer struct{}
+
+func (r *CSVDuplicateRenderer) Render(duplicates []DuplicatePackage, diags []diagnostic.Diagnostic) ([]byte, error) {
+	return renderCSV(duplicateColumns, duplicateRows(duplicates), diags)
+}
+
+// MarkdownDuplicateRenderer implements DuplicateRenderer for Markdown output.
+type MarkdownDuplicateRenderer struct{}
+
+func (r *MarkdownDuplicateRenderer) Render(duplicates []DuplicatePackage, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writeMarkdownTable(&buf, duplicateColumns, duplicateRows(duplicates))
+	writeMarkdownDiagnostics(&buf, diags)
+
+	return buf.Bytes(), nil
+}
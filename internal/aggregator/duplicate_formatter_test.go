@@ -0,0 +1,65 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownDuplicateRenderer_Render(t *testing.T) {
+	out, err := (&MarkdownDuplicateRenderer{}).Render(FindDuplicates(sampleResolved), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"| Name | Versions | Version | Copies | Dependents | Project | Lockfile | Packaging |",
+		"| qs | 2 | 5.2.1 | 1 | jest 29.0.0 | web | web/package-lock.json | node |",
+		"| qs | 2 | 6.11.0 | 1 | body-parser 1.20.1, express 4.18.2 | web | web/package-lock.json | node |",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestCSVDuplicateRenderer_Render(t *testing.T) {
+	duplicates := []DuplicatePackage{{
+		Name: "lodash", Packaging: "node", Project: "app", Lockfile: "app/yarn.lock",
+		Versions: []InstalledVersion{
+			{Version: "3.10.1", Copies: 2, Dependents: []PackageVersion{{Name: "a", Version: "1.0.0"}}},
+			{Version: "4.17.21", Copies: 1, Direct: true, Dependents: []PackageVersion{{Name: "b", Version: "1.0.0"}}},
+		},
+	}}
+	out, err := (&CSVDuplicateRenderer{}).Render(duplicates, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Name,Versions,Version,Copies,Dependents,Project,Lockfile,Packaging\n" +
+		"lodash,2,3.10.1,2,a 1.0.0,app,app/yarn.lock,node\n" +
+		"lodash,2,4.17.21,1,\"direct, b 1.0.0\",app,app/yarn.lock,node\n"
+	if string(out) != want {
+		t.Errorf("Render() = %q, want %q", out, want)
+	}
+}
+
+func TestJSONDuplicateRenderer_Render(t *testing.T) {
+	out, err := (&JSONDuplicateRenderer{}).Render(FindDuplicates(sampleResolved), sampleDiagnostics())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report struct {
+		Dependencies []DuplicatePackage
+		Diagnostics  []json.RawMessage
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	if len(report.Dependencies) != 1 || len(report.Dependencies[0].Versions) != 2 || len(report.Diagnostics) != 1 {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+
+	empty, _ := (&JSONDuplicateRenderer{}).Render(nil, nil)
+	if !strings.Contains(string(empty), `"Dependencies": []`) {
+		t.Errorf("expected an empty dependencies list, got:\n%s", empty)
+	}
+}
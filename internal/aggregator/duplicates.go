@@ -0,0 +1,113 @@
+package aggregator
+
+import (
+	"slices"
+	"sort"
+)
+
+// InstalledVersion is a version of a package installed by a lockfile, with the
+// packages pulling it.
+type InstalledVersion struct {
+	Version    string
+	Copies     int              // Directories the version is installed in, e.g. nested node_modules
+	Direct     bool             `json:",omitempty"` // A direct dependency of the project
+	Dependents []PackageVersion // Packages depending on the version
+}
+
+// DuplicatePackage is a package installed in several versions in one
+// project.
+type DuplicatePackage struct {
+	Name      string
+	Packaging string
+	Project   string // Name of the project, or its root
+	Lockfile  string
+	Versions  []InstalledVersion // From the lowest version
+}
+
+// Copies returns the number of installed copies of the package.
+func (d DuplicatePackage) Copies() int {
+	copies := 0
+	for _, v := range d.Versions {
+		copies += v.Copies
+	}
+	return copies
+}
+
+// FindDuplicates returns the packages installed in several versions by the
+// lockfile of each project, those with the most versions then copies first.
+func FindDuplicates(projects []ResolvedProject) []DuplicatePackage {
+	var duplicates []DuplicatePackage
+	for _, p := range projects {
+		index := packageIndex(p.Resolution)
+		direct := make(map[string]bool, len(p.Resolution.Roots))
+		for _, id := range p.Resolution.Roots {
+			direct[id] = true
+		}
+		dependents := make(map[string][]string)
+		byName := make(map[string][]string)
+		for _, pkg := range p.Resolution.Packages {
+			byName[pkg.Name] = append(byName[pkg.Name], pkg.ID)
+			for _, dep := range pkg.Dependencies {
+				dependents[dep] = append(dependents[dep], pkg.ID)
+			}
+		}
+
+		comparator := ComparatorFor(p.Ecosystem)
+		for name, ids := range byName {
+			versions := make(map[string]*InstalledVersion)
+			for _, id := range ids {
+				version := index[id].Version
+				installed, ok := versions[version]
+				if !ok {
+					installed = &InstalledVersion{Version: version}
+					versions[version] = installed
+				}
+				installed.Copies++
+				installed.Direct = installed.Direct || direct[id]
+				for _, dependent := range dependents[id] {
+					pv := PackageVersion{Name: index[dependent].Name, Version: index[dependent].Version}
+					if !slices.Contains(installed.Dependents, pv) {
+						installed.Dependents = append(installed.Dependents, pv)
+					}
+				}
+			}
+			if len(versions) < 2 {
+				continue
+			}
+
+			duplicate := DuplicatePackage{Name: name, Packaging: p.Ecosystem, Project: p.Label(), Lockfile: p.Lockfile}
+			for _, v := range versions {
+				sortPackageVersions(v.Dependents)
+				duplicate.Versions = append(duplicate.Versions, *v)
+			}
+			sort.Slice(duplicate.Versions, func(i, j int) bool {
+				return comparator.Compare(duplicate.Versions[i].Version, duplicate.Versions[j].Version) < 0
+			})
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		a, b := duplicates[i], duplicates[j]
+		if len(a.Versions) != len(b.Versions) {
+			return len(a.Versions) > len(b.Versions)
+		}
+		if a.Copies() != b.Copies() {
+			return a.Copies() > b.Copies()
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Project < b.Project
+	})
+	return duplicates
+}
+
+func sortPackageVersions(packages []PackageVersion) {
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+}
@@ -0,0 +1,87 @@
+package aggregator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	got := FindDuplicates(sampleResolved)
+
+	want := []DuplicatePackage{{
+		Name:      "qs",
+		Packaging: "node",
+		Project:   "web",
+		Lockfile:  "web/package-lock.json",
+		Versions: []InstalledVersion{
+			{Version: "5.2.1", Copies: 1, Dependents: []PackageVersion{{Name: "jest", Version: "29.0.0"}}},
+			{Version: "6.11.0", Copies: 1, Dependents: []PackageVersion{
+				{Name: "body-parser", Version: "1.20.1"},
+				{Name: "express", Version: "4.18.2"},
+			}},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDuplicates() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFindDuplicates_Copies(t *testing.T) {
+	// npm installs the same version in several node_modules directories
+	project := ResolvedProject{
+		Project: parser.Project{Root: "app", Ecosystem: "node", Lockfile: "app/package-lock.json"},
+		Resolution: ecosystem.Resolution{
+			Roots: []string{"node_modules/lodash", "node_modules/a"},
+			Packages: []ecosystem.ResolvedPackage{
+				{ID: "node_modules/lodash", Name: "lodash", Version: "4.17.21"},
+				{ID: "node_modules/a", Name: "a", Version: "1.0.0", Dependencies: []string{"node_modules/a/node_modules/lodash"}},
+				{ID: "node_modules/b", Name: "b", Version: "1.0.0", Dependencies: []string{"node_modules/b/node_modules/lodash"}},
+				{ID: "node_modules/a/node_modules/lodash", Name: "lodash", Version: "3.10.1"},
+				{ID: "node_modules/b/node_modules/lodash", Name: "lodash", Version: "3.10.1"},
+				{ID: "node_modules/c", Name: "c", Version: "2.0.0"},
+				{ID: "node_modules/a/node_modules/c", Name: "c", Version: "1.0.0"},
+				{ID: "node_modules/b/node_modules/c", Name: "c", Version: "1.5.0"},
+			},
+		},
+	}
+
+	got := FindDuplicates([]ResolvedProject{project})
+	if len(got) != 2 || got[0].Name != "c" || got[1].Name != "lodash" {
+		t.Fatalf("expected c (3 versions) before lodash (2 versions), got %+v", got)
+	}
+	lodash := got[1]
+	if lodash.Copies() != 3 {
+		t.Errorf("lodash Copies() = %d, want 3", lodash.Copies())
+	}
+	want := []InstalledVersion{
+		{Version: "3.10.1", Copies: 2, Dependents: []PackageVersion{{Name: "a", Version: "1.0.0"}, {Name: "b", Version: "1.0.0"}}},
+		{Version: "4.17.21", Copies: 1, Direct: true},
+	}
+	if !reflect.DeepEqual(lodash.Versions, want) {
+		t.Errorf("lodash versions = %+v, want %+v", lodash.Versions, want)
+	}
+}
+
+func TestFindDuplicates_GoSum(t *testing.T) {
+	// go.sum keeps the checksums of versions Go no longer selects
+	goSum := "golang.org/x/text v0.3.0 h1:a=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:b=\n" +
+		"golang.org/x/text v0.14.0 h1:c=\n" +
+		"golang.org/x/text v0.14.0/go.mod h1:d=\n"
+	projects := []ProjectDependencies{{
+		Project:      parser.Project{Root: "app", Ecosystem: "go", Lockfile: "app/go.sum"},
+		Dependencies: []FlatDependency{{Name: "golang.org/x/text", Version: "v0.14.0", Category: "prod", Packaging: "go"}},
+	}}
+	read := func(string) ([]byte, error) { return []byte(goSum), nil }
+
+	resolved := ResolveProjects(context.Background(), projects, parser.Options{Read: read}, &diagnostic.Collector{})
+
+	if got := FindDuplicates(resolved); len(got) != 0 {
+		t.Errorf("FindDuplicates() = %+v, want no duplicate", got)
+	}
+}
@@ -5,75 +5,207 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
 )
 
+// renderStream renders dependencies and diagnostics with a stream into memory.
+func renderStream(newStream func(io.Writer) FlatStream, deps []FlatDependency, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var buf bytes.Buffer
+	stream := newStream(&buf)
+	for _, dep := range deps {
+		if err := stream.Write(dep); err != nil {
+			return nil, err
+		}
+	}
+	if err := stream.Close(diags); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // JSONRenderer implements Renderer for JSON output.
 type JSONRenderer struct{}
 
-// Render renders dependencies as JSON.
-func (r *JSONRenderer) Render(deps []FlatDependency) ([]byte, error) {
-	return json.MarshalIndent(deps, "", "  ")
+// jsonFlatDependency adds the location to the JSON of a dependency.
+type jsonFlatDependency struct {
+	FlatDependency
+	Location string
+}
+
+// Render renders dependencies and diagnostics as a JSON object.
+func (r *JSONRenderer) Render(deps []FlatDependency, diags []diagnostic.Diagnostic) ([]byte, error) {
+	return renderStream(NewJSONStream, deps, diags)
+}
+
+// jsonStream writes the JSON object of JSONRenderer one dependency at a time.
+type jsonStream struct {
+	w     io.Writer
+	count int
+}
+
+// NewJSONStream returns a stream writing the output of JSONRenderer to w.
+func NewJSONStream(w io.Writer) FlatStream {
+	return &jsonStream{w: w}
+}
+
+func (s *jsonStream) Write(dep FlatDependency) error {
+	content, err := json.MarshalIndent(jsonFlatDependency{FlatDependency: dep, Location: dep.Location()}, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	separator := ",\n    "
+	if s.count == 0 {
+		separator = "{\n  \"Dependencies\": [\n    "
+	}
+	s.count++
+	if _, err := io.WriteString(s.w, separator); err != nil {
+		return err
+	}
+	_, err = s.w.Write(content)
+	return err
+}
+
+func (s *jsonStream) Close(diags []diagnostic.Diagnostic) error {
+	report := newJSONReport[jsonFlatDependency](nil, diags)
+	content, err := json.MarshalIndent(report.Diagnostics, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if s.count == 0 {
+		buf.WriteString("{\n  \"Dependencies\": [],\n")
+	} else {
+		buf.WriteString("\n  ],\n")
+	}
+	buf.WriteString("  \"Diagnostics\": ")
+	buf.Write(content)
+	if report.Incomplete {
+		buf.WriteString(",\n  \"Incomplete\": true")
+	}
+	buf.WriteString("\n}")
+	_, err = s.w.Write(buf.Bytes())
+	return err
 }
 
 // CSVRenderer implements Renderer for CSV output.
 type CSVRenderer struct{}
 
-// Render renders dependencies as CSV.
-func (r *CSVRenderer) Render(deps []FlatDependency) ([]byte, error) {
-	var buf bytes.Buffer
-	writer := csv.NewWriter(&buf)
+// Render renders dependencies as CSV, followed by the diagnostics as comment
+// lines when there are any.
+func (r *CSVRenderer) Render(deps []FlatDependency, diags []diagnostic.Diagnostic) ([]byte, error) {
+	return renderStream(NewCSVStream, deps, diags)
+}
 
-	// Write header
+// csvStream writes the output of CSVRenderer one dependency at a time.
+type csvStream struct {
+	w       io.Writer
+	writer  *csv.Writer
+	started bool
+}
+
+// NewCSVStream returns a stream writing the output of CSVRenderer to w.
+func NewCSVStream(w io.Writer) FlatStream {
+	return &csvStream{w: w, writer: csv.NewWriter(w)}
+}
+
+func (s *csvStream) header() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
 	header := []string{"Name", "Version", "Category", "Path", "Packaging"}
-	if err := writer.Write(header); err != nil {
-		return nil, fmt.Errorf("error writing CSV header: %w", err)
+	if err := s.writer.Write(header); err != nil {
+		return fmt.Errorf("error writing CSV header: %w", err)
 	}
+	return nil
+}
 
-	// Write records
-	for _, dep := range deps {
-		record := []string{
-			dep.Name,
-			dep.Version,
-			dep.Category,
-			dep.Path,
-			dep.Packaging,
-		}
-		if err := writer.Write(record); err != nil {
-			return nil, fmt.Errorf("error writing CSV record: %w", err)
-		}
+func (s *csvStream) Write(dep FlatDependency) error {
+	if err := s.header(); err != nil {
+		return err
 	}
-
-	writer.Flush()
-
-	if err := writer.Error(); err != nil {
-		return nil, fmt.Errorf("error flushing CSV writer: %w", err)
+	record := []string{
+		dep.Name,
+		dep.Version,
+		dep.Category,
+		dep.Location(),
+		dep.Packaging,
 	}
+	if err := s.writer.Write(record); err != nil {
+		return fmt.Errorf("error writing CSV record: %w", err)
+	}
+	return nil
+}
 
-	return buf.Bytes(), nil
+func (s *csvStream) Close(diags []diagnostic.Diagnostic) error {
+	if err := s.header(); err != nil {
+		return err
+	}
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %w", err)
+	}
+	var buf bytes.Buffer
+	writeCommentDiagnostics(&buf, diags, csvComment)
+	_, err := s.w.Write(buf.Bytes())
+	return err
 }
 
 // MarkdownRenderer implements FlatRenderer for Markdown table output.
 type MarkdownRenderer struct{}
 
-// Render renders dependencies as a Markdown table.
-func (r *MarkdownRenderer) Render(deps []FlatDependency) ([]byte, error) {
-	var buf bytes.Buffer
+// Render renders dependencies as a Markdown table, followed by a table of
+// diagnostics when there are any.
+func (r *MarkdownRenderer) Render(deps []FlatDependency, diags []diagnostic.Diagnostic) ([]byte, error) {
+	return renderStream(NewMarkdownStream, deps, diags)
+}
 
-	// Write header
-	buf.WriteString("| Name | Version | Category | Path | Packaging |\n")
-	buf.WriteString("| ---- | ------- | -------- | ---- | --------- |\n")
+// markdownStream writes the output of MarkdownRenderer one dependency at a
+// time.
+type markdownStream struct {
+	w       io.Writer
+	started bool
+}
 
-	// Write rows
-	for _, dep := range deps {
-		row := fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
-			EscapeMarkdown(dep.Name),
-			EscapeMarkdown(dep.Version),
-			EscapeMarkdown(dep.Category),
-			EscapeMarkdown(dep.Path),
-			EscapeMarkdown(dep.Packaging),
-		)
-		buf.WriteString(row)
+// NewMarkdownStream returns a stream writing the output of MarkdownRenderer
+// to w.
+func NewMarkdownStream(w io.Writer) FlatStream {
+	return &markdownStream{w: w}
+}
+
+func (s *markdownStream) header() error {
+	if s.started {
+		return nil
 	}
+	s.started = true
+	_, err := io.WriteString(s.w, "| Name | Version | Category | Path | Packaging |\n"+
+		"| ---- | ------- | -------- | ---- | --------- |\n")
+	return err
+}
 
-	return buf.Bytes(), nil
+func (s *markdownStream) Write(dep FlatDependency) error {
+	if err := s.header(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(s.w, "| %s | %s | %s | %s | %s |\n",
+		EscapeMarkdown(dep.Name),
+		EscapeMarkdown(dep.Version),
+		EscapeMarkdown(dep.Category),
+		EscapeMarkdown(dep.Location()),
+		EscapeMarkdown(dep.Packaging),
+	)
+	return err
+}
+
+func (s *markdownStream) Close(diags []diagnostic.Diagnostic) error {
+	if err := s.header(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	writeMarkdownDiagnostics(&buf, diags)
+	_, err := s.w.Write(buf.Bytes())
+	return err
 }
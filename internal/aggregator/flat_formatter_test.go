@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"io"
 	"strings"
 	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
 )
 
 func sampleDependencies() []FlatDependency {
@@ -15,6 +18,8 @@ func sampleDependencies() []FlatDependency {
 			Version:   "1.0.0",
 			Category:  "prod",
 			Path:      "/some/path",
+			Line:      12,
+			Column:    5,
 			Packaging: "node",
 		},
 		{
@@ -27,31 +32,59 @@ func sampleDependencies() []FlatDependency {
 	}
 }
 
+func sampleDiagnostics() []diagnostic.Diagnostic {
+	d := diagnostic.Errorf(diagnostic.CodeParse, "/broken/package.json", "invalid character '}'")
+	d.Line = 4
+	return []diagnostic.Diagnostic{d}
+}
+
 func TestJSONRenderer(t *testing.T) {
 	renderer := &JSONRenderer{}
-	output, err := renderer.Render(sampleDependencies())
+	output, err := renderer.Render(sampleDependencies(), sampleDiagnostics())
 	if err != nil {
 		t.Fatalf("JSONRenderer.Render returned error: %v", err)
 	}
 
-	var result []FlatDependency
-	if err := json.Unmarshal(output, &result); err != nil {
+	var report jsonReport[jsonFlatDependency]
+	if err := json.Unmarshal(output, &report); err != nil {
 		t.Fatalf("JSONRenderer.Render output is not valid JSON: %v", err)
 	}
+	result := report.Dependencies
 
 	if len(result) != 2 {
-		t.Errorf("expected 2 dependencies, got %d", len(result))
+		t.Fatalf("expected 2 dependencies, got %d", len(result))
+	}
+	if result[0].Line != 12 || result[0].Column != 5 || result[0].Location != "/some/path:12" {
+		t.Errorf("unexpected location of the first dependency: %+v", result[0])
+	}
+	if result[1].Location != "/another/path" {
+		t.Errorf("unknown line should not be reported: %+v", result[1])
+	}
+	if len(report.Diagnostics) != 1 || report.Diagnostics[0] != sampleDiagnostics()[0] {
+		t.Errorf("unexpected diagnostics: %+v", report.Diagnostics)
+	}
+}
+
+func TestJSONRenderer_Empty(t *testing.T) {
+	output, err := (&JSONRenderer{}).Render(nil, nil)
+	if err != nil {
+		t.Fatalf("JSONRenderer.Render returned error: %v", err)
+	}
+	if want := "{\n  \"Dependencies\": [],\n  \"Diagnostics\": []\n}"; string(output) != want {
+		t.Errorf("JSONRenderer.Render = %s, want %s", output, want)
 	}
 }
 
 func TestCSVRenderer(t *testing.T) {
 	renderer := &CSVRenderer{}
-	output, err := renderer.Render(sampleDependencies())
+	output, err := renderer.Render(sampleDependencies(), sampleDiagnostics())
 	if err != nil {
 		t.Fatalf("CSVRenderer.Render returned error: %v", err)
 	}
 
+	// The diagnostics trailer is made of comment lines
 	reader := csv.NewReader(bytes.NewReader(output))
+	reader.Comment = '#'
 	records, err := reader.ReadAll()
 	if err != nil {
 		t.Fatalf("CSVRenderer.Render output is not valid CSV: %v", err)
@@ -67,11 +100,14 @@ func TestCSVRenderer(t *testing.T) {
 			t.Errorf("CSV header mismatch at column %d: got %q, want %q", i, records[0][i], field)
 		}
 	}
+	if records[1][3] != "/some/path:12" {
+		t.Errorf("expected path:line in the Path column, got %q", records[1][3])
+	}
 }
 
 func TestMarkdownRenderer(t *testing.T) {
 	renderer := &MarkdownRenderer{}
-	output, err := renderer.Render(sampleDependencies())
+	output, err := renderer.Render(sampleDependencies(), sampleDiagnostics())
 	if err != nil {
 		t.Fatalf("MarkdownRenderer.Render returned error: %v", err)
 	}
@@ -85,7 +121,87 @@ func TestMarkdownRenderer(t *testing.T) {
 		t.Errorf("unexpected Markdown header: %q", lines[0])
 	}
 
-	if !strings.HasPrefix(lines[2], "| dep1 |") {
+	if !strings.HasPrefix(lines[2], "| dep1 |") || !strings.Contains(lines[2], "| /some/path:12 |") {
 		t.Errorf("unexpected first row: %q", lines[2])
 	}
+
+	wantDiagnostic := "| error | parse-error | /broken/package.json:4 | invalid character '}' |"
+	if !strings.Contains(string(output), "## Diagnostics") || lines[len(lines)-1] != wantDiagnostic {
+		t.Errorf("expected a Diagnostics section ending with %q, got:\n%s", wantDiagnostic, output)
+	}
+}
+
+func TestCSVRenderer_Incomplete(t *testing.T) {
+	incomplete := diagnostic.Errorf(diagnostic.CodeIncomplete, "", "scan stopped before completion: timed out after 1s")
+	output, err := (&CSVRenderer{}).Render(sampleDependencies()[:1], []diagnostic.Diagnostic{incomplete})
+	if err != nil {
+		t.Fatalf("CSVRenderer.Render returned error: %v", err)
+	}
+
+	want := "# Incomplete report: the scan stopped before completion.\n" +
+		"# error[incomplete]: scan stopped before completion: timed out after 1s\n"
+	if !strings.HasSuffix(string(output), want) {
+		t.Errorf("expected the trailer %q, got:\n%s", want, output)
+	}
+}
+
+func TestJSONStream_MatchesReport(t *testing.T) {
+	incomplete := diagnostic.Errorf(diagnostic.CodeIncomplete, "", "scan stopped before completion: timed out after 1s")
+	tests := []struct {
+		name  string
+		deps  []FlatDependency
+		diags []diagnostic.Diagnostic
+	}{
+		{"empty", nil, nil},
+		{"dependencies and diagnostics", sampleDependencies(), sampleDiagnostics()},
+		{"incomplete", sampleDependencies()[:1], []diagnostic.Diagnostic{incomplete}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			located := make([]jsonFlatDependency, len(tt.deps))
+			for i, dep := range tt.deps {
+				located[i] = jsonFlatDependency{FlatDependency: dep, Location: dep.Location()}
+			}
+			want, err := json.MarshalIndent(newJSONReport(located, tt.diags), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			stream := NewJSONStream(&buf)
+			for _, dep := range tt.deps {
+				if err := stream.Write(dep); err != nil {
+					t.Fatalf("Write() error: %v", err)
+				}
+			}
+			if err := stream.Close(tt.diags); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+			if buf.String() != string(want) {
+				t.Errorf("stream output:\n%s\nwant:\n%s", buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestFlatStreams_Empty(t *testing.T) {
+	tests := []struct {
+		name      string
+		newStream func(io.Writer) FlatStream
+		want      string
+	}{
+		{"csv", NewCSVStream, "Name,Version,Category,Path,Packaging\n"},
+		{"markdown", NewMarkdownStream, "| Name | Version | Category | Path | Packaging |\n| ---- | ------- | -------- | ---- | --------- |\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.newStream(&buf).Close(nil); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Close() wrote %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
 }
@@ -0,0 +1,135 @@
+package aggregator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// NodeKind is the kind of a node of a dependency graph.
+type NodeKind string
+
+const (
+	NodeProject  NodeKind = "project"
+	NodeExternal NodeKind = "external" // A package published outside the scan
+	// NodeEcosystem stands for the external packages of an ecosystem when
+	// they are collapsed.
+	NodeEcosystem NodeKind = "ecosystem"
+)
+
+// GraphNode is a project or a package of a dependency graph.
+type GraphNode struct {
+	ID        string
+	Label     string
+	Kind      NodeKind
+	Ecosystem string
+	Version   string // Version declared by a project
+	Count     int    // Packages collapsed into an ecosystem node
+}
+
+// GraphEdge is a declared dependency, or the declarations collapsed into it.
+type GraphEdge struct {
+	From     string
+	To       string
+	Version  string         // Declared version, empty once collapsed
+	Category string         // e.g., "prod", "dev", empty when collapsed ones differ
+	Status   InternalStatus // Set for a dependency on a project of the scan
+	Count    int            // Declarations collapsed into the edge
+}
+
+// Graph is a graph of the projects of a scan and of their dependencies.
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// GraphOptions control which nodes a dependency graph shows.
+type GraphOptions struct {
+	HideExternal       bool // Only show the dependencies between projects
+	CollapseEcosystems bool // Show one node for the external packages of each ecosystem
+}
+
+// BuildGraph returns the graph of the projects and of the dependencies they
+// declare. Dependencies marked by LinkInternalPackages point to the project
+// publishing them. Nodes and edges are sorted by their identifiers.
+func BuildGraph(projects []ProjectDependencies, opts GraphOptions) Graph {
+	nodes := make(map[string]*GraphNode)
+	edges := make(map[[2]string]*GraphEdge)
+	projectID := func(ecosystem, root string) string {
+		return fmt.Sprintf("project:%s:%s", ecosystem, root)
+	}
+
+	for _, p := range projects {
+		id := projectID(p.Ecosystem, p.Root)
+		nodes[id] = &GraphNode{ID: id, Label: p.Label(), Kind: NodeProject, Ecosystem: p.Ecosystem, Version: p.Version}
+	}
+
+	for _, p := range projects {
+		from := projectID(p.Ecosystem, p.Root)
+		for _, d := range p.Dependencies {
+			var to string
+			switch {
+			case d.Internal != nil:
+				to = projectID(d.Packaging, d.Internal.Project)
+			case opts.HideExternal:
+				continue
+			case opts.CollapseEcosystems:
+				to = "ecosystem:" + d.Packaging
+				if _, ok := nodes[to]; !ok {
+					nodes[to] = &GraphNode{ID: to, Label: d.Packaging + " packages", Kind: NodeEcosystem, Ecosystem: d.Packaging}
+				}
+			default:
+				to = fmt.Sprintf("package:%s:%s", d.Packaging, d.Name)
+				if _, ok := nodes[to]; !ok {
+					nodes[to] = &GraphNode{ID: to, Label: d.Name, Kind: NodeExternal, Ecosystem: d.Packaging}
+				}
+			}
+
+			edge, ok := edges[[2]string{from, to}]
+			if !ok {
+				edge = &GraphEdge{From: from, To: to, Version: d.Version, Category: d.Category}
+				if d.Internal != nil {
+					edge.Status = d.Internal.Status
+				}
+				edges[[2]string{from, to}] = edge
+			} else {
+				// Several declarations of one package, or collapsed packages
+				edge.Version = ""
+				if edge.Category != d.Category {
+					edge.Category = ""
+				}
+			}
+			edge.Count++
+		}
+	}
+
+	// Collapsed nodes count the distinct packages of their ecosystem
+	if opts.CollapseEcosystems && !opts.HideExternal {
+		packages := make(map[[2]string]struct{})
+		for _, p := range projects {
+			for _, d := range p.Dependencies {
+				if d.Internal == nil {
+					packages[[2]string{d.Packaging, d.Name}] = struct{}{}
+				}
+			}
+		}
+		for key := range packages {
+			nodes["ecosystem:"+key[0]].Count++
+		}
+	}
+
+	graph := Graph{Nodes: make([]GraphNode, 0, len(nodes)), Edges: make([]GraphEdge, 0, len(edges))}
+	for _, n := range nodes {
+		graph.Nodes = append(graph.Nodes, *n)
+	}
+	for _, e := range edges {
+		graph.Edges = append(graph.Edges, *e)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph
+}
@@ -0,0 +1,221 @@
+package aggregator
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+// nodeLabel returns the text shown for a node.
+func nodeLabel(n GraphNode) string {
+	switch {
+	case n.Kind == NodeEcosystem:
+		return fmt.Sprintf("%s (%d)", n.Label, n.Count)
+	case n.Version != "":
+		return n.Label + " " + n.Version
+	}
+	return n.Label
+}
+
+// edgeLabel returns the text shown for an edge: the declared version, or the
+// number of collapsed declarations.
+func edgeLabel(e GraphEdge) string {
+	if e.Count > 1 {
+		return strconv.Itoa(e.Count)
+	}
+	return e.Version
+}
+
+// DOTRenderer implements GraphRenderer for Graphviz DOT output.
+type DOTRenderer struct{}
+
+var dotShapes = map[NodeKind]string{NodeProject: "box", NodeExternal: "ellipse", NodeEcosystem: "folder"}
+
+func (r *DOTRenderer) Render(graph Graph, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("digraph dependencies {\n  rankdir=LR;\n")
+	for _, n := range graph.Nodes {
+		fmt.Fprintf(&buf, "  %s [label=%s, shape=%s];\n", strconv.Quote(n.ID), strconv.Quote(nodeLabel(n)), dotShapes[n.Kind])
+	}
+	for _, e := range graph.Edges {
+		attributes := []string{"label=" + strconv.Quote(edgeLabel(e))}
+		if e.Category == "dev" {
+			attributes = append(attributes, "style=dashed")
+		}
+		if e.Status == InternalMismatch {
+			attributes = append(attributes, "color=red")
+		}
+		fmt.Fprintf(&buf, "  %s -> %s [%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strings.Join(attributes, ", "))
+	}
+	buf.WriteString("}\n")
+	writeCommentDiagnostics(&buf, diags, func(text string) string { return "// " + text })
+
+	return buf.Bytes(), nil
+}
+
+// MermaidRenderer implements GraphRenderer for Mermaid flowchart output.
+type MermaidRenderer struct{}
+
+// mermaidShapes are the brackets around the label of each kind of node.
+var mermaidShapes = map[NodeKind][2]string{
+	NodeProject:   {"[", "]"},
+	NodeExternal:  {"(", ")"},
+	NodeEcosystem: {"[[", "]]"},
+}
+
+// mermaidText quotes a label, whose double quotes Mermaid only accepts as an
+// entity.
+func mermaidText(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+func (r *MermaidRenderer) Render(graph Graph, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var buf bytes.Buffer
+
+	// Mermaid identifiers cannot contain the slashes and colons of node IDs
+	ids := make(map[string]string, len(graph.Nodes))
+	buf.WriteString("graph LR\n")
+	for i, n := range graph.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		shape := mermaidShapes[n.Kind]
+		fmt.Fprintf(&buf, "  %s%s%s%s\n", ids[n.ID], shape[0], mermaidText(nodeLabel(n)), shape[1])
+	}
+	var mismatches []string
+	for i, e := range graph.Edges {
+		arrow := "-->"
+		if e.Category == "dev" {
+			arrow = "-.->"
+		}
+		if label := edgeLabel(e); label != "" {
+			arrow += "|" + mermaidText(label) + "|"
+		}
+		fmt.Fprintf(&buf, "  %s %s %s\n", ids[e.From], arrow, ids[e.To])
+		if e.Status == InternalMismatch {
+			mismatches = append(mismatches, strconv.Itoa(i))
+		}
+	}
+	if len(mismatches) > 0 {
+		fmt.Fprintf(&buf, "  linkStyle %s stroke:red\n", strings.Join(mismatches, ","))
+	}
+	writeCommentDiagnostics(&buf, diags, func(text string) string { return "%% " + text })
+
+	return buf.Bytes(), nil
+}
+
+// GraphMLRenderer implements GraphRenderer for GraphML output.
+type GraphMLRenderer struct{}
+
+type graphML struct {
+	XMLName xml.Name       `xml:"graphml"`
+	XMLNS   string         `xml:"xmlns,attr"`
+	Keys    []graphMLKey   `xml:"key"`
+	Graph   graphMLContent `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLContent struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLKeys declare the attributes of the nodes and edges.
+var graphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", Name: "label", Type: "string"},
+	{ID: "kind", For: "node", Name: "kind", Type: "string"},
+	{ID: "ecosystem", For: "node", Name: "ecosystem", Type: "string"},
+	{ID: "version", For: "all", Name: "version", Type: "string"},
+	{ID: "count", For: "all", Name: "count", Type: "int"},
+	{ID: "category", For: "edge", Name: "category", Type: "string"},
+	{ID: "status", For: "edge", Name: "status", Type: "string"},
+}
+
+// graphMLValues returns the data of the non-empty values, in key order.
+func graphMLValues(values ...string) []graphMLData {
+	var data []graphMLData
+	for i := 0; i < len(values); i += 2 {
+		if values[i+1] != "" {
+			data = append(data, graphMLData{Key: values[i], Value: values[i+1]})
+		}
+	}
+	return data
+}
+
+// graphMLCount returns a count, or nothing for nodes which count nothing.
+func graphMLCount(count int) string {
+	if count == 0 {
+		return ""
+	}
+	return strconv.Itoa(count)
+}
+
+// graphMLComment returns an XML comment, which cannot contain a double hyphen.
+// Comments go before the root element.
+func graphMLComment(text string) string {
+	for strings.Contains(text, "--") {
+		text = strings.ReplaceAll(text, "--", "- -")
+	}
+	return "<!-- " + text + " -->"
+}
+
+func (r *GraphMLRenderer) Render(graph Graph, diags []diagnostic.Diagnostic) ([]byte, error) {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLKeys,
+		Graph: graphMLContent{ID: "dependencies", EdgeDefault: "directed"},
+	}
+	for _, n := range graph.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: n.ID, Data: graphMLValues(
+			"label", n.Label,
+			"kind", string(n.Kind),
+			"ecosystem", n.Ecosystem,
+			"version", n.Version,
+			"count", graphMLCount(n.Count),
+		)})
+	}
+	for _, e := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e.From, Target: e.To, Data: graphMLValues(
+			"version", e.Version,
+			"count", graphMLCount(e.Count),
+			"category", e.Category,
+			"status", string(e.Status),
+		)})
+	}
+
+	content, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBufferString(xml.Header)
+	writeCommentDiagnostics(buf, diags, graphMLComment)
+	buf.Write(content)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,187 @@
+package aggregator
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+)
+
+// sampleGraphProjects has a web app using an internal package with a
+// mismatching range, and two external packages.
+func sampleGraphProjects() []ProjectDependencies {
+	return []ProjectDependencies{
+		{
+			Project: parser.Project{Root: "apps/web", Ecosystem: "node", Name: "web", Version: "1.0.0"},
+			Dependencies: []FlatDependency{
+				{Name: "@acme/ui", Version: "^2.0.0", Category: "prod", Packaging: "node",
+					Internal: &InternalPackage{Project: "packages/ui", Version: "3.0.0", Status: InternalMismatch}},
+				{Name: "jest", Version: "^29.0.0", Category: "dev", Packaging: "node"},
+				{Name: "react", Version: "^18.2.0", Category: "prod", Packaging: "node"},
+			},
+		},
+		{
+			Project:      parser.Project{Root: "packages/ui", Ecosystem: "node", Name: "@acme/ui", Version: "3.0.0"},
+			Dependencies: []FlatDependency{{Name: "react", Version: "^18.0.0", Category: "prod", Packaging: "node"}},
+		},
+	}
+}
+
+func TestBuildGraph(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  GraphOptions
+		nodes []string
+		edges []string
+	}{
+		{
+			name:  "every dependency",
+			nodes: []string{"package:node:jest", "package:node:react", "project:node:apps/web", "project:node:packages/ui"},
+			edges: []string{
+				"project:node:apps/web>package:node:jest ^29.0.0",
+				"project:node:apps/web>package:node:react ^18.2.0",
+				"project:node:apps/web>project:node:packages/ui ^2.0.0",
+				"project:node:packages/ui>package:node:react ^18.0.0",
+			},
+		},
+		{
+			name:  "hide external packages",
+			opts:  GraphOptions{HideExternal: true},
+			nodes: []string{"project:node:apps/web", "project:node:packages/ui"},
+			edges: []string{"project:node:apps/web>project:node:packages/ui ^2.0.0"},
+		},
+		{
+			name:  "collapse ecosystems",
+			opts:  GraphOptions{CollapseEcosystems: true},
+			nodes: []string{"ecosystem:node", "project:node:apps/web", "project:node:packages/ui"},
+			edges: []string{
+				"project:node:apps/web>ecosystem:node ",
+				"project:node:apps/web>project:node:packages/ui ^2.0.0",
+				"project:node:packages/ui>ecosystem:node ^18.0.0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := BuildGraph(sampleGraphProjects(), tt.opts)
+			var nodes, edges []string
+			for _, n := range graph.Nodes {
+				nodes = append(nodes, n.ID)
+			}
+			for _, e := range graph.Edges {
+				edges = append(edges, e.From+">"+e.To+" "+e.Version)
+			}
+			if strings.Join(nodes, "\n") != strings.Join(tt.nodes, "\n") {
+				t.Errorf("nodes = %q, want %q", nodes, tt.nodes)
+			}
+			if strings.Join(edges, "\n") != strings.Join(tt.edges, "\n") {
+				t.Errorf("edges = %q, want %q", edges, tt.edges)
+			}
+		})
+	}
+
+	collapsed := BuildGraph(sampleGraphProjects(), GraphOptions{CollapseEcosystems: true})
+	if collapsed.Nodes[0].Count != 2 || collapsed.Edges[0].Count != 2 || collapsed.Edges[0].Category != "" {
+		t.Errorf("collapsed graph = %+v, want 2 packages and 2 declarations of mixed categories", collapsed)
+	}
+}
+
+func TestDOTRenderer_Render(t *testing.T) {
+	out, err := (&DOTRenderer{}).Render(BuildGraph(sampleGraphProjects(), GraphOptions{}), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"digraph dependencies {\n",
+		`  "project:node:apps/web" [label="web 1.0.0", shape=box];`,
+		`  "package:node:react" [label="react", shape=ellipse];`,
+		`  "project:node:apps/web" -> "package:node:jest" [label="^29.0.0", style=dashed];`,
+		`  "project:node:apps/web" -> "project:node:packages/ui" [label="^2.0.0", color=red];`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in the output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestMermaidRenderer_Render(t *testing.T) {
+	out, err := (&MermaidRenderer{}).Render(BuildGraph(sampleGraphProjects(), GraphOptions{CollapseEcosystems: true}), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `graph LR
+  n0[["node packages (2)"]]
+  n1["web 1.0.0"]
+  n2["@acme/ui 3.0.0"]
+  n1 -->|"2"| n0
+  n1 -->|"^2.0.0"| n2
+  n2 -->|"^18.0.0"| n0
+  linkStyle 1 stroke:red
+`
+	if string(out) != want {
+		t.Errorf("Render() = %q, want %q", out, want)
+	}
+}
+
+func TestGraphMLRenderer_Render(t *testing.T) {
+	out, err := (&GraphMLRenderer{}).Render(BuildGraph(sampleGraphProjects(), GraphOptions{HideExternal: true}), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var doc graphML
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	if len(doc.Graph.Nodes) != 2 || len(doc.Graph.Edges) != 1 {
+		t.Fatalf("expected 2 nodes and 1 edge, got:\n%s", out)
+	}
+	edge := doc.Graph.Edges[0]
+	if edge.Source != "project:node:apps/web" || edge.Target != "project:node:packages/ui" {
+		t.Errorf("unexpected edge %+v", edge)
+	}
+	if !strings.Contains(string(out), `<data key="status">mismatch</data>`) {
+		t.Errorf("expected the status of the edge, got:\n%s", out)
+	}
+}
+
+func TestGraphRenderers_Diagnostics(t *testing.T) {
+	graph := BuildGraph(sampleGraphProjects(), GraphOptions{HideExternal: true})
+	diags := append(sampleDiagnostics(), diagnostic.Errorf(diagnostic.CodeIncomplete, "", "scan stopped --- timeout"))
+
+	tests := []struct {
+		renderer GraphRenderer
+		want     []string
+	}{
+		{&DOTRenderer{}, []string{
+			"// Incomplete report: the scan stopped before completion.\n",
+			"// error[parse-error] /broken/package.json:4: invalid character '}'\n",
+		}},
+		{&MermaidRenderer{}, []string{
+			"%% error[parse-error] /broken/package.json:4: invalid character '}'\n",
+		}},
+		{&GraphMLRenderer{}, []string{
+			"?>\n<!-- Incomplete report: the scan stopped before completion. -->\n",
+			"<!-- error[incomplete]: scan stopped - - - timeout -->\n<graphml",
+		}},
+	}
+	for _, tt := range tests {
+		out, err := tt.renderer.Render(graph, diags)
+		if err != nil {
+			t.Fatalf("%T: unexpected error: %v", tt.renderer, err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(out), want) {
+				t.Errorf("%T: expected %q in the output, got:\n%s", tt.renderer, want, out)
+			}
+		}
+	}
+
+	var doc graphML
+	out, _ := (&GraphMLRenderer{}).Render(graph, diags)
+	if err := xml.Unmarshal(out, &doc); err != nil || len(doc.Graph.Nodes) != 2 {
+		t.Errorf("invalid GraphML with comments: %v\n%s", err, out)
+	}
+}
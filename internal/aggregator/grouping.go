@@ -0,0 +1,168 @@
+package aggregator
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GroupField is a field dependencies can be grouped by.
+type GroupField string
+
+const (
+	GroupByName      GroupField = "name"
+	GroupByPackaging GroupField = "packaging"
+	GroupByCategory  GroupField = "category"
+	GroupByDirectory GroupField = "directory" // Directory of the manifest
+	GroupByPath      GroupField = "path"      // Leading directories of the manifest
+)
+
+// GroupKey is a field of the key grouping dependencies. A path key keeps
+// the Depth leading directories of the manifest, relative to the scanned
+// path, or the directories matching Pattern, e.g. apps/*.
+type GroupKey struct {
+	Field   GroupField
+	Depth   int
+	Pattern string
+}
+
+// DefaultGroupKeys group the dependencies with the same name, category and
+// packaging.
+var DefaultGroupKeys = []GroupKey{{Field: GroupByName}, {Field: GroupByCategory}, {Field: GroupByPackaging}}
+
+// ParseGroupBy parses a comma-separated list of group keys: name, packaging,
+// category, directory or its workspace alias, path:N for the N leading
+// directories, or path:PATTERN for the leading directories matching a
+// pattern such as apps/*.
+func ParseGroupBy(spec string) ([]GroupKey, error) {
+	var keys []GroupKey
+	seen := map[GroupField]bool{}
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		var key GroupKey
+		switch {
+		case field == "name", field == "packaging", field == "category", field == "directory":
+			key.Field = GroupField(field)
+		case field == "workspace":
+			key.Field = GroupByDirectory
+		case strings.HasPrefix(field, "path:"):
+			key.Field = GroupByPath
+			prefix := strings.TrimPrefix(field, "path:")
+			if depth, err := strconv.Atoi(prefix); err == nil {
+				if depth < 1 {
+					return nil, fmt.Errorf("invalid group key %q: the depth must be at least 1", field)
+				}
+				key.Depth = depth
+			} else {
+				if _, err := path.Match(prefix, ""); err != nil || prefix == "" {
+					return nil, fmt.Errorf("invalid group key %q: bad pattern", field)
+				}
+				key.Pattern = strings.Trim(prefix, "/")
+			}
+		default:
+			return nil, fmt.Errorf("unknown group key %q: expected name, packaging, category, directory, workspace or path:", field)
+		}
+		if seen[key.Field] {
+			return nil, fmt.Errorf("group key %q is used twice", key.Field)
+		}
+		seen[key.Field] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Grouping tells how dependencies are grouped before being aggregated.
+type Grouping struct {
+	Keys  []GroupKey // DefaultGroupKeys when empty
+	Roots []string   // Scanned paths, to which path keys are relative
+}
+
+// keys returns the group keys, or the default ones.
+func (g Grouping) keys() []GroupKey {
+	if len(g.Keys) == 0 {
+		return DefaultGroupKeys
+	}
+	return g.Keys
+}
+
+// hasGroupField returns true if the keys, or the default ones when empty,
+// group the dependencies by the field.
+func hasGroupField(keys []GroupKey, field GroupField) bool {
+	if len(keys) == 0 {
+		keys = DefaultGroupKeys
+	}
+	for _, k := range keys {
+		if k.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the value of the key for a dependency.
+func (k GroupKey) value(d FlatDependency, roots []string) string {
+	switch k.Field {
+	case GroupByName:
+		return d.Name
+	case GroupByPackaging:
+		return d.Packaging
+	case GroupByCategory:
+		return d.Category
+	case GroupByDirectory:
+		return filepath.ToSlash(filepath.Dir(d.Path))
+	}
+	return k.prefix(path.Dir(relativePath(d.Path, roots)))
+}
+
+// prefix returns the leading directories of dir kept by a path key, or an
+// empty string when they do not match its pattern.
+func (k GroupKey) prefix(dir string) string {
+	segments := strings.Split(dir, "/")
+	if dir == "." {
+		segments = nil
+	}
+	if k.Pattern == "" {
+		if len(segments) > k.Depth {
+			segments = segments[:k.Depth]
+		}
+		if len(segments) == 0 {
+			return "."
+		}
+		return strings.Join(segments, "/")
+	}
+
+	patterns := strings.Split(k.Pattern, "/")
+	if len(segments) < len(patterns) {
+		return ""
+	}
+	for i, p := range patterns {
+		if ok, _ := path.Match(p, segments[i]); !ok {
+			return ""
+		}
+	}
+	return strings.Join(segments[:len(patterns)], "/")
+}
+
+// relativePath returns the path of a manifest relative to the longest
+// scanned path containing it, with forward slashes.
+func relativePath(p string, roots []string) string {
+	p = filepath.ToSlash(filepath.Clean(p))
+	best := ""
+	found := false
+	for _, root := range roots {
+		root = filepath.ToSlash(filepath.Clean(root))
+		rel, ok := strings.CutPrefix(p, strings.TrimSuffix(root, "/")+"/")
+		if root == "." && !path.IsAbs(p) {
+			rel, ok = p, true
+		}
+		if ok && (!found || len(rel) < len(best)) {
+			best, found = rel, true
+		}
+	}
+	if !found {
+		return p
+	}
+	return best
+}
@@ -0,0 +1,138 @@
+package aggregator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []GroupKey
+	}{
+		{"name", []GroupKey{{Field: GroupByName}}},
+		{"name, packaging", []GroupKey{{Field: GroupByName}, {Field: GroupByPackaging}}},
+		{"workspace,category", []GroupKey{{Field: GroupByDirectory}, {Field: GroupByCategory}}},
+		{"path:2", []GroupKey{{Field: GroupByPath, Depth: 2}}},
+		{"name,path:apps/*/", []GroupKey{{Field: GroupByName}, {Field: GroupByPath, Pattern: "apps/*"}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseGroupBy(tt.spec)
+		if err != nil {
+			t.Errorf("ParseGroupBy(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseGroupBy(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"", "version", "name,name", "directory,workspace", "path:0", "path:", "path:[", "path:1,path:2"} {
+		if _, err := ParseGroupBy(invalid); err == nil {
+			t.Errorf("ParseGroupBy(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestGroupDependencies(t *testing.T) {
+	deps := []FlatDependency{
+		{Name: "react", Version: "^18.2.0", Category: "prod", Packaging: "node", Path: "/repo/apps/web/package.json"},
+		{Name: "react", Version: "^17.0.2", Category: "prod", Packaging: "node", Path: "/repo/apps/admin/package.json"},
+		{Name: "react", Version: "^18.2.0", Category: "dev", Packaging: "node", Path: "/repo/libs/ui/package.json"},
+		{Name: "six", Version: "1.16.0", Category: "prod", Packaging: "python", Path: "/repo/apps/web/requirements.txt"},
+		{Name: "six", Version: "1.15.0", Category: "prod", Packaging: "node", Path: "/repo/package.json"},
+	}
+
+	tests := []struct {
+		spec string
+		want []string // Name, directory, path prefix, category, packaging and count of each group, shared fields only
+	}{
+		{"name", []string{
+			"react||||node|3",
+			"six|||prod||2",
+		}},
+		{"name,packaging", []string{
+			"react||||node|3",
+			"six|||prod|node|1",
+			"six|||prod|python|1",
+		}},
+		{"directory", []string{
+			"|/repo/apps/web||prod||2",
+			"react|/repo/apps/admin||prod|node|1",
+			"react|/repo/libs/ui||dev|node|1",
+			"six|/repo||prod|node|1",
+		}},
+		{"name,path:1", []string{
+			"react||apps|prod|node|2",
+			"react||libs|dev|node|1",
+			"six||.|prod|node|1",
+			"six||apps|prod|python|1",
+		}},
+		{"path:apps/*", []string{
+			"||||node|2", // outside apps/*
+			"||apps/web|prod||2",
+			"react||apps/admin|prod|node|1",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			keys, err := ParseGroupBy(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range GroupDependencies(deps, Grouping{Keys: keys, Roots: []string{"/repo"}}) {
+				got = append(got, strings.Join([]string{d.Name, d.Directory, d.PathPrefix, d.Category, d.Packaging}, "|")+"|"+fmt.Sprint(d.Count))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroupDependencies() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupDependencies_MixedPackagings(t *testing.T) {
+	// Python pre-releases are not semantic versions, so they become opaque
+	deps := []FlatDependency{
+		{Name: "six", Version: "2.0rc1", Packaging: "python"},
+		{Name: "six", Version: "1.16.0", Packaging: "node"},
+	}
+	got := GroupDependencies(deps, Grouping{Keys: []GroupKey{{Field: GroupByName}}})
+	if len(got) != 1 || got[0].MinVersion != "1.16.0" || got[0].Satisfiable != UnknownSatisfiability {
+		t.Errorf("GroupDependencies() = %+v", got)
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		path  string
+		roots []string
+		want  string
+	}{
+		{"/repo/apps/web/package.json", []string{"/repo"}, "apps/web/package.json"},
+		{"/repo/apps/web/package.json", []string{"/repo", "/repo/apps/"}, "web/package.json"},
+		{"apps/web/package.json", []string{"."}, "apps/web/package.json"},
+		{"./apps/web/package.json", []string{"./apps"}, "web/package.json"},
+		{"/other/package.json", []string{"/repo"}, "/other/package.json"},
+		{"/repository/package.json", []string{"/repo"}, "/repository/package.json"},
+	}
+	for _, tt := range tests {
+		if got := relativePath(tt.path, tt.roots); got != tt.want {
+			t.Errorf("relativePath(%q, %q) = %q, want %q", tt.path, tt.roots, got, tt.want)
+		}
+	}
+}
+
+func TestCSVAggregateRenderer_GroupBy(t *testing.T) {
+	r := &CSVAggregateRenderer{GroupBy: []GroupKey{{Field: GroupByPath, Depth: 1}, {Field: GroupByName}, {Field: GroupByPackaging}}}
+	out, err := r.Render([]AggregatedDependency{{Name: "react", PathPrefix: "apps", MinVersion: "^17.0.2", MaxVersion: "^18.2.0", Satisfiable: Unsatisfiable, Count: 2, Projects: 2, Category: "prod", Packaging: "node"}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Name,PathPrefix,MinVersion,MaxVersion,Satisfiable,Count,Projects,Packaging\nreact,apps,^17.0.2,^18.2.0,no,2,2,node\n"
+	if string(out) != want {
+		t.Errorf("Render() = %q, want %q", out, want)
+	}
+}
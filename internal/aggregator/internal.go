@@ -0,0 +1,136 @@
+package aggregator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+)
+
+// InternalStatus tells how the version declared for an internal package
+// compares with the version of the local package.
+type InternalStatus string
+
+const (
+	// InternalLocal is a reference to the local sources, such as workspace:*,
+	// a Dart path dependency or a Go module replaced by a directory.
+	InternalLocal InternalStatus = "local"
+	// InternalMatching is a declared version or range allowing the version of
+	// the local package.
+	InternalMatching InternalStatus = "matching"
+	// InternalMismatch is a declared version or range excluding the version of
+	// the local package, so that a published copy is used instead.
+	InternalMismatch InternalStatus = "mismatch"
+	// InternalUnknown is used when either version cannot be compared, for
+	// instance as Go modules do not declare their version.
+	InternalUnknown InternalStatus = "unknown"
+)
+
+// InternalPackage is a project of the scan publishing a dependency.
+type InternalPackage struct {
+	Project string         // Root directory of the project
+	Version string         `json:",omitempty"` // Version declared by the project
+	Status  InternalStatus // How the declared version compares with it
+}
+
+// localProtocols are the prefixes of npm, yarn and pnpm versions referring to
+// local sources.
+var localProtocols = []string{"workspace:", "file:", "link:", "portal:"}
+
+// LinkInternalPackages marks the dependencies whose name and packaging match
+// those of a project of the scan as internal, and compares their declared
+// version with the version of the project. Dependencies of a project on
+// itself are left alone.
+func LinkInternalPackages(projects []ProjectDependencies) {
+	type packageKey struct{ name, ecosystem string }
+	published := make(map[packageKey]parser.Project)
+	for _, p := range projects {
+		key := packageKey{p.Name, p.Ecosystem}
+		if _, found := published[key]; p.Name != "" && !found {
+			published[key] = p.Project
+		}
+	}
+
+	for i := range projects {
+		deps := projects[i].Dependencies
+		for j, d := range deps {
+			local, ok := published[packageKey{d.Name, d.Packaging}]
+			if !ok || local.Root == projects[i].Root {
+				continue
+			}
+			deps[j].Internal = &InternalPackage{
+				Project: local.Root,
+				Version: local.Version,
+				Status:  internalStatus(d, local.Version),
+			}
+		}
+	}
+}
+
+// internalStatus compares the declared version of an internal dependency with
+// the version of the local package.
+func internalStatus(d FlatDependency, localVersion string) InternalStatus {
+	if d.LocalPath != "" {
+		return InternalLocal
+	}
+	declared := d.Version
+	for _, protocol := range localProtocols {
+		if rest, found := strings.CutPrefix(declared, protocol); found {
+			// workspace:^1.2.0 is published as ^1.2.0, workspace:* as is
+			if protocol != "workspace:" || rest == "*" || rest == "^" || rest == "~" {
+				return InternalLocal
+			}
+			declared = rest
+		}
+	}
+	if localVersion == "" {
+		return InternalUnknown
+	}
+	comparator := ComparatorFor(d.Packaging)
+	want, err := comparator.ParseConstraint(declared)
+	if err != nil {
+		return InternalUnknown
+	}
+	have, err := comparator.ParseConstraint(localVersion)
+	if err != nil {
+		return InternalUnknown
+	}
+	if want.Intersect(have).Satisfiable() {
+		return InternalMatching
+	}
+	return InternalMismatch
+}
+
+// InternalDependency is a dependency of a project on another project of the
+// scan.
+type InternalDependency struct {
+	Consumer string // Name of the project declaring the dependency, or its root
+	FlatDependency
+}
+
+// statusOrder lists the mismatches first, as they need attention.
+var statusOrder = map[InternalStatus]int{InternalMismatch: 0, InternalUnknown: 1, InternalMatching: 2, InternalLocal: 3}
+
+// InternalDependencies returns the dependencies marked by
+// LinkInternalPackages, mismatches first, then by name and consumer.
+func InternalDependencies(projects []ProjectDependencies) []InternalDependency {
+	var result []InternalDependency
+	for _, p := range projects {
+		for _, d := range p.Dependencies {
+			if d.Internal != nil {
+				result = append(result, InternalDependency{Consumer: p.Label(), FlatDependency: d})
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if statusOrder[a.Internal.Status] != statusOrder[b.Internal.Status] {
+			return statusOrder[a.Internal.Status] < statusOrder[b.Internal.Status]
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Consumer < b.Consumer
+	})
+	return result
+}
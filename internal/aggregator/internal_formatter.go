@@ -0,0 +1,57 @@
+package aggregator
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+// internalColumns are the columns of an internal dependencies report.
+var internalColumns = []column[InternalDependency]{
+	{"Name", func(d InternalDependency) string { return d.Name }},
+	{"Version", func(d InternalDependency) string { return d.Version }},
+	{"LocalVersion", func(d InternalDependency) string { return d.Internal.Version }},
+	{"Status", func(d InternalDependency) string { return string(d.Internal.Status) }},
+	{"Consumer", func(d InternalDependency) string { return d.Consumer }},
+	{"Path", func(d InternalDependency) string { return d.Location() }},
+	{"Project", func(d InternalDependency) string { return d.Internal.Project }},
+	{"Packaging", func(d InternalDependency) string { return d.Packaging }},
+}
+
+// JSONInternalRenderer implements InternalRenderer for JSON output.
+type JSONInternalRenderer struct{}
+
+// jsonInternalDependency adds the location to the JSON of an internal
+// dependency.
+type jsonInternalDependency struct {
+	InternalDependency
+	Location string
+}
+
+func (r *JSONInternalRenderer) Render(deps []InternalDependency, diags []diagnostic.Diagnostic) ([]byte, error) {
+	rows := make([]jsonInternalDependency, 0, len(deps))
+	for _, d := range deps {
+		rows = append(rows, jsonInternalDependency{InternalDependency: d, Location: d.Location()})
+	}
+	return json.MarshalIndent(newJSONReport(rows, diags), "", "  ")
+}
+
+// CSVInternalRenderer implements InternalRenderer for CSV output.
+type CSVInternalRenderer struct{}
+
+func (r *CSVInternalRenderer) Render(deps []InternalDependency, diags []diagnostic.Diagnostic) ([]byte, error) {
+	return renderCSV(internalColumns, deps, diags)
+}
+
+// MarkdownInternalRenderer implements InternalRenderer for Markdown output.
+type MarkdownInternalRenderer struct{}
+
+func (r *MarkdownInternalRenderer) Render(deps []InternalDependency, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writeMarkdownTable(&buf, internalColumns, deps)
+	writeMarkdownDiagnostics(&buf, diags)
+
+	return buf.Bytes(), nil
+}
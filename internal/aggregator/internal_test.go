@@ -0,0 +1,89 @@
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+)
+
+func TestLinkInternalPackages(t *testing.T) {
+	dep := func(name, version, packaging string) FlatDependency {
+		return FlatDependency{Name: name, Version: version, Category: "prod", Packaging: packaging}
+	}
+	projects := []ProjectDependencies{
+		{
+			Project: parser.Project{Root: "apps/web", Ecosystem: "node", Name: "web"},
+			Dependencies: []FlatDependency{
+				dep("@acme/ui", "workspace:*", "node"),
+				dep("@acme/utils", "^1.2.0", "node"),
+				dep("@acme/api-client", "^2.0.0", "node"),
+				dep("@acme/icons", "workspace:^1.0.0", "node"),
+				dep("react", "^18.2.0", "node"),
+				dep("web", "1.0.0", "node"), // not a dependency on itself
+			},
+		},
+		{
+			Project:      parser.Project{Root: "apps/mobile", Ecosystem: "dart", Name: "mobile"},
+			Dependencies: []FlatDependency{{Name: "acme_ui", Packaging: "dart", LocalPath: "../../packages/acme_ui"}, dep("ui", "^1.0.0", "dart")},
+		},
+		{
+			Project:      parser.Project{Root: "services/api", Ecosystem: "go", Name: "example.com/api"},
+			Dependencies: []FlatDependency{dep("example.com/lib", "v0.3.0", "go")},
+		},
+		{Project: parser.Project{Root: "packages/ui", Ecosystem: "node", Name: "@acme/ui", Version: "3.0.0"}},
+		{Project: parser.Project{Root: "packages/utils", Ecosystem: "node", Name: "@acme/utils", Version: "1.4.1"}},
+		{Project: parser.Project{Root: "packages/api-client", Ecosystem: "node", Name: "@acme/api-client", Version: "1.9.0"}},
+		{Project: parser.Project{Root: "packages/icons", Ecosystem: "node", Name: "@acme/icons", Version: "1.1.0"}},
+		{Project: parser.Project{Root: "packages/acme_ui", Ecosystem: "dart", Name: "acme_ui", Version: "0.1.0"}},
+		{Project: parser.Project{Root: "packages/ui-node", Ecosystem: "node", Name: "ui", Version: "1.0.0"}},
+		{Project: parser.Project{Root: "lib", Ecosystem: "go", Name: "example.com/lib"}},
+	}
+
+	LinkInternalPackages(projects)
+
+	want := map[string]InternalStatus{
+		"@acme/ui":         InternalLocal,
+		"@acme/utils":      InternalMatching,
+		"@acme/api-client": InternalMismatch,
+		"@acme/icons":      InternalMatching,
+		"acme_ui":          InternalLocal,
+		"example.com/lib":  InternalUnknown,
+	}
+	for _, p := range projects {
+		for _, d := range p.Dependencies {
+			status, internal := want[d.Name]
+			switch {
+			case !internal && d.Internal != nil:
+				t.Errorf("%s in %s: unexpected internal package %+v", d.Name, p.Root, d.Internal)
+			case internal && d.Internal == nil:
+				t.Errorf("%s in %s: not marked as internal", d.Name, p.Root)
+			case internal && d.Internal.Status != status:
+				t.Errorf("%s in %s: status = %s, want %s", d.Name, p.Root, d.Internal.Status, status)
+			}
+		}
+	}
+
+	got := InternalDependencies(projects)
+	if len(got) != len(want) || got[0].Name != "@acme/api-client" || got[0].Consumer != "web" || got[0].Internal.Project != "packages/api-client" {
+		t.Errorf("InternalDependencies() = %+v, want the mismatch first", got)
+	}
+}
+
+func TestCSVInternalRenderer_Render(t *testing.T) {
+	deps := []InternalDependency{{
+		Consumer: "web",
+		FlatDependency: FlatDependency{
+			Name: "@acme/utils", Version: "^2.0.0", Path: "apps/web/package.json", Line: 7, Packaging: "node",
+			Internal: &InternalPackage{Project: "packages/utils", Version: "1.4.1", Status: InternalMismatch},
+		},
+	}}
+	out, err := (&CSVInternalRenderer{}).Render(deps, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Name,Version,LocalVersion,Status,Consumer,Path,Project,Packaging\n" +
+		"@acme/utils,^2.0.0,1.4.1,mismatch,web,apps/web/package.json:7,packages/utils,node\n"
+	if string(out) != want {
+		t.Errorf("Render() = %q, want %q", out, want)
+	}
+}
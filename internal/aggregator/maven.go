@@ -0,0 +1,202 @@
+package aggregator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mavenVersion is a Maven artifact version, e.g. 1.0, 1.0-SNAPSHOT or
+// 2.3.1.Final, made of numbers and qualifiers ordered like Maven's
+// ComparableVersion: alpha, beta, milestone, rc, snapshot, the release, then
+// sp, and other qualifiers in alphabetical order.
+type mavenVersion struct {
+	text  string
+	items []mavenItem
+}
+
+// mavenItem is a number, without leading zeros, or a qualifier. The zero
+// value is a missing item.
+type mavenItem struct {
+	numeric   bool
+	number    string
+	qualifier string
+}
+
+var mavenPattern = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z._+-]*$`)
+
+// mavenQualifiers ranks the known qualifiers, the release being "".
+var mavenQualifiers = map[string]int{
+	"alpha": 0, "beta": 1, "milestone": 2, "rc": 3, "snapshot": 4, "": 5, "sp": 6,
+}
+
+// mavenAliases normalises qualifiers. The a, b and m shortcuts only apply
+// when directly followed by a number, as in 1.0-b2.
+var mavenAliases = map[string]string{
+	"cr": "rc", "ga": "", "final": "", "release": "",
+}
+
+func parseMavenVersion(version string) (Version, error) {
+	version = strings.TrimSpace(version)
+	if !mavenPattern.MatchString(version) {
+		return nil, fmt.Errorf("invalid Maven version %q", version)
+	}
+	v := mavenVersion{text: version}
+	for _, token := range strings.FieldsFunc(strings.ToLower(version), func(r rune) bool {
+		return r == '.' || r == '-' || r == '_' || r == '+'
+	}) {
+		for token != "" {
+			run, rest := leadingRun(token)
+			if isDigit(run[0]) {
+				v.items = append(v.items, mavenItem{numeric: true, number: strings.TrimLeft(run, "0")})
+			} else {
+				v.items = append(v.items, mavenItem{qualifier: mavenQualifier(run, rest != "")})
+			}
+			token = rest
+		}
+	}
+	// Trailing zeros and release qualifiers do not change the version
+	for len(v.items) > 0 && v.items[len(v.items)-1].isNull() {
+		v.items = v.items[:len(v.items)-1]
+	}
+	return v, nil
+}
+
+func mavenQualifier(q string, followedByNumber bool) string {
+	if followedByNumber {
+		switch q {
+		case "a":
+			return "alpha"
+		case "b":
+			return "beta"
+		case "m":
+			return "milestone"
+		}
+	}
+	if alias, ok := mavenAliases[q]; ok {
+		return alias
+	}
+	return q
+}
+
+// isNull returns true for 0 and the release qualifiers.
+func (i mavenItem) isNull() bool {
+	return i.number == "" && i.qualifier == ""
+}
+
+// compare compares two items. Numbers are higher than qualifiers, and a
+// missing item or release qualifier is 0 against a number.
+func (i mavenItem) compare(o mavenItem) int {
+	switch {
+	case i.numeric && o.numeric,
+		i.numeric && o.isNull(),
+		o.numeric && i.isNull():
+		return compareNumeric(i.number, o.number)
+	case i.numeric:
+		return 1
+	case o.numeric:
+		return -1
+	}
+	ri, iKnown := mavenQualifiers[i.qualifier]
+	ro, oKnown := mavenQualifiers[o.qualifier]
+	switch {
+	case iKnown && oKnown:
+		return compareInt(int64(ri), int64(ro))
+	case iKnown:
+		return -1
+	case oKnown:
+		return 1
+	}
+	return strings.Compare(i.qualifier, o.qualifier)
+}
+
+func (v mavenVersion) Compare(other Version) int {
+	o := other.(mavenVersion)
+	for i := 0; i < len(v.items) || i < len(o.items); i++ {
+		var a, b mavenItem
+		if i < len(v.items) {
+			a = v.items[i]
+		}
+		if i < len(o.items) {
+			b = o.items[i]
+		}
+		if c := a.compare(b); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Release returns the leading numbers of the version.
+func (v mavenVersion) Release() [3]uint64 {
+	var release [3]uint64
+	for i := 0; i < len(release) && i < len(v.items) && v.items[i].numeric; i++ {
+		release[i], _ = strconv.ParseUint("0"+v.items[i].number, 10, 64)
+	}
+	return release
+}
+
+func (v mavenVersion) String() string {
+	return v.text
+}
+
+var mavenRangePattern = regexp.MustCompile(`[\[(][^\])]*[\])]`)
+
+// parseMavenConstraint parses a version requirement of Maven: a range such as
+// [1.0,2.0), (,1.0] or [1.5,), a union of ranges separated by commas, or a
+// soft requirement such as 1.0, read as an exact version.
+func parseMavenConstraint(declared string) (Constraint, error) {
+	declared = strings.TrimSpace(declared)
+	if !strings.HasPrefix(declared, "[") && !strings.HasPrefix(declared, "(") {
+		v, err := parseMavenVersion(declared)
+		if err != nil {
+			return Constraint{}, err
+		}
+		return exactly(v), nil
+	}
+
+	var c Constraint
+	if strings.Trim(mavenRangePattern.ReplaceAllString(declared, ""), ", ") != "" {
+		return Constraint{}, fmt.Errorf("invalid Maven range %q", declared)
+	}
+	for _, r := range mavenRangePattern.FindAllString(declared, -1) {
+		in, err := parseMavenRange(r)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid Maven range %q: %w", declared, err)
+		}
+		if !in.empty() {
+			c.intervals = append(c.intervals, in)
+		}
+	}
+	return c, nil
+}
+
+func parseMavenRange(r string) (interval, error) {
+	lowerInclusive, upperInclusive := r[0] == '[', r[len(r)-1] == ']'
+	lower, upper, isRange := strings.Cut(r[1:len(r)-1], ",")
+	if !isRange {
+		// [1.0] is exactly 1.0
+		upper = lower
+	}
+
+	var in interval
+	for _, end := range []struct {
+		text      string
+		inclusive bool
+		bound     *bound
+	}{
+		{strings.TrimSpace(lower), lowerInclusive, &in.lower},
+		{strings.TrimSpace(upper), upperInclusive, &in.upper},
+	} {
+		if end.text == "" {
+			continue
+		}
+		v, err := parseMavenVersion(end.text)
+		if err != nil {
+			return interval{}, err
+		}
+		*end.bound = bound{version: v, inclusive: end.inclusive}
+	}
+	return in, nil
+}
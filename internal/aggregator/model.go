@@ -1,28 +1,133 @@
 package aggregator
 
+import (
+	"fmt"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+)
+
 // A single dependency
 type FlatDependency struct {
 	Name      string
 	Version   string
 	Category  string // e.g., "prod", "dev"
 	Path      string
-	Packaging string // e.g., "node", "python"
+	Line      int              `json:",omitempty"` // 1-based line of the declaration, 0 when unknown
+	Column    int              `json:",omitempty"` // 1-based column of the declaration, 0 when unknown
+	Packaging string           // e.g., "node", "python"
+	LocalPath string           `json:",omitempty"` // Directory of the local package used instead of a published version
+	Internal  *InternalPackage `json:",omitempty"` // Project of the scan publishing the package
+}
+
+// Location returns the path of the file declaring the dependency, followed by
+// the line when known, e.g. "app/package.json:12".
+func (d FlatDependency) Location() string {
+	if d.Line <= 0 {
+		return d.Path
+	}
+	return fmt.Sprintf("%s:%d", d.Path, d.Line)
+}
+
+// ProjectDependencies is a project with the dependencies declared by its
+// manifests.
+type ProjectDependencies struct {
+	parser.Project
+	Dependencies []FlatDependency
 }
 
 // An aggregated dependency representeing all the dependency with the same name
+// or, with a Grouping, the same group keys. The name, category and packaging
+// are only set when all the grouped dependencies share them.
 type AggregatedDependency struct {
-	Name       string
-	MinVersion string
-	MaxVersion string
-	Count      uint
-	Category   string // e.g., "prod", "dev"
-	Packaging  string // e.g., "node", "python"
+	Name        string
+	Directory   string         `json:",omitempty"` // Directory of the manifests, when grouped by directory
+	PathPrefix  string         `json:",omitempty"` // Leading directories of the manifests, when grouped by path
+	MinVersion  string         // Declared version or range with the lowest lower bound
+	MaxVersion  string         // Declared version or range with the highest lower bound
+	Satisfiable Satisfiability // Whether one version satisfies every declaration
+	Count       uint           // Declarations of the dependency
+	Projects    uint           // Projects declaring the dependency
+	Category    string         // e.g., "prod", "dev"
+	Packaging   string         // e.g., "node", "python"
+	Versions    []VersionUsage // Every declared version or range, from the lowest
 }
 
+// VersionUsage lists the declarations of a version or range of an aggregated
+// dependency.
+type VersionUsage struct {
+	Version string
+	Count   uint
+	Paths   []string // Locations of the declarations, e.g. "app/package.json:12"
+}
+
+// Satisfiability tells whether a single version satisfies all the declared
+// versions and ranges of an aggregated dependency.
+type Satisfiability string
+
+const (
+	Satisfiable   Satisfiability = "yes"
+	Unsatisfiable Satisfiability = "no"
+	// Some declarations, such as git URLs or paths, are not versions
+	UnknownSatisfiability Satisfiability = "unknown"
+)
+
+// FlatRenderer renders the dependencies and the diagnostics of a scan.
 type FlatRenderer interface {
-	Render(deps []FlatDependency) ([]byte, error)
+	Render(deps []FlatDependency, diags []diagnostic.Diagnostic) ([]byte, error)
+}
+
+// FlatStream writes the dependencies of a scan one at a time as they are
+// found, so that they are not held in memory, then the diagnostics on Close.
+type FlatStream interface {
+	Write(dep FlatDependency) error
+	Close(diags []diagnostic.Diagnostic) error
+}
+
+// ProjectRenderer renders the dependencies of each project and the
+// diagnostics of a scan.
+type ProjectRenderer interface {
+	Render(projects []ProjectDependencies, diags []diagnostic.Diagnostic) ([]byte, error)
+}
+
+// InternalRenderer renders the dependencies between the projects of a scan
+// and the diagnostics of the scan.
+type InternalRenderer interface {
+	Render(deps []InternalDependency, diags []diagnostic.Diagnostic) ([]byte, error)
+}
+
+// GraphRenderer renders the dependency graph of a scan and its diagnostics as
+// comments.
+type GraphRenderer interface {
+	Render(graph Graph, diags []diagnostic.Diagnostic) ([]byte, error)
+}
+
+// TreeRenderer renders the dependency tree of each project and the
+// diagnostics of a scan.
+type TreeRenderer interface {
+	Render(trees []ProjectTree, diags []diagnostic.Diagnostic) ([]byte, error)
 }
 
+// WhyRenderer renders the paths from each project to a package and the
+// diagnostics of a scan.
+type WhyRenderer interface {
+	Render(paths []PackagePaths, diags []diagnostic.Diagnostic) ([]byte, error)
+}
+
+// DuplicateRenderer renders the packages installed in several versions by
+// each lockfile and the diagnostics of a scan.
+type DuplicateRenderer interface {
+	Render(duplicates []DuplicatePackage, diags []diagnostic.Diagnostic) ([]byte, error)
+}
+
+// AggregateRenderer renders the aggregated dependencies and the diagnostics
+// of a scan.
 type AggregateRenderer interface {
-	Render(deps []AggregatedDependency) ([]byte, error)
+	Render(deps []AggregatedDependency, diags []diagnostic.Diagnostic) ([]byte, error)
+}
+
+// DriftRenderer renders the drifting dependencies and the diagnostics of a
+// scan.
+type DriftRenderer interface {
+	Render(drifts []Drift, diags []diagnostic.Diagnostic) ([]byte, error)
 }
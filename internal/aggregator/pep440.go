@@ -0,0 +1,265 @@
+package aggregator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pep440Version is a Python version as specified by PEP 440, e.g. 1!2.0rc1,
+// 1.0.post1 or 2.1.dev3+local.
+type pep440Version struct {
+	epoch   int64
+	release []int64
+	pre     int // 0 for a, 1 for b, 2 for rc, preFinal without pre-release
+	preNum  int64
+	post    int64 // -1 without post-release
+	dev     int64 // -1 without development release
+	local   string
+}
+
+// preFinal ranks versions without pre-release after pre-releases.
+const preFinal = 3
+
+var pep440Pattern = regexp.MustCompile(`(?i)^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d+)?)?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?` +
+	`(?:[-_.]?(dev)[-_.]?(\d+)?)?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+var pep440PreRanks = map[string]int{
+	"a": 0, "alpha": 0, "b": 1, "beta": 1, "c": 2, "rc": 2, "pre": 2, "preview": 2,
+}
+
+func parsePEP440(version string) (Version, error) {
+	v, err := parsePEP440Version(version)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func parsePEP440Version(version string) (pep440Version, error) {
+	m := pep440Pattern.FindStringSubmatch(strings.TrimSpace(version))
+	if m == nil {
+		return pep440Version{}, fmt.Errorf("invalid PEP 440 version %q", version)
+	}
+	number := func(s string) int64 {
+		n, _ := strconv.ParseInt(s, 10, 64)
+		return n
+	}
+	v := pep440Version{epoch: number(m[1]), pre: preFinal, post: -1, dev: -1, local: strings.ToLower(m[10])}
+	for _, segment := range strings.Split(m[2], ".") {
+		v.release = append(v.release, number(segment))
+	}
+	if m[3] != "" {
+		v.pre, v.preNum = pep440PreRanks[strings.ToLower(m[3])], number(m[4])
+	}
+	switch {
+	case m[5] != "":
+		v.post = number(m[5])
+	case m[6] != "":
+		v.post = number(m[7])
+	}
+	if m[8] != "" {
+		v.dev = number(m[9])
+	}
+	return v, nil
+}
+
+func (v pep440Version) Compare(other Version) int {
+	o := other.(pep440Version)
+	if c := compareInt(v.epoch, o.epoch); c != 0 {
+		return c
+	}
+	if c := compareSegments(v.release, o.release); c != 0 {
+		return c
+	}
+	if c := compareInt(v.preKey(), o.preKey()); c != 0 {
+		return c
+	}
+	if c := compareInt(v.preNum, o.preNum); c != 0 {
+		return c
+	}
+	if c := compareInt(v.post, o.post); c != 0 {
+		return c
+	}
+	if c := compareInt(v.devKey(), o.devKey()); c != 0 {
+		return c
+	}
+	return compareOpaque(v.local, o.local)
+}
+
+// preKey ranks development releases without pre- or post-release, such as
+// 1.0.dev1, before the pre-releases of the same release.
+func (v pep440Version) preKey() int64 {
+	if v.pre == preFinal && v.post < 0 && v.dev >= 0 {
+		return -1
+	}
+	return int64(v.pre)
+}
+
+// devKey ranks versions without development release after those with one.
+func (v pep440Version) devKey() int64 {
+	if v.dev < 0 {
+		return math.MaxInt64
+	}
+	return v.dev
+}
+
+func (v pep440Version) Release() [3]uint64 {
+	var release [3]uint64
+	for i := 0; i < len(release) && i < len(v.release); i++ {
+		release[i] = uint64(v.release[i])
+	}
+	return release
+}
+
+func (v pep440Version) String() string {
+	var b strings.Builder
+	if v.epoch != 0 {
+		fmt.Fprintf(&b, "%d!", v.epoch)
+	}
+	for i, n := range v.release {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		fmt.Fprint(&b, n)
+	}
+	if v.pre != preFinal {
+		fmt.Fprintf(&b, "%s%d", [...]string{"a", "b", "rc"}[v.pre], v.preNum)
+	}
+	if v.post >= 0 {
+		fmt.Fprintf(&b, ".post%d", v.post)
+	}
+	if v.dev >= 0 {
+		fmt.Fprintf(&b, ".dev%d", v.dev)
+	}
+	if v.local != "" {
+		b.WriteString("+" + v.local)
+	}
+	return b.String()
+}
+
+// earliest returns the lowest version of the release of v, its first
+// development release.
+func (v pep440Version) earliest() pep440Version {
+	return pep440Version{epoch: v.epoch, release: v.release, pre: preFinal, post: -1, dev: 0}
+}
+
+// nextRelease returns the earliest version after every version whose
+// release starts with the given number of segments of v, e.g. 1.3.dev0 for
+// two segments of 1.2.5.
+func (v pep440Version) nextRelease(segments int) pep440Version {
+	release := append([]int64(nil), v.release[:segments]...)
+	release[segments-1]++
+	return pep440Version{epoch: v.epoch, release: release, pre: preFinal, post: -1, dev: 0}
+}
+
+// isFinal returns true for versions without pre-, post- or development
+// release.
+func (v pep440Version) isFinal() bool {
+	return v.pre == preFinal && v.post < 0 && v.dev < 0
+}
+
+// parsePEP440Constraint parses version specifiers separated by commas, such
+// as >=1.0,<2.0 or ~=1.4.2. A bare version is an exact version, as the
+// requirements parser reports the version following ==.
+func parsePEP440Constraint(declared string) (Constraint, error) {
+	result := Constraint{intervals: []interval{anyInterval}}
+	for _, specifier := range strings.Split(declared, ",") {
+		in, err := parsePEP440Specifier(strings.TrimSpace(specifier))
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid specifier %q: %w", declared, err)
+		}
+		result = result.Intersect(Constraint{intervals: []interval{in}})
+	}
+	return result, nil
+}
+
+// pep440Operators are sorted so that longer operators are matched first.
+var pep440Operators = []string{"===", "~=", "==", "!=", "<=", ">=", "<", ">"}
+
+func parsePEP440Specifier(specifier string) (interval, error) {
+	if specifier == "" || specifier == "*" {
+		return anyInterval, nil
+	}
+	op := ""
+	for _, candidate := range pep440Operators {
+		if strings.HasPrefix(specifier, candidate) {
+			op = candidate
+			break
+		}
+	}
+	text := strings.TrimSpace(specifier[len(op):])
+	if op == "!=" {
+		return anyInterval, nil
+	}
+	if prefix, found := strings.CutSuffix(text, ".*"); found && (op == "" || op == "==") {
+		v, err := parsePEP440Version(prefix)
+		if err != nil {
+			return interval{}, err
+		}
+		return interval{
+			lower: bound{version: v.earliest(), inclusive: true},
+			upper: bound{version: v.nextRelease(len(v.release))},
+		}, nil
+	}
+
+	v, err := parsePEP440Version(text)
+	if err != nil {
+		return interval{}, err
+	}
+	at := bound{version: v, inclusive: true}
+	switch op {
+	case "", "==", "===":
+		return interval{lower: at, upper: at}, nil
+	case ">=":
+		return interval{lower: at}, nil
+	case ">":
+		return interval{lower: bound{version: v}}, nil
+	case "<=":
+		return interval{upper: at}, nil
+	case "<":
+		// <2.0 excludes the pre-releases of 2.0, unless 2.0 is one
+		if v.isFinal() {
+			return interval{upper: bound{version: v.earliest()}}, nil
+		}
+		return interval{upper: bound{version: v}}, nil
+	default:
+		// ~=1.4.2 is >=1.4.2 and ==1.4.*
+		if len(v.release) < 2 {
+			return interval{}, fmt.Errorf("%s requires at least two release segments", op)
+		}
+		return interval{lower: at, upper: bound{version: v.nextRelease(len(v.release) - 1)}}, nil
+	}
+}
+
+// compareSegments compares two lists of numbers, missing numbers being 0.
+func compareSegments(a, b []int64) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int64
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareInt(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
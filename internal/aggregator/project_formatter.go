@@ -0,0 +1,134 @@
+package aggregator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+)
+
+// projectDependency is a dependency with the project declaring it, a row of
+// the CSV output.
+type projectDependency struct {
+	project *ProjectDependencies
+	FlatDependency
+}
+
+// projectColumns are the columns of the CSV output, a row per dependency.
+var projectColumns = []column[projectDependency]{
+	{"Project", func(d projectDependency) string { return d.project.Label() }},
+	{"ProjectVersion", func(d projectDependency) string { return d.project.Version }},
+	{"Root", func(d projectDependency) string { return d.project.Root }},
+	{"Name", func(d projectDependency) string { return d.Name }},
+	{"Version", func(d projectDependency) string { return d.Version }},
+	{"Category", func(d projectDependency) string { return d.Category }},
+	{"Path", func(d projectDependency) string { return d.Location() }},
+	{"Packaging", func(d projectDependency) string { return d.Packaging }},
+	{"Internal", func(d projectDependency) string { return internalLabel(d.FlatDependency) }},
+}
+
+// dependencyColumns are the columns of the dependencies of a project in the
+// Markdown output, whose section names the packaging.
+var dependencyColumns = []column[FlatDependency]{
+	{"Name", func(d FlatDependency) string { return d.Name }},
+	{"Version", func(d FlatDependency) string { return d.Version }},
+	{"Category", func(d FlatDependency) string { return d.Category }},
+	{"Path", func(d FlatDependency) string { return d.Location() }},
+	{"Internal", internalLabel},
+}
+
+// internalLabel returns the status of an internal dependency, or nothing for
+// an external one.
+func internalLabel(d FlatDependency) string {
+	if d.Internal == nil {
+		return ""
+	}
+	return string(d.Internal.Status)
+}
+
+// writeProjectHeading writes the heading of the Markdown section of a
+// project, e.g. "## web 1.2.0 (node)".
+func writeProjectHeading(buf *bytes.Buffer, p parser.Project) {
+	heading := p.Label()
+	if p.Version != "" {
+		heading += " " + p.Version
+	}
+	fmt.Fprintf(buf, "## %s (%s)\n\n", heading, p.Ecosystem)
+}
+
+// JSONProjectRenderer implements ProjectRenderer for JSON output.
+type JSONProjectRenderer struct{}
+
+// jsonProject adds the location to the JSON of the dependencies of a project.
+type jsonProject struct {
+	ProjectDependencies
+	Dependencies []jsonFlatDependency
+}
+
+type jsonProjectReport struct {
+	Projects    []jsonProject
+	Diagnostics []diagnostic.Diagnostic
+	Incomplete  bool `json:",omitempty"`
+}
+
+func (r *JSONProjectRenderer) Render(projects []ProjectDependencies, diags []diagnostic.Diagnostic) ([]byte, error) {
+	report := newJSONReport[jsonFlatDependency](nil, diags)
+	result := jsonProjectReport{
+		Projects:    make([]jsonProject, 0, len(projects)),
+		Diagnostics: report.Diagnostics,
+		Incomplete:  report.Incomplete,
+	}
+	for _, p := range projects {
+		deps := make([]jsonFlatDependency, 0, len(p.Dependencies))
+		for _, dep := range p.Dependencies {
+			deps = append(deps, jsonFlatDependency{FlatDependency: dep, Location: dep.Location()})
+		}
+		result.Projects = append(result.Projects, jsonProject{ProjectDependencies: p, Dependencies: deps})
+	}
+	return json.MarshalIndent(result, "", "  ")
+}
+
+// CSVProjectRenderer implements ProjectRenderer for CSV output, with a row
+// per dependency. Projects without dependencies do not fit in the table.
+type CSVProjectRenderer struct{}
+
+func (r *CSVProjectRenderer) Render(projects []ProjectDependencies, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var rows []projectDependency
+	for i := range projects {
+		for _, dep := range projects[i].Dependencies {
+			rows = append(rows, projectDependency{project: &projects[i], FlatDependency: dep})
+		}
+	}
+	return renderCSV(projectColumns, rows, diags)
+}
+
+// MarkdownProjectRenderer implements ProjectRenderer for Markdown output, with
+// a section per project.
+type MarkdownProjectRenderer struct{}
+
+func (r *MarkdownProjectRenderer) Render(projects []ProjectDependencies, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for i, p := range projects {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		writeProjectHeading(&buf, p.Project)
+		lockfile := p.Lockfile
+		if lockfile == "" {
+			lockfile = "none"
+		}
+		fmt.Fprintf(&buf, "- Root: %s\n- Manifests: %s\n- Lockfile: %s\n\n", p.Root, strings.Join(p.Manifests, ", "), lockfile)
+		if len(p.Dependencies) == 0 {
+			buf.WriteString("No dependencies.\n")
+			continue
+		}
+		writeMarkdownTable(&buf, dependencyColumns, p.Dependencies)
+	}
+	writeMarkdownDiagnostics(&buf, diags)
+
+	return buf.Bytes(), nil
+}
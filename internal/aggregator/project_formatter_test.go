@@ -0,0 +1,77 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+)
+
+var sampleProjects = []ProjectDependencies{
+	{
+		Project: parser.Project{Root: "api", Ecosystem: "python", Manifests: []string{"api/requirements.txt"}},
+	},
+	{
+		Project: parser.Project{Root: "web", Ecosystem: "node", Name: "web", Version: "1.0.0", Manifests: []string{"web/package.json"}, Lockfile: "web/yarn.lock"},
+		Dependencies: []FlatDependency{
+			{Name: "react", Version: "^18.2.0", Category: "prod", Path: "web/package.json", Line: 4, Packaging: "node"},
+		},
+	},
+}
+
+func TestJSONProjectRenderer_Render(t *testing.T) {
+	out, err := (&JSONProjectRenderer{}).Render(sampleProjects, sampleDiagnostics())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var report struct {
+		Projects []struct {
+			Root         string
+			Name         string
+			Lockfile     string
+			Dependencies []struct{ Name, Location string }
+		}
+		Diagnostics []json.RawMessage
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	if len(report.Projects) != 2 || len(report.Diagnostics) != 1 {
+		t.Fatalf("expected 2 projects and 1 diagnostic, got:\n%s", out)
+	}
+	web := report.Projects[1]
+	if web.Name != "web" || web.Lockfile != "web/yarn.lock" || len(web.Dependencies) != 1 || web.Dependencies[0].Location != "web/package.json:4" {
+		t.Errorf("unexpected project: %+v", web)
+	}
+}
+
+func TestCSVProjectRenderer_Render(t *testing.T) {
+	out, err := (&CSVProjectRenderer{}).Render(sampleProjects, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Project,ProjectVersion,Root,Name,Version,Category,Path,Packaging,Internal\n" +
+		"web,1.0.0,web,react,^18.2.0,prod,web/package.json:4,node,\n"
+	if string(out) != want {
+		t.Errorf("Render() = %q, want %q", out, want)
+	}
+}
+
+func TestMarkdownProjectRenderer_Render(t *testing.T) {
+	out, err := (&MarkdownProjectRenderer{}).Render(sampleProjects, sampleDiagnostics())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"## api (python)\n\n- Root: api\n- Manifests: api/requirements.txt\n- Lockfile: none\n\nNo dependencies.\n",
+		"\n## web 1.0.0 (node)\n\n- Root: web\n- Manifests: web/package.json\n- Lockfile: web/yarn.lock\n\n",
+		"| react | ^18.2.0 | prod | web/package.json:4 |  |\n",
+		"\n## Diagnostics\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in the output, got:\n%s", want, out)
+		}
+	}
+}
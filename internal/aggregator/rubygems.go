@@ -0,0 +1,163 @@
+package aggregator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// gemVersion is a RubyGems version, e.g. 1.2.3 or 2.0.0.rc1. Segments
+// containing letters make the version a pre-release, lower than the release.
+type gemVersion struct {
+	text     string
+	segments []string // Runs of digits or letters
+}
+
+var gemPattern = regexp.MustCompile(`^[0-9]+(\.[0-9A-Za-z]+)*(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+func parseGemVersion(version string) (Version, error) {
+	v, err := parseGem(version)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func parseGem(version string) (gemVersion, error) {
+	version = strings.TrimSpace(version)
+	if !gemPattern.MatchString(version) {
+		return gemVersion{}, fmt.Errorf("invalid gem version %q", version)
+	}
+	v := gemVersion{text: version}
+	// As in RubyGems, 1.0-beta is 1.0.pre.beta
+	for _, segment := range strings.Split(strings.ReplaceAll(version, "-", ".pre."), ".") {
+		for segment != "" {
+			run, rest := leadingRun(segment)
+			v.segments = append(v.segments, run)
+			segment = rest
+		}
+	}
+	return v, nil
+}
+
+// Compare compares the segments in order: numbers by value, letters
+// alphabetically and lower than numbers, missing segments being 0.
+func (v gemVersion) Compare(other Version) int {
+	o := other.(gemVersion)
+	for i := 0; i < len(v.segments) || i < len(o.segments); i++ {
+		a, b := "0", "0"
+		if i < len(v.segments) {
+			a = v.segments[i]
+		}
+		if i < len(o.segments) {
+			b = o.segments[i]
+		}
+		aNumber, bNumber := isDigit(a[0]), isDigit(b[0])
+		switch {
+		case aNumber && !bNumber:
+			return 1
+		case !aNumber && bNumber:
+			return -1
+		}
+		if c := compareRuns(a, b); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Release returns the leading numbers of the version.
+func (v gemVersion) Release() [3]uint64 {
+	var release [3]uint64
+	for i := 0; i < len(release) && i < len(v.segments) && isDigit(v.segments[i][0]); i++ {
+		release[i], _ = strconv.ParseUint(v.segments[i], 10, 64)
+	}
+	return release
+}
+
+func (v gemVersion) String() string {
+	return v.text
+}
+
+// bump returns the upper bound of ~> for the version: the release without
+// its last segment, incremented, e.g. 1.3 for 1.2.3 and 2 for 1.2.
+func (v gemVersion) bump() gemVersion {
+	var release []string
+	for _, s := range v.segments {
+		if !isDigit(s[0]) {
+			break
+		}
+		release = append(release, s)
+	}
+	if len(release) > 1 {
+		release = release[:len(release)-1]
+	}
+	last := release[len(release)-1]
+	release[len(release)-1] = incrementNumeric(last)
+	return gemVersion{text: strings.Join(release, "."), segments: release}
+}
+
+// incrementNumeric adds one to a string of digits.
+func incrementNumeric(n string) string {
+	digits := []byte(n)
+	for i := len(digits) - 1; i >= 0; i-- {
+		if digits[i] < '9' {
+			digits[i]++
+			return string(digits)
+		}
+		digits[i] = '0'
+	}
+	return "1" + string(digits)
+}
+
+// gemOperators are sorted so that longer operators are matched first.
+var gemOperators = []string{"~>", "!=", ">=", "<=", "=", ">", "<"}
+
+// parseGemConstraint parses the requirements of a gem separated by commas,
+// e.g. ~> 1.2, >= 1.2.3. Quotes around each requirement are ignored.
+func parseGemConstraint(declared string) (Constraint, error) {
+	result := Constraint{intervals: []interval{anyInterval}}
+	for _, requirement := range strings.Split(declared, ",") {
+		in, err := parseGemRequirement(strings.Trim(strings.TrimSpace(requirement), `'"`))
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid requirement %q: %w", declared, err)
+		}
+		result = result.Intersect(Constraint{intervals: []interval{in}})
+	}
+	return result, nil
+}
+
+func parseGemRequirement(requirement string) (interval, error) {
+	if requirement == "" {
+		return anyInterval, nil
+	}
+	op := ""
+	for _, candidate := range gemOperators {
+		if strings.HasPrefix(requirement, candidate) {
+			op = candidate
+			break
+		}
+	}
+	v, err := parseGem(requirement[len(op):])
+	if err != nil {
+		return interval{}, err
+	}
+	at := bound{version: v, inclusive: true}
+	switch op {
+	case "", "=":
+		return interval{lower: at, upper: at}, nil
+	case "!=":
+		return anyInterval, nil
+	case ">=":
+		return interval{lower: at}, nil
+	case ">":
+		return interval{lower: bound{version: v}}, nil
+	case "<=":
+		return interval{upper: at}, nil
+	case "<":
+		return interval{upper: bound{version: v}}, nil
+	default:
+		return interval{lower: at, upper: bound{version: v.bump()}}, nil
+	}
+}
@@ -0,0 +1,254 @@
+package aggregator
+
+import (
+	"context"
+	"sort"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+// ResolvedProject is a project with the dependency graph of its lockfile.
+type ResolvedProject struct {
+	parser.Project
+	Resolution ecosystem.Resolution
+	Unreadable bool // The lockfile could not be read, see the diagnostics
+}
+
+// ResolveProjects reads the lockfiles of the projects with opts, reporting
+// those which cannot be read to diags and marking their project Unreadable,
+// unless opts recovers part of them. Projects without a lockfile are left
+// out. When ctx is done first, it returns the projects resolved so far and
+// reports the scan as incomplete.
+func ResolveProjects(ctx context.Context, projects []ProjectDependencies, opts parser.Options, diags *diagnostic.Collector) []ResolvedProject {
+	var resolved []ResolvedProject
+	for _, p := range projects {
+		if p.Lockfile == "" {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		declared := make([]parser.Dependency, 0, len(p.Dependencies))
+		for _, d := range p.Dependencies {
+			declared = append(declared, parser.Dependency{Name: d.Name, Version: d.Version, Category: d.Category})
+		}
+		resolution, err := parser.ReadLockfile(p.Project, declared, opts)
+		if err != nil && ctx.Err() != nil {
+			// The lockfile was not read because of the cancellation
+			break
+		}
+		if err != nil {
+			partial := resolution.Packages != nil
+			diags.Report(parser.LockfileDiagnostic(p.Lockfile, err, partial))
+			if !partial {
+				resolved = append(resolved, ResolvedProject{Project: p.Project, Unreadable: true})
+				continue
+			}
+		}
+		resolved = append(resolved, ResolvedProject{Project: p.Project, Resolution: resolution})
+	}
+	if ctx.Err() != nil {
+		diags.Report(diagnostic.Errorf(diagnostic.CodeIncomplete, "", "scan stopped before completion: %v", context.Cause(ctx)))
+	}
+	return resolved
+}
+
+// packageIndex returns the packages of a resolution by ID.
+func packageIndex(r ecosystem.Resolution) map[string]ecosystem.ResolvedPackage {
+	index := make(map[string]ecosystem.ResolvedPackage, len(r.Packages))
+	for _, pkg := range r.Packages {
+		index[pkg.ID] = pkg
+	}
+	return index
+}
+
+// sortedPackages returns the IDs of the packages found in index, sorted by
+// name then version.
+func sortedPackages(ids []string, index map[string]ecosystem.ResolvedPackage) []string {
+	sorted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := index[id]; ok {
+			sorted = append(sorted, id)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := index[sorted[i]], index[sorted[j]]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+	return sorted
+}
+
+// unlinkedPackages returns the IDs of the packages of a lockfile which does
+// not record the dependencies between packages, other than the direct
+// dependencies of the project.
+func unlinkedPackages(r ecosystem.Resolution, index map[string]ecosystem.ResolvedPackage) []string {
+	if !r.Unlinked {
+		return nil
+	}
+	direct := make(map[string]bool, len(r.Roots))
+	for _, id := range r.Roots {
+		direct[id] = true
+	}
+	var ids []string
+	for _, pkg := range r.Packages {
+		if !direct[pkg.ID] {
+			ids = append(ids, pkg.ID)
+		}
+	}
+	return sortedPackages(ids, index)
+}
+
+// TreeNode is a package of a dependency tree.
+type TreeNode struct {
+	Name         string
+	Version      string
+	Deduped      bool       `json:",omitempty"` // Dependencies listed earlier in the tree
+	Dependencies []TreeNode `json:",omitempty"`
+}
+
+// ProjectTree is the dependency tree of a project, read from its lockfile.
+type ProjectTree struct {
+	parser.Project
+	Dependencies []TreeNode
+	// Unlinked are the packages installed for the dependencies of the project
+	// when the lockfile does not record which ones, as with pubspec.lock.
+	Unlinked   []TreeNode `json:",omitempty"`
+	Unreadable bool       `json:",omitempty"` // The lockfile could not be read
+}
+
+// DependencyTrees returns the dependency tree of each project. Like npm ls,
+// the dependencies of a package are listed where it is first found only,
+// which also stops cycles.
+func DependencyTrees(projects []ResolvedProject) []ProjectTree {
+	trees := make([]ProjectTree, 0, len(projects))
+	for _, p := range projects {
+		index := packageIndex(p.Resolution)
+		expanded := make(map[string]bool)
+		var build func(ids []string) []TreeNode
+		build = func(ids []string) []TreeNode {
+			nodes := make([]TreeNode, 0, len(ids))
+			for _, id := range sortedPackages(ids, index) {
+				pkg := index[id]
+				node := TreeNode{Name: pkg.Name, Version: pkg.Version}
+				if len(pkg.Dependencies) > 0 && expanded[id] {
+					node.Deduped = true
+				} else if len(pkg.Dependencies) > 0 {
+					expanded[id] = true
+					node.Dependencies = build(pkg.Dependencies)
+				}
+				nodes = append(nodes, node)
+			}
+			return nodes
+		}
+
+		tree := ProjectTree{Project: p.Project, Dependencies: build(p.Resolution.Roots), Unreadable: p.Unreadable}
+		for _, id := range unlinkedPackages(p.Resolution, index) {
+			tree.Unlinked = append(tree.Unlinked, TreeNode{Name: index[id].Name, Version: index[id].Version})
+		}
+		trees = append(trees, tree)
+	}
+	return trees
+}
+
+// MaxPackagePaths bounds the paths to a package listed for a project, whose
+// number grows exponentially with the packages depending on each other.
+const MaxPackagePaths = 1000
+
+// PackageVersion is a package installed in a version.
+type PackageVersion struct {
+	Name    string
+	Version string
+}
+
+// PackagePaths are the paths from a project to the installed copies of a
+// package, from a direct dependency of the project to the package.
+type PackagePaths struct {
+	parser.Project
+	Paths [][]PackageVersion
+	// Unlinked are the copies installed for the dependencies of the project
+	// when the lockfile does not record which ones, as with pubspec.lock.
+	Unlinked  []PackageVersion `json:",omitempty"`
+	Truncated bool             `json:",omitempty"` // Paths beyond MaxPackagePaths are left out
+}
+
+// WhyPackage returns every path, without cycles, from each project to the
+// package named name, like npm why. Projects which do not install the
+// package are left out.
+func WhyPackage(projects []ResolvedProject, name string) []PackagePaths {
+	var result []PackagePaths
+	for _, p := range projects {
+		index := packageIndex(p.Resolution)
+
+		// Only the packages leading to a copy are walked through
+		leads := make(map[string]bool)
+		dependents := make(map[string][]string)
+		var pending []string
+		for _, pkg := range p.Resolution.Packages {
+			if pkg.Name == name {
+				leads[pkg.ID] = true
+				pending = append(pending, pkg.ID)
+			}
+			for _, dep := range pkg.Dependencies {
+				dependents[dep] = append(dependents[dep], pkg.ID)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+		for len(pending) > 0 {
+			id := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			for _, dependent := range dependents[id] {
+				if !leads[dependent] {
+					leads[dependent] = true
+					pending = append(pending, dependent)
+				}
+			}
+		}
+
+		paths := PackagePaths{Project: p.Project}
+		var path []string
+		onPath := make(map[string]bool)
+		var walk func(ids []string)
+		walk = func(ids []string) {
+			for _, id := range sortedPackages(ids, index) {
+				if !leads[id] || onPath[id] {
+					continue
+				}
+				if len(paths.Paths) == MaxPackagePaths {
+					paths.Truncated = true
+					return
+				}
+				path = append(path, id)
+				if index[id].Name == name {
+					steps := make([]PackageVersion, len(path))
+					for i, step := range path {
+						steps[i] = PackageVersion{Name: index[step].Name, Version: index[step].Version}
+					}
+					paths.Paths = append(paths.Paths, steps)
+				} else {
+					onPath[id] = true
+					walk(index[id].Dependencies)
+					onPath[id] = false
+				}
+				path = path[:len(path)-1]
+			}
+		}
+		walk(p.Resolution.Roots)
+
+		for _, id := range unlinkedPackages(p.Resolution, index) {
+			if index[id].Name == name {
+				paths.Unlinked = append(paths.Unlinked, PackageVersion{Name: name, Version: index[id].Version})
+			}
+		}
+		if len(paths.Paths) > 0 || len(paths.Unlinked) > 0 {
+			result = append(result, paths)
+		}
+	}
+	return result
+}
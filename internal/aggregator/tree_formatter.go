@@ -0,0 +1,239 @@
+package aggregator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+// packageLabel returns the name and version of a package, e.g. "qs 6.11.0".
+func packageLabel(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + " " + version
+}
+
+// treeRow is a package of a dependency tree, a row of the CSV output.
+type treeRow struct {
+	project *ProjectTree
+	depth   int // 0 for unlinked packages
+	parent  *TreeNode
+	node    TreeNode
+}
+
+// treeColumns are the columns of the CSV output, a row per package in the
+// order of the tree.
+var treeColumns = []column[treeRow]{
+	{"Project", func(r treeRow) string { return r.project.Label() }},
+	{"Root", func(r treeRow) string { return r.project.Root }},
+	{"Depth", func(r treeRow) string {
+		if r.depth == 0 {
+			return ""
+		}
+		return strconv.Itoa(r.depth)
+	}},
+	{"Name", func(r treeRow) string { return r.node.Name }},
+	{"Version", func(r treeRow) string { return r.node.Version }},
+	{"Parent", func(r treeRow) string {
+		if r.parent == nil {
+			return ""
+		}
+		return packageLabel(r.parent.Name, r.parent.Version)
+	}},
+	{"Deduped", func(r treeRow) string {
+		if r.node.Deduped {
+			return "yes"
+		}
+		return ""
+	}},
+}
+
+// JSONTreeRenderer implements TreeRenderer for JSON output.
+type JSONTreeRenderer struct{}
+
+type jsonTreeReport struct {
+	Projects    []ProjectTree
+	Diagnostics []diagnostic.Diagnostic
+	Incomplete  bool `json:",omitempty"`
+}
+
+func (r *JSONTreeRenderer) Render(trees []ProjectTree, diags []diagnostic.Diagnostic) ([]byte, error) {
+	report := newJSONReport(trees, diags)
+	return json.MarshalIndent(jsonTreeReport{Projects: report.Dependencies, Diagnostics: report.Diagnostics, Incomplete: report.Incomplete}, "", "  ")
+}
+
+// CSVTreeRenderer implements TreeRenderer for CSV output. Unlinked packages
+// have no depth.
+type CSVTreeRenderer struct{}
+
+func (r *CSVTreeRenderer) Render(trees []ProjectTree, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var rows []treeRow
+	var add func(project *ProjectTree, depth int, parent *TreeNode, nodes []TreeNode)
+	add = func(project *ProjectTree, depth int, parent *TreeNode, nodes []TreeNode) {
+		for i := range nodes {
+			rows = append(rows, treeRow{project: project, depth: depth, parent: parent, node: nodes[i]})
+			add(project, depth+1, &nodes[i], nodes[i].Dependencies)
+		}
+	}
+	for i := range trees {
+		add(&trees[i], 1, nil, trees[i].Dependencies)
+		for _, node := range trees[i].Unlinked {
+			rows = append(rows, treeRow{project: &trees[i], node: node})
+		}
+	}
+	return renderCSV(treeColumns, rows, diags)
+}
+
+// MarkdownTreeRenderer implements TreeRenderer for Markdown output, with a
+// nested list per project.
+type MarkdownTreeRenderer struct{}
+
+func (r *MarkdownTreeRenderer) Render(trees []ProjectTree, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if len(trees) == 0 {
+		if hasErrors(diags) {
+			buf.WriteString("No project could be resolved, see the diagnostics.\n")
+		} else {
+			buf.WriteString("No lockfile found.\n")
+		}
+	}
+	var write func(depth int, nodes []TreeNode)
+	write = func(depth int, nodes []TreeNode) {
+		for _, node := range nodes {
+			label := packageLabel(node.Name, node.Version)
+			if node.Deduped {
+				label += " (deduped)"
+			}
+			fmt.Fprintf(&buf, "%s- %s\n", strings.Repeat("  ", depth), label)
+			write(depth+1, node.Dependencies)
+		}
+	}
+	for i, tree := range trees {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		writeProjectHeading(&buf, tree.Project)
+		if tree.Unreadable {
+			fmt.Fprintf(&buf, "Could not read %s, see the diagnostics.\n", tree.Lockfile)
+			continue
+		}
+		fmt.Fprintf(&buf, "Resolved from %s:\n\n", tree.Lockfile)
+		if len(tree.Dependencies) == 0 {
+			buf.WriteString("No dependencies.\n")
+		}
+		write(0, tree.Dependencies)
+		if len(tree.Unlinked) > 0 {
+			fmt.Fprintf(&buf, "\nTransitive dependencies, whose dependents %s does not record:\n\n", filepath.Base(tree.Lockfile))
+			write(0, tree.Unlinked)
+		}
+	}
+	writeMarkdownDiagnostics(&buf, diags)
+
+	return buf.Bytes(), nil
+}
+
+// pathLabel returns the packages of a path separated by >, e.g.
+// "express 4.18.2 > qs 6.11.0".
+func pathLabel(path []PackageVersion) string {
+	labels := make([]string, len(path))
+	for i, step := range path {
+		labels[i] = packageLabel(step.Name, step.Version)
+	}
+	return strings.Join(labels, " > ")
+}
+
+// whyRow is a path to an installed copy of a package, or an unlinked copy
+// without path, a row of the CSV output.
+type whyRow struct {
+	project   *PackagePaths
+	path      []PackageVersion
+	installed PackageVersion
+}
+
+// whyColumns are the columns of the CSV output, a row per path.
+var whyColumns = []column[whyRow]{
+	{"Project", func(r whyRow) string { return r.project.Label() }},
+	{"Root", func(r whyRow) string { return r.project.Root }},
+	{"Lockfile", func(r whyRow) string { return r.project.Lockfile }},
+	{"Name", func(r whyRow) string { return r.installed.Name }},
+	{"Version", func(r whyRow) string { return r.installed.Version }},
+	{"Path", func(r whyRow) string { return pathLabel(r.path) }},
+}
+
+// JSONWhyRenderer implements WhyRenderer for JSON output.
+type JSONWhyRenderer struct {
+	Package string // Name of the package looked for
+}
+
+type jsonWhyReport struct {
+	Package     string
+	Projects    []PackagePaths
+	Diagnostics []diagnostic.Diagnostic
+	Incomplete  bool `json:",omitempty"`
+}
+
+func (r *JSONWhyRenderer) Render(paths []PackagePaths, diags []diagnostic.Diagnostic) ([]byte, error) {
+	report := newJSONReport(paths, diags)
+	return json.MarshalIndent(jsonWhyReport{Package: r.Package, Projects: report.Dependencies, Diagnostics: report.Diagnostics, Incomplete: report.Incomplete}, "", "  ")
+}
+
+// CSVWhyRenderer implements WhyRenderer for CSV output. Unlinked copies have
+// no path.
+type CSVWhyRenderer struct{}
+
+func (r *CSVWhyRenderer) Render(paths []PackagePaths, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var rows []whyRow
+	for i := range paths {
+		for _, path := range paths[i].Paths {
+			rows = append(rows, whyRow{project: &paths[i], path: path, installed: path[len(path)-1]})
+		}
+		for _, installed := range paths[i].Unlinked {
+			rows = append(rows, whyRow{project: &paths[i], installed: installed})
+		}
+	}
+	return renderCSV(whyColumns, rows, diags)
+}
+
+// MarkdownWhyRenderer implements WhyRenderer for Markdown output, with the
+// paths of each project.
+type MarkdownWhyRenderer struct {
+	Package string // Name of the package looked for
+}
+
+func (r *MarkdownWhyRenderer) Render(paths []PackagePaths, diags []diagnostic.Diagnostic) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if len(paths) == 0 {
+		if hasErrors(diags) {
+			fmt.Fprintf(&buf, "No lockfile read installs %s, see the diagnostics.\n", r.Package)
+		} else {
+			fmt.Fprintf(&buf, "No lockfile installs %s.\n", r.Package)
+		}
+	}
+	for i, p := range paths {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		writeProjectHeading(&buf, p.Project)
+		fmt.Fprintf(&buf, "Resolved from %s:\n\n", p.Lockfile)
+		for _, path := range p.Paths {
+			fmt.Fprintf(&buf, "- %s\n", pathLabel(path))
+		}
+		for _, installed := range p.Unlinked {
+			fmt.Fprintf(&buf, "- %s, whose dependents %s does not record\n", packageLabel(installed.Name, installed.Version), filepath.Base(p.Lockfile))
+		}
+		if p.Truncated {
+			fmt.Fprintf(&buf, "\nOnly the first %d paths are listed.\n", MaxPackagePaths)
+		}
+	}
+	writeMarkdownDiagnostics(&buf, diags)
+
+	return buf.Bytes(), nil
+}
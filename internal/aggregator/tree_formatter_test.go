@@ -0,0 +1,170 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+)
+
+func TestMarkdownTreeRenderer_Render(t *testing.T) {
+	out, err := (&MarkdownTreeRenderer{}).Render(DependencyTrees(sampleResolved), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `## web (node)
+
+Resolved from web/package-lock.json:
+
+- express 4.18.2
+  - body-parser 1.20.1
+    - express 4.18.2 (deduped)
+    - qs 6.11.0
+  - qs 6.11.0
+- jest 29.0.0
+  - body-parser 1.20.1 (deduped)
+  - qs 5.2.1
+
+## mobile (dart)
+
+Resolved from mobile/pubspec.lock:
+
+- http 1.1.0
+
+Transitive dependencies, whose dependents pubspec.lock does not record:
+
+- meta 1.9.1
+`
+	if string(out) != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", out, want)
+	}
+
+	if empty, _ := (&MarkdownTreeRenderer{}).Render(nil, nil); string(empty) != "No lockfile found.\n" {
+		t.Errorf("Render(nil) = %q", empty)
+	}
+
+	// The project of a manifest which cannot be parsed is left out
+	conflict := diagnostic.Errorf(diagnostic.CodeConflict, "api/package.json", "unresolved merge conflict")
+	empty, _ := (&MarkdownTreeRenderer{}).Render(nil, []diagnostic.Diagnostic{conflict})
+	if !strings.HasPrefix(string(empty), "No project could be resolved, see the diagnostics.\n") {
+		t.Errorf("Render(nil) with an error = %q", empty)
+	}
+}
+
+func TestMarkdownTreeRenderer_Unreadable(t *testing.T) {
+	broken := ResolvedProject{Project: parser.Project{Root: "api", Ecosystem: "node", Name: "api", Lockfile: "api/yarn.lock"}, Unreadable: true}
+	diag := diagnostic.Errorf(diagnostic.CodeParse, "api/yarn.lock", "unexpected end of input")
+
+	out, err := (&MarkdownTreeRenderer{}).Render(DependencyTrees([]ResolvedProject{broken}), []diagnostic.Diagnostic{diag})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(out), "No lockfile found.") {
+		t.Errorf("an unreadable lockfile was reported as missing:\n%s", out)
+	}
+	for _, want := range []string{
+		"## api (node)\n\nCould not read api/yarn.lock, see the diagnostics.\n",
+		"| error | parse-error | api/yarn.lock | unexpected end of input |",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestCSVTreeRenderer_Render(t *testing.T) {
+	out, err := (&CSVTreeRenderer{}).Render(DependencyTrees(sampleResolved[1:]), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Project,Root,Depth,Name,Version,Parent,Deduped\n" +
+		"mobile,mobile,1,http,1.1.0,,\n" +
+		"mobile,mobile,,meta,1.9.1,,\n"
+	if string(out) != want {
+		t.Errorf("Render() = %q, want %q", out, want)
+	}
+
+	out, _ = (&CSVTreeRenderer{}).Render(DependencyTrees(sampleResolved[:1]), nil)
+	if want := "web,web,2,body-parser,1.20.1,jest 29.0.0,yes\n"; !strings.Contains(string(out), want) {
+		t.Errorf("expected the deduped row %q in:\n%s", want, out)
+	}
+}
+
+func TestJSONTreeRenderer_Render(t *testing.T) {
+	out, err := (&JSONTreeRenderer{}).Render(DependencyTrees(sampleResolved), sampleDiagnostics())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report struct {
+		Projects []struct {
+			Lockfile     string
+			Dependencies []TreeNode
+			Unlinked     []TreeNode
+		}
+		Diagnostics []json.RawMessage
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	if len(report.Projects) != 2 || len(report.Diagnostics) != 1 {
+		t.Fatalf("expected 2 projects and 1 diagnostic, got:\n%s", out)
+	}
+	if web := report.Projects[0]; web.Lockfile != "web/package-lock.json" || len(web.Dependencies[0].Dependencies) != 2 {
+		t.Errorf("unexpected web tree: %+v", web)
+	}
+	if mobile := report.Projects[1]; len(mobile.Unlinked) != 1 {
+		t.Errorf("unexpected mobile tree: %+v", mobile)
+	}
+}
+
+func TestMarkdownWhyRenderer_Render(t *testing.T) {
+	renderer := &MarkdownWhyRenderer{Package: "meta"}
+	out, err := renderer.Render(WhyPackage(sampleResolved, "meta"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `## mobile (dart)
+
+Resolved from mobile/pubspec.lock:
+
+- meta 1.9.1, whose dependents pubspec.lock does not record
+`
+	if string(out) != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", out, want)
+	}
+
+	if empty, _ := renderer.Render(nil, nil); string(empty) != "No lockfile installs meta.\n" {
+		t.Errorf("Render(nil) = %q", empty)
+	}
+}
+
+func TestCSVWhyRenderer_Render(t *testing.T) {
+	paths := append(WhyPackage(sampleResolved, "qs")[:1:1], WhyPackage(sampleResolved, "meta")...)
+	paths[0].Paths = paths[0].Paths[4:]
+	out, err := (&CSVWhyRenderer{}).Render(paths, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Project,Root,Lockfile,Name,Version,Path\n" +
+		"web,web,web/package-lock.json,qs,5.2.1,jest 29.0.0 > qs 5.2.1\n" +
+		"mobile,mobile,mobile/pubspec.lock,meta,1.9.1,\n"
+	if string(out) != want {
+		t.Errorf("Render() = %q, want %q", out, want)
+	}
+}
+
+func TestJSONWhyRenderer_Render(t *testing.T) {
+	out, err := (&JSONWhyRenderer{Package: "qs"}).Render(nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report map[string]json.RawMessage
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	if string(report["Package"]) != `"qs"` || string(report["Projects"]) != "[]" {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+}
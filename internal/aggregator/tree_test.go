@@ -0,0 +1,175 @@
+package aggregator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+// resolvedPackage returns a package whose ID is name@version.
+func resolvedPackage(name, version string, deps ...string) ecosystem.ResolvedPackage {
+	return ecosystem.ResolvedPackage{ID: name + "@" + version, Name: name, Version: version, Dependencies: deps}
+}
+
+var sampleResolved = []ResolvedProject{
+	{
+		Project: parser.Project{Root: "web", Ecosystem: "node", Name: "web", Lockfile: "web/package-lock.json"},
+		Resolution: ecosystem.Resolution{
+			Roots: []string{"jest@29.0.0", "express@4.18.2"},
+			Packages: []ecosystem.ResolvedPackage{
+				resolvedPackage("express", "4.18.2", "body-parser@1.20.1", "qs@6.11.0"),
+				resolvedPackage("body-parser", "1.20.1", "qs@6.11.0", "express@4.18.2"), // cycle
+				resolvedPackage("qs", "6.11.0"),
+				resolvedPackage("jest", "29.0.0", "body-parser@1.20.1", "qs@5.2.1"),
+				resolvedPackage("qs", "5.2.1"),
+			},
+		},
+	},
+	{
+		Project: parser.Project{Root: "mobile", Ecosystem: "dart", Name: "mobile", Lockfile: "mobile/pubspec.lock"},
+		Resolution: ecosystem.Resolution{
+			Roots:    []string{"http@1.1.0"},
+			Packages: []ecosystem.ResolvedPackage{resolvedPackage("http", "1.1.0"), resolvedPackage("meta", "1.9.1")},
+			Unlinked: true,
+		},
+	},
+}
+
+func TestResolveProjects(t *testing.T) {
+	projects := []ProjectDependencies{
+		{Project: parser.Project{Root: "api", Ecosystem: "python"}},
+		{
+			Project:      parser.Project{Root: "web", Ecosystem: "node", Lockfile: "web/yarn.lock"},
+			Dependencies: []FlatDependency{{Name: "lodash", Version: "^4.17.0", Packaging: "node"}},
+		},
+		{Project: parser.Project{Root: "broken", Ecosystem: "dart", Lockfile: "broken/pubspec.lock"}},
+	}
+	files := map[string]string{
+		"web/yarn.lock":       "lodash@^4.17.0:\n  version \"4.17.21\"\n",
+		"broken/pubspec.lock": "packages: [",
+	}
+	read := func(path string) ([]byte, error) { return []byte(files[path]), nil }
+	diags := &diagnostic.Collector{}
+
+	resolved := ResolveProjects(context.Background(), projects, parser.Options{Read: read}, diags)
+
+	if len(resolved) != 2 || resolved[0].Root != "web" || resolved[0].Unreadable {
+		t.Fatalf("expected the web and broken projects, got %+v", resolved)
+	}
+	if broken := resolved[1]; broken.Root != "broken" || !broken.Unreadable || broken.Resolution.Packages != nil {
+		t.Errorf("expected an unreadable broken project, got %+v", broken)
+	}
+	if roots := resolved[0].Resolution.Roots; !reflect.DeepEqual(roots, []string{"lodash@4.17.21"}) {
+		t.Errorf("roots = %v, want the declared lodash", roots)
+	}
+	got := diags.Diagnostics()
+	if len(got) != 1 || got[0].Code != diagnostic.CodeParse || got[0].Path != "broken/pubspec.lock" {
+		t.Errorf("expected a parse error for broken/pubspec.lock, got %+v", got)
+	}
+}
+
+func TestResolveProjects_Cancelled(t *testing.T) {
+	projects := []ProjectDependencies{
+		{Project: parser.Project{Root: "web", Ecosystem: "node", Lockfile: "web/yarn.lock"}},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	read := func(path string) ([]byte, error) {
+		t.Errorf("lockfile %s read after the cancellation", path)
+		return nil, ctx.Err()
+	}
+	diags := &diagnostic.Collector{}
+
+	if resolved := ResolveProjects(ctx, projects, parser.Options{Read: read}, diags); len(resolved) != 0 {
+		t.Errorf("expected no project resolved, got %+v", resolved)
+	}
+	if got := diags.Diagnostics(); !diagnostic.Incomplete(got) || len(got) != 1 {
+		t.Errorf("expected an incomplete error only, got %+v", got)
+	}
+}
+
+func TestDependencyTrees(t *testing.T) {
+	trees := DependencyTrees(sampleResolved)
+
+	want := []TreeNode{
+		{Name: "express", Version: "4.18.2", Dependencies: []TreeNode{
+			{Name: "body-parser", Version: "1.20.1", Dependencies: []TreeNode{
+				{Name: "express", Version: "4.18.2", Deduped: true},
+				{Name: "qs", Version: "6.11.0"},
+			}},
+			{Name: "qs", Version: "6.11.0"},
+		}},
+		{Name: "jest", Version: "29.0.0", Dependencies: []TreeNode{
+			{Name: "body-parser", Version: "1.20.1", Deduped: true},
+			{Name: "qs", Version: "5.2.1"},
+		}},
+	}
+	if !reflect.DeepEqual(trees[0].Dependencies, want) {
+		t.Errorf("web tree = %+v, want %+v", trees[0].Dependencies, want)
+	}
+	if trees[0].Unlinked != nil {
+		t.Errorf("web Unlinked = %+v, want none", trees[0].Unlinked)
+	}
+
+	mobile := trees[1]
+	if !reflect.DeepEqual(mobile.Dependencies, []TreeNode{{Name: "http", Version: "1.1.0"}}) ||
+		!reflect.DeepEqual(mobile.Unlinked, []TreeNode{{Name: "meta", Version: "1.9.1"}}) {
+		t.Errorf("mobile tree = %+v", mobile)
+	}
+}
+
+func TestWhyPackage(t *testing.T) {
+	pathLabels := func(paths [][]PackageVersion) []string {
+		var labels []string
+		for _, path := range paths {
+			labels = append(labels, pathLabel(path))
+		}
+		return labels
+	}
+
+	got := WhyPackage(sampleResolved, "qs")
+	if len(got) != 1 || got[0].Root != "web" {
+		t.Fatalf("expected paths in web only, got %+v", got)
+	}
+	want := []string{
+		"express 4.18.2 > body-parser 1.20.1 > qs 6.11.0",
+		"express 4.18.2 > qs 6.11.0",
+		"jest 29.0.0 > body-parser 1.20.1 > express 4.18.2 > qs 6.11.0",
+		"jest 29.0.0 > body-parser 1.20.1 > qs 6.11.0",
+		"jest 29.0.0 > qs 5.2.1",
+	}
+	if labels := pathLabels(got[0].Paths); !reflect.DeepEqual(labels, want) {
+		t.Errorf("paths =\n%s\nwant\n%s", strings.Join(labels, "\n"), strings.Join(want, "\n"))
+	}
+
+	meta := WhyPackage(sampleResolved, "meta")
+	if len(meta) != 1 || meta[0].Paths != nil || !reflect.DeepEqual(meta[0].Unlinked, []PackageVersion{{Name: "meta", Version: "1.9.1"}}) {
+		t.Errorf("meta = %+v, want an unlinked copy in mobile", meta)
+	}
+	if missing := WhyPackage(sampleResolved, "lodash"); len(missing) != 0 {
+		t.Errorf("lodash = %+v, want no project", missing)
+	}
+}
+
+func TestWhyPackage_Truncated(t *testing.T) {
+	// Each layer doubles the paths to the package
+	var packages []ecosystem.ResolvedPackage
+	next := []string{"target@1.0.0"}
+	for layer := 0; layer < 11; layer++ {
+		a, b := resolvedPackage("a", string(rune('a'+layer)), next...), resolvedPackage("b", string(rune('a'+layer)), next...)
+		packages = append(packages, a, b)
+		next = []string{a.ID, b.ID}
+	}
+	packages = append(packages, resolvedPackage("target", "1.0.0"))
+	project := ResolvedProject{Resolution: ecosystem.Resolution{Roots: next, Packages: packages}}
+
+	got := WhyPackage([]ResolvedProject{project}, "target")
+	if len(got) != 1 || len(got[0].Paths) != MaxPackagePaths || !got[0].Truncated {
+		t.Errorf("expected %d paths and a truncated result, got %d paths, truncated %v", MaxPackagePaths, len(got[0].Paths), got[0].Truncated)
+	}
+}
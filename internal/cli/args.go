@@ -4,7 +4,13 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/flarebyte/clingy-code-detective/internal/aggregator"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
 )
 
 // Version should be set at build time using -ldflags "-X 'cli.Version=1.2.3'"
@@ -21,6 +27,10 @@ Copyright (c) 2025 Flarebyte.com - MIT License
 
 const helpUsage = `
 Usage: clingy [options] <paths>
+       clingy --files-from <file|-> [options] [paths]
+       clingy --git-rev <ref> [options] [paths]
+
+A path of - reads the paths to scan from stdin, one per line.
 
 Options:
   --include    Comma-separated ecosystems to include (e.g. node,dart)
@@ -28,20 +38,91 @@ Options:
   --json       Output in JSON format
   --csv        Output in CSV format
   --md         Output in Markdown format
+  --format     Output format: json, csv, md, or a graph of the projects and their dependencies
+               with dot (Graphviz), mermaid or graphml
+  --hide-external       Only draw the dependencies between the projects of the scan in a graph
+  --collapse-ecosystems Draw the external packages of each ecosystem as one node in a graph
   --aggregate  Aggregate results across all directories
+  --by-project Report the dependencies of each project, identified by the directory and ecosystem of its manifests
+  --internal   List the dependencies on projects of the scan, versions excluding the local package first
+  --tree       Print the dependency tree of each project resolved by its lockfile, like npm ls
+  --why        Print every path from each project to a package installed by its lockfile, e.g. --why=lodash
+  --duplicates Print the packages installed in several versions by the lockfile of each project,
+               with the dependents pulling each version
+  --drift      List the dependencies declared with several versions, from the widest spread
+  --drift-target  Version suggested by --drift: common (most declared) or max (default: common)
+  --group-by   Comma-separated keys grouping --aggregate and --drift: name, packaging, category,
+               directory (or workspace), path:N or path:PATTERN, e.g. path:apps/* (default: name,category,packaging)
+  --files-from Read manifest files to parse from a file, or stdin with -
+  --config     YAML configuration file declaring parser plugins and declarative parsers
+  --sniff      Also sniff the content of generic .json, .yaml, .txt, .in and .mod files
+  --archives   Also look inside archives (.tar.gz, .zip, .jar, .whl) found while walking
+  --max-depth      Maximum directory depth below each path (default: no limit)
+  --max-file-size  Skip manifests larger than this size, e.g. 512K or 2M (default: no limit)
+  --max-files      Stop after this number of manifests (default: no limit)
+  --git-rev    Scan the tree of a git revision instead of the working copy
+  --git-dir    Local git repository used with --git-rev (default: .)
+  --strict     Also exit with a non-zero code when a warning is reported, not only an error
+  --tolerant   Recover the dependencies of malformed manifests (comments, trailing commas, merge conflicts)
+  --jobs       Number of manifests parsed at once (default: number of CPUs)
+  --walk-jobs  Number of paths walked at once (default: number of CPUs)
+  --stream     Print dependencies as they are found, unsorted, instead of holding them in memory
+  --timeout    Stop the scan after this duration and print a partial report, e.g. 30s or 5m (default: no limit)
   --version    Show version information
   --help       Show this help message
 `
 
+// helpEcosystems lists the registered ecosystems and their aliases.
+func helpEcosystems() string {
+	var b strings.Builder
+	b.WriteString("\nEcosystems:\n")
+	for _, e := range ecosystem.All() {
+		b.WriteString("  " + e.Name)
+		if len(e.Aliases) > 0 {
+			b.WriteString(" (" + strings.Join(e.Aliases, ", ") + ")")
+		}
+		b.WriteString(": " + strings.Join(e.Patterns, ", ") + "\n")
+	}
+	return b.String()
+}
+
+// GraphFormats are the formats drawing the dependency graph of the projects.
+var GraphFormats = []string{"dot", "mermaid", "graphml"}
+
 // Config holds the parsed CLI arguments.
 type Config struct {
-	Paths     []string
-	Format    string
-	Aggregate bool
-	Includes  []string
-	Excludes  []string
-	ShowHelp  bool
-	ShowVer   bool
+	Paths       []string
+	Format      string // json, csv, md, or one of the GraphFormats
+	Aggregate   bool
+	ByProject   bool   // Report the dependencies of each project
+	Internal    bool   // Report the dependencies on projects of the scan
+	Tree        bool   // Report the dependency tree resolved by each lockfile
+	Why         string // Package whose paths from each project are reported
+	Duplicates  bool   // Report the packages installed in several versions by each lockfile
+	Drift       bool   // Report the dependencies declared with several versions
+	DriftTarget string // Version suggested by the drift report, common or max
+	GroupBy     []aggregator.GroupKey
+	Graph       aggregator.GraphOptions // Nodes drawn by the graph formats
+	Archives    bool
+	Sniff       bool
+	ConfigPath  string
+	Includes    []string
+	Excludes    []string
+	FilesFrom   string
+	Files       []string // Explicit manifest files, loaded by ResolveInputs
+	GitRev      string
+	GitDir      string
+	MaxDepth    int
+	MaxFileSize int64
+	MaxFiles    int
+	Strict      bool          // Warnings set the exit code, like errors
+	Tolerant    bool          // Recover dependencies from malformed manifests
+	Timeout     time.Duration // Maximum duration of the scan, 0 for no limit
+	Jobs        int           // Parse workers, the number of CPUs when 0
+	WalkJobs    int           // Paths walked at once, the number of CPUs when 0
+	Stream      bool          // Print flat results as they are found
+	ShowHelp    bool
+	ShowVer     bool
 }
 
 type parseIncludes []string
@@ -56,11 +137,15 @@ func (i *parseExcludes) Set(value string) error { *i = strings.Split(value, ",")
 func ParseArgsFrom(args []string) (*Config, error) {
 	var includes parseIncludes
 	var excludes parseExcludes
-	var jsonOut, csvOut, mdOut, aggregate, showHelp, showVer bool
+	var jsonOut, csvOut, mdOut, aggregate, byProject, internal, drift, archives, sniff, strict, tolerant, stream, showHelp, showVer bool
+	var hideExternal, collapseEcosystems, tree, duplicates bool
+	var gitRev, gitDir, filesFrom, maxFileSize, configPath, driftTarget, groupBy, formatName, why string
+	var maxDepth, maxFiles, jobs, walkJobs int
+	var timeout time.Duration
 
 	fs := flag.NewFlagSet("clingy", flag.ContinueOnError)
 	fs.Usage = func() {
-		fmt.Printf("%sVersion: %s, Date: %s \n%s", helpIntro, Version, niceDate, helpUsage)
+		fmt.Printf("%sVersion: %s, Date: %s \n%s%s", helpIntro, Version, niceDate, helpUsage, helpEcosystems())
 	}
 
 	fs.Var(&includes, "include", "Ecosystems to include")
@@ -68,7 +153,33 @@ func ParseArgsFrom(args []string) (*Config, error) {
 	fs.BoolVar(&jsonOut, "json", false, "Output JSON")
 	fs.BoolVar(&csvOut, "csv", false, "Output CSV")
 	fs.BoolVar(&mdOut, "md", false, "Output Markdown")
+	fs.StringVar(&formatName, "format", "", "Output format")
+	fs.BoolVar(&hideExternal, "hide-external", false, "Hide external packages from graphs")
+	fs.BoolVar(&collapseEcosystems, "collapse-ecosystems", false, "Collapse the external packages of graphs by ecosystem")
 	fs.BoolVar(&aggregate, "aggregate", false, "Aggregate results")
+	fs.BoolVar(&byProject, "by-project", false, "Report dependencies per project")
+	fs.BoolVar(&internal, "internal", false, "Report dependencies on projects of the scan")
+	fs.BoolVar(&tree, "tree", false, "Report the dependency tree of each lockfile")
+	fs.StringVar(&why, "why", "", "Report the paths to a package")
+	fs.BoolVar(&duplicates, "duplicates", false, "Report the packages installed in several versions")
+	fs.BoolVar(&drift, "drift", false, "Report version drift")
+	fs.StringVar(&driftTarget, "drift-target", "common", "Version suggested by --drift")
+	fs.StringVar(&groupBy, "group-by", "", "Keys grouping aggregated dependencies")
+	fs.StringVar(&filesFrom, "files-from", "", "File listing manifests to parse")
+	fs.StringVar(&configPath, "config", "", "Configuration file")
+	fs.BoolVar(&sniff, "sniff", false, "Sniff content of generic files")
+	fs.BoolVar(&archives, "archives", false, "Look inside archives found while walking")
+	fs.IntVar(&maxDepth, "max-depth", 0, "Maximum directory depth")
+	fs.StringVar(&maxFileSize, "max-file-size", "", "Maximum manifest size")
+	fs.IntVar(&maxFiles, "max-files", 0, "Maximum number of manifests")
+	fs.StringVar(&gitRev, "git-rev", "", "Git revision to scan")
+	fs.StringVar(&gitDir, "git-dir", "", "Git repository directory")
+	fs.BoolVar(&strict, "strict", false, "Fail on warnings too")
+	fs.BoolVar(&tolerant, "tolerant", false, "Recover dependencies from malformed manifests")
+	fs.DurationVar(&timeout, "timeout", 0, "Maximum duration of the scan")
+	fs.IntVar(&jobs, "jobs", 0, "Number of parse workers")
+	fs.IntVar(&walkJobs, "walk-jobs", 0, "Number of paths walked at once")
+	fs.BoolVar(&stream, "stream", false, "Print dependencies as they are found")
 	fs.BoolVar(&showVer, "version", false, "Show version")
 	fs.BoolVar(&showHelp, "help", false, "Show help")
 
@@ -86,33 +197,150 @@ func ParseArgsFrom(args []string) (*Config, error) {
 		return &Config{ShowVer: true}, nil
 	}
 
+	if maxDepth < 0 || maxFiles < 0 {
+		return nil, fmt.Errorf("--max-depth and --max-files must not be negative")
+	}
+	if timeout < 0 {
+		return nil, fmt.Errorf("--timeout must not be negative")
+	}
+	if jobs < 0 || walkJobs < 0 {
+		return nil, fmt.Errorf("--jobs and --walk-jobs must not be negative")
+	}
+	if stream && (aggregate || drift) {
+		return nil, fmt.Errorf("--stream cannot be used with --aggregate or --drift")
+	}
+	if stream && (byProject || internal || tree || why != "" || duplicates) {
+		return nil, fmt.Errorf("--stream cannot be used with --by-project, --internal, --tree, --why or --duplicates")
+	}
+	modes := 0
+	for _, mode := range []bool{aggregate, drift, byProject, internal, tree, why != "", duplicates} {
+		if mode {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return nil, fmt.Errorf("only one of --aggregate, --drift, --by-project, --internal, --tree, --why, --duplicates may be used")
+	}
+	if driftTarget != "common" && driftTarget != "max" {
+		return nil, fmt.Errorf("invalid --drift-target %q: must be common or max", driftTarget)
+	}
+	var groupKeys []aggregator.GroupKey
+	if groupBy != "" {
+		if !aggregate && !drift {
+			return nil, fmt.Errorf("--group-by requires --aggregate or --drift")
+		}
+		keys, err := aggregator.ParseGroupBy(groupBy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --group-by: %w", err)
+		}
+		groupKeys = keys
+	}
+	maxFileSizeBytes, err := parseSize(maxFileSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --max-file-size: %w", err)
+	}
+
+	if gitDir != "" && gitRev == "" {
+		return nil, fmt.Errorf("--git-dir requires --git-rev")
+	}
+
 	paths := fs.Args()
-	if len(paths) == 0 {
+	if len(paths) == 0 && gitRev != "" && filesFrom == "" {
+		// Scan the whole revision tree by default
+		paths = []string{"."}
+	}
+	if len(paths) == 0 && filesFrom == "" {
 		fs.Usage()
 		return &Config{ShowHelp: true}, nil
 	}
+	if gitRev != "" && gitDir == "" {
+		gitDir = "."
+	}
 
 	var format string
-	switch {
-	case (jsonOut && csvOut) || (jsonOut && mdOut) || (csvOut && mdOut):
-		return nil, fmt.Errorf("only one of --json, --csv, --md may be used")
-	case jsonOut:
-		format = "json"
-	case csvOut:
-		format = "csv"
-	case mdOut:
-		format = "md"
+	formats := 0
+	for _, f := range []struct {
+		set  bool
+		name string
+	}{{jsonOut, "json"}, {csvOut, "csv"}, {mdOut, "md"}, {formatName != "", formatName}} {
+		if f.set {
+			format = f.name
+			formats++
+		}
+	}
+	if formats > 1 {
+		return nil, fmt.Errorf("only one of --json, --csv, --md, --format may be used")
+	}
+	graph := slices.Contains(GraphFormats, format)
+	if format != "" && format != "json" && format != "csv" && format != "md" && !graph {
+		return nil, fmt.Errorf("invalid --format %q: must be json, csv, md, dot, mermaid or graphml", format)
+	}
+	if graph && (aggregate || drift || internal || tree || why != "" || duplicates || stream) {
+		return nil, fmt.Errorf("--format=%s cannot be used with --aggregate, --drift, --internal, --tree, --why, --duplicates or --stream", format)
+	}
+	if (hideExternal || collapseEcosystems) && !graph {
+		return nil, fmt.Errorf("--hide-external and --collapse-ecosystems require --format=dot, mermaid or graphml")
 	}
 
 	return &Config{
-		Paths:     paths,
-		Format:    format,
-		Aggregate: aggregate,
-		Includes:  includes,
-		Excludes:  excludes,
+		Paths:       paths,
+		Format:      format,
+		Aggregate:   aggregate,
+		ByProject:   byProject,
+		Internal:    internal,
+		Tree:        tree,
+		Why:         why,
+		Duplicates:  duplicates,
+		Drift:       drift,
+		DriftTarget: driftTarget,
+		GroupBy:     groupKeys,
+		Graph:       aggregator.GraphOptions{HideExternal: hideExternal, CollapseEcosystems: collapseEcosystems},
+		Archives:    archives,
+		Sniff:       sniff,
+		ConfigPath:  configPath,
+		Includes:    includes,
+		Excludes:    excludes,
+		FilesFrom:   filesFrom,
+		GitRev:      gitRev,
+		GitDir:      gitDir,
+		MaxDepth:    maxDepth,
+		MaxFileSize: maxFileSizeBytes,
+		MaxFiles:    maxFiles,
+		Strict:      strict,
+		Tolerant:    tolerant,
+		Timeout:     timeout,
+		Jobs:        jobs,
+		WalkJobs:    walkJobs,
+		Stream:      stream,
 	}, nil
 }
 
+// parseSize parses a size in bytes with an optional K, M or G binary suffix.
+// An empty string means no limit.
+func parseSize(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	multiplier := int64(1)
+	number := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(value), "B"), "I")
+	switch {
+	case strings.HasSuffix(number, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(number, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(number, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		number = number[:len(number)-1]
+	}
+	size, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("%q is not a size", value)
+	}
+	return size * multiplier, nil
+}
+
 // ParseArgs uses os.Args and defaults to help on empty args.
 func ParseArgs() (*Config, error) {
 	args := os.Args[1:]
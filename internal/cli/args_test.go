@@ -6,6 +6,9 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/flarebyte/clingy-code-detective/internal/aggregator"
 )
 
 // helper to temporarily override os.Args and reset flag.CommandLine
@@ -42,6 +45,10 @@ func TestParseArgs_Basic(t *testing.T) {
 		t.Errorf("Aggregate = true, want false by default")
 	}
 
+	if cfg.Strict {
+		t.Errorf("Strict = true, want false by default")
+	}
+
 	wantIncludes := []string{"node", "dart"}
 	if !reflect.DeepEqual(cfg.Includes, wantIncludes) {
 		t.Errorf("Includes = %v, want %v", cfg.Includes, wantIncludes)
@@ -65,7 +72,7 @@ func TestParseArgs_MutuallyExclusiveFormats(t *testing.T) {
 	}
 
 	_, err := ParseArgsFrom(args)
-	if err == nil || !strings.Contains(err.Error(), "only one of --json, --csv, --md") {
+	if err == nil || !strings.Contains(err.Error(), "only one of --json, --csv, --md, --format") {
 		t.Errorf("expected mutually exclusive error, got: %v", err)
 	}
 }
@@ -101,3 +108,258 @@ func TestParseArgs_VersionFlag(t *testing.T) {
 		t.Error("expected ShowVer to be true")
 	}
 }
+
+func TestParseArgs_GitRevDefaultsToWholeTree(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--git-rev=v1.2.0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GitRev != "v1.2.0" || cfg.GitDir != "." {
+		t.Errorf("GitRev = %q, GitDir = %q, want v1.2.0 and .", cfg.GitRev, cfg.GitDir)
+	}
+	if !reflect.DeepEqual(cfg.Paths, []string{"."}) {
+		t.Errorf("Paths = %v, want [.]", cfg.Paths)
+	}
+}
+
+func TestParseArgs_GitDirRequiresGitRev(t *testing.T) {
+	_, err := ParseArgsFrom([]string{"--git-dir=repo", "dir"})
+	if err == nil || !strings.Contains(err.Error(), "--git-dir requires --git-rev") {
+		t.Errorf("expected --git-dir error, got: %v", err)
+	}
+}
+
+func TestParseArgs_FilesFromWithoutPaths(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--json", "--files-from=-"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ShowHelp {
+		t.Error("expected --files-from to be enough without paths")
+	}
+	if cfg.FilesFrom != "-" || len(cfg.Paths) != 0 {
+		t.Errorf("FilesFrom = %q, Paths = %v, want - and no paths", cfg.FilesFrom, cfg.Paths)
+	}
+}
+
+func TestParseArgs_Limits(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--max-depth=3", "--max-file-size=2M", "--max-files=100", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxDepth != 3 || cfg.MaxFileSize != 2<<20 || cfg.MaxFiles != 100 {
+		t.Errorf("limits = %d, %d, %d, want 3, %d, 100", cfg.MaxDepth, cfg.MaxFileSize, cfg.MaxFiles, 2<<20)
+	}
+
+	if _, err := ParseArgsFrom([]string{"--max-file-size=lots", "dir"}); err == nil {
+		t.Error("expected error for invalid --max-file-size")
+	}
+}
+
+func TestParseArgs_Strict(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--strict", "--json", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Strict {
+		t.Errorf("Strict = false, want true")
+	}
+}
+
+func TestParseArgs_Tolerant(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--tolerant", "--json", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Tolerant {
+		t.Errorf("Tolerant = false, want true")
+	}
+}
+
+func TestParseArgs_Timeout(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--timeout=1m30s", "--json", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Timeout != 90*time.Second {
+		t.Errorf("Timeout = %s, want 1m30s", cfg.Timeout)
+	}
+	for _, invalid := range []string{"--timeout=soon", "--timeout=-1s"} {
+		if _, err := ParseArgsFrom([]string{invalid, "dir"}); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
+
+func TestParseArgs_Concurrency(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--jobs=8", "--walk-jobs=2", "--stream", "--csv", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Jobs != 8 || cfg.WalkJobs != 2 || !cfg.Stream {
+		t.Errorf("Jobs = %d, WalkJobs = %d, Stream = %v, want 8, 2, true", cfg.Jobs, cfg.WalkJobs, cfg.Stream)
+	}
+	for _, invalid := range [][]string{
+		{"--jobs=-1", "dir"},
+		{"--walk-jobs=-1", "dir"},
+		{"--stream", "--aggregate", "dir"},
+	} {
+		if _, err := ParseArgsFrom(invalid); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
+
+func TestParseArgs_Drift(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--drift", "--md", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Drift || cfg.DriftTarget != "common" {
+		t.Errorf("Drift = %v, DriftTarget = %q, want true, common", cfg.Drift, cfg.DriftTarget)
+	}
+	cfg, err = ParseArgsFrom([]string{"--drift", "--drift-target=max", "--md", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DriftTarget != "max" {
+		t.Errorf("DriftTarget = %q, want max", cfg.DriftTarget)
+	}
+	for _, invalid := range [][]string{
+		{"--drift", "--aggregate", "dir"},
+		{"--drift", "--stream", "dir"},
+		{"--drift", "--drift-target=latest", "dir"},
+	} {
+		if _, err := ParseArgsFrom(invalid); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
+
+func TestParseArgs_ByProject(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--by-project", "--md", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.ByProject {
+		t.Error("ByProject = false, want true")
+	}
+	if cfg, err = ParseArgsFrom([]string{"--internal", "dir"}); err != nil || !cfg.Internal {
+		t.Errorf("--internal: got %+v, %v", cfg, err)
+	}
+	for _, invalid := range [][]string{
+		{"--by-project", "--aggregate", "dir"},
+		{"--by-project", "--drift", "dir"},
+		{"--by-project", "--stream", "dir"},
+		{"--by-project", "--internal", "dir"},
+		{"--internal", "--aggregate", "dir"},
+		{"--internal", "--stream", "dir"},
+	} {
+		if _, err := ParseArgsFrom(invalid); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
+
+func TestParseArgs_TreeAndWhy(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--tree", "--md", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Tree || cfg.Why != "" {
+		t.Errorf("Tree = %v, Why = %q, want tree only", cfg.Tree, cfg.Why)
+	}
+	if cfg, err = ParseArgsFrom([]string{"--why=lodash", "dir"}); err != nil || cfg.Why != "lodash" {
+		t.Errorf("--why=lodash: got %+v, %v", cfg, err)
+	}
+	for _, invalid := range [][]string{
+		{"--tree", "--why=lodash", "dir"},
+		{"--tree", "--by-project", "dir"},
+		{"--why=lodash", "--aggregate", "dir"},
+		{"--tree", "--stream", "dir"},
+		{"--why=lodash", "--format=dot", "dir"},
+	} {
+		if _, err := ParseArgsFrom(invalid); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
+
+func TestParseArgs_Duplicates(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--duplicates", "--csv", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Duplicates || cfg.Format != "csv" {
+		t.Errorf("Duplicates = %v, Format = %q, want a CSV duplicates report", cfg.Duplicates, cfg.Format)
+	}
+	for _, invalid := range [][]string{
+		{"--duplicates", "--tree", "dir"},
+		{"--duplicates", "--stream", "dir"},
+		{"--duplicates", "--format=mermaid", "dir"},
+	} {
+		if _, err := ParseArgsFrom(invalid); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
+
+func TestParseArgs_Format(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--format=mermaid", "--hide-external", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Format != "mermaid" || !cfg.Graph.HideExternal || cfg.Graph.CollapseEcosystems {
+		t.Errorf("Format = %q, Graph = %+v, want mermaid hiding external packages", cfg.Format, cfg.Graph)
+	}
+	if cfg, err = ParseArgsFrom([]string{"--format=csv", "dir"}); err != nil || cfg.Format != "csv" {
+		t.Errorf("--format=csv: got %+v, %v", cfg, err)
+	}
+	for _, invalid := range [][]string{
+		{"--format=svg", "dir"},
+		{"--format=dot", "--json", "dir"},
+		{"--format=dot", "--aggregate", "dir"},
+		{"--format=graphml", "--stream", "dir"},
+		{"--collapse-ecosystems", "--md", "dir"},
+	} {
+		if _, err := ParseArgsFrom(invalid); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
+
+func TestParseArgs_GroupBy(t *testing.T) {
+	cfg, err := ParseArgsFrom([]string{"--aggregate", "--group-by=name,path:apps/*", "--csv", "dir"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []aggregator.GroupKey{{Field: aggregator.GroupByName}, {Field: aggregator.GroupByPath, Pattern: "apps/*"}}
+	if !reflect.DeepEqual(cfg.GroupBy, want) {
+		t.Errorf("GroupBy = %+v, want %+v", cfg.GroupBy, want)
+	}
+	for _, invalid := range [][]string{
+		{"--group-by=name", "dir"},
+		{"--aggregate", "--group-by=version", "dir"},
+	} {
+		if _, err := ParseArgsFrom(invalid); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"":     0,
+		"512":  512,
+		"512K": 512 << 10,
+		"1MiB": 1 << 20,
+		"2mb":  2 << 20,
+		"1G":   1 << 30,
+	}
+	for value, want := range tests {
+		got, err := parseSize(value)
+		if err != nil || got != want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+}
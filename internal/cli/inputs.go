@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// StdinPath is the path standing for the standard input, as a root or as the
+// value of --files-from.
+const StdinPath = "-"
+
+// ResolveInputs replaces the "-" root by the paths read from stdin and loads
+// the explicit manifest files listed by --files-from.
+func (c *Config) ResolveInputs(stdin io.Reader) error {
+	rootsFromStdin := false
+	paths := make([]string, 0, len(c.Paths))
+	for _, p := range c.Paths {
+		if p != StdinPath {
+			paths = append(paths, p)
+			continue
+		}
+		if rootsFromStdin {
+			continue
+		}
+		rootsFromStdin = true
+		roots, err := readList(stdin)
+		if err != nil {
+			return fmt.Errorf("reading paths from stdin: %w", err)
+		}
+		paths = append(paths, roots...)
+	}
+	c.Paths = paths
+
+	switch c.FilesFrom {
+	case "":
+		return nil
+	case StdinPath:
+		if rootsFromStdin {
+			return errors.New("stdin cannot be used both as a root and by --files-from")
+		}
+		files, err := readList(stdin)
+		if err != nil {
+			return fmt.Errorf("reading files from stdin: %w", err)
+		}
+		c.Files = files
+	default:
+		f, err := os.Open(c.FilesFrom)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		files, err := readList(f)
+		if err != nil {
+			return fmt.Errorf("reading files from %s: %w", c.FilesFrom, err)
+		}
+		c.Files = files
+	}
+	return nil
+}
+
+// readList reads one path per line, ignoring blank lines.
+func readList(r io.Reader) ([]string, error) {
+	var list []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			list = append(list, line)
+		}
+	}
+	return list, scanner.Err()
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveInputs_RootsFromStdin(t *testing.T) {
+	cfg := &Config{Paths: []string{"apps", "-", "libs"}}
+	stdin := strings.NewReader("one\n\n  two  \n")
+
+	if err := cfg.ResolveInputs(stdin); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"apps", "one", "two", "libs"}
+	if !reflect.DeepEqual(cfg.Paths, want) {
+		t.Errorf("Paths = %v, want %v", cfg.Paths, want)
+	}
+}
+
+func TestResolveInputs_FilesFromStdin(t *testing.T) {
+	cfg := &Config{FilesFrom: "-"}
+	stdin := strings.NewReader("a/package.json\nb/go.mod\n")
+
+	if err := cfg.ResolveInputs(stdin); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"a/package.json", "b/go.mod"}
+	if !reflect.DeepEqual(cfg.Files, want) {
+		t.Errorf("Files = %v, want %v", cfg.Files, want)
+	}
+}
+
+func TestResolveInputs_FilesFromFile(t *testing.T) {
+	listPath := filepath.Join(t.TempDir(), "files.txt")
+	if err := os.WriteFile(listPath, []byte("pubspec.yaml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{FilesFrom: listPath}
+
+	if err := cfg.ResolveInputs(strings.NewReader("")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(cfg.Files, []string{"pubspec.yaml"}) {
+		t.Errorf("Files = %v, want [pubspec.yaml]", cfg.Files)
+	}
+}
+
+func TestResolveInputs_StdinUsedTwice(t *testing.T) {
+	cfg := &Config{Paths: []string{"-"}, FilesFrom: "-"}
+
+	err := cfg.ResolveInputs(strings.NewReader("x\n"))
+	if err == nil || !strings.Contains(err.Error(), "stdin cannot be used both") {
+		t.Errorf("expected stdin error, got: %v", err)
+	}
+}
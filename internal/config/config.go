@@ -0,0 +1,132 @@
+// Package config loads the optional configuration file of clingy.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/flarebyte/clingy-code-detective/internal/declarative"
+	"github.com/flarebyte/clingy-code-detective/internal/plugin"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the content of a configuration file.
+type Config struct {
+	Plugins []PluginConfig `yaml:"plugins"`
+	Parsers []ParserConfig `yaml:"parsers"`
+}
+
+// PluginConfig declares an external parser, see the plugin package for the
+// protocol.
+type PluginConfig struct {
+	Name     string   `yaml:"name"`     // Ecosystem name, reported as packaging
+	Aliases  []string `yaml:"aliases"`  // Alternative names for --include
+	Command  string   `yaml:"command"`  // Executable, relative paths are resolved from the config file
+	Args     []string `yaml:"args"`     // Arguments passed to the executable
+	Patterns []string `yaml:"patterns"` // Filename patterns of the parsed files
+	Timeout  Duration `yaml:"timeout"`  // Maximum run time per file, e.g. 5s
+}
+
+// ParserConfig declares a parser extracting dependencies from a JSON, YAML
+// or TOML document with selectors, see the declarative package.
+type ParserConfig struct {
+	Name         string                 `yaml:"name"`         // Ecosystem name, reported as packaging
+	Aliases      []string               `yaml:"aliases"`      // Alternative names for --include
+	Patterns     []string               `yaml:"patterns"`     // Filename patterns of the parsed files
+	Format       string                 `yaml:"format"`       // json, yaml or toml
+	Dependencies []declarative.Selector `yaml:"dependencies"` // Where the dependencies are found
+
+	parser *declarative.Parser
+}
+
+// Duration is a time.Duration read from a string such as "1m30s".
+type Duration time.Duration
+
+// UnmarshalYAML parses the duration string.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Load reads and validates a configuration file.
+func Load(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	baseDir := filepath.Dir(path)
+	for i := range cfg.Plugins {
+		p := &cfg.Plugins[i]
+		if p.Name == "" || p.Command == "" || len(p.Patterns) == 0 {
+			return nil, fmt.Errorf("config %s: plugin %d requires a name, a command and patterns", path, i+1)
+		}
+		if strings.ContainsRune(p.Command, filepath.Separator) && !filepath.IsAbs(p.Command) {
+			// An absolute path, as exec looks up ./plug.sh joined to "." on PATH
+			command, err := filepath.Abs(filepath.Join(baseDir, p.Command))
+			if err != nil {
+				return nil, fmt.Errorf("config %s: plugin %s: %w", path, p.Name, err)
+			}
+			p.Command = command
+		}
+	}
+	for i := range cfg.Parsers {
+		p := &cfg.Parsers[i]
+		if p.Name == "" || len(p.Patterns) == 0 {
+			return nil, fmt.Errorf("config %s: parser %d requires a name and patterns", path, i+1)
+		}
+		parser, err := declarative.New(p.Format, p.Dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("config %s: parser %s: %w", path, p.Name, err)
+		}
+		p.parser = parser
+	}
+	return &cfg, nil
+}
+
+// Register adds the declared plugins and parsers to the ecosystem registry.
+func (c *Config) Register() error {
+	for _, p := range c.Plugins {
+		parser := plugin.Plugin{Command: p.Command, Args: p.Args, Timeout: time.Duration(p.Timeout)}
+		err := ecosystem.Register(ecosystem.Ecosystem{
+			Name:      p.Name,
+			Aliases:   p.Aliases,
+			Patterns:  p.Patterns,
+			NewParser: func() ecosystem.Parser { return parser },
+		})
+		if err != nil {
+			return fmt.Errorf("plugin %s: %w", p.Name, err)
+		}
+	}
+	for _, p := range c.Parsers {
+		parser := p.parser
+		err := ecosystem.Register(ecosystem.Ecosystem{
+			Name:      p.Name,
+			Aliases:   p.Aliases,
+			Patterns:  p.Patterns,
+			NewParser: func() ecosystem.Parser { return parser },
+		})
+		if err != nil {
+			return fmt.Errorf("parser %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
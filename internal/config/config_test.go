@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "clingy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Plugins(t *testing.T) {
+	path := writeConfig(t, `
+plugins:
+  - name: config-test-tools
+    aliases: [config-test-alias]
+    command: ./bin/tools-parser
+    args: [--json]
+    patterns: [".tool-versions"]
+    timeout: 2s
+  - name: config-test-other
+    command: other-parser
+    patterns: ["*.other"]
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(cfg.Plugins) != 2 {
+		t.Fatalf("expected 2 plugins, got %d", len(cfg.Plugins))
+	}
+
+	first := cfg.Plugins[0]
+	if first.Command != filepath.Join(filepath.Dir(path), "bin", "tools-parser") {
+		t.Errorf("relative command not resolved from the config file: %q", first.Command)
+	}
+	if time.Duration(first.Timeout) != 2*time.Second {
+		t.Errorf("Timeout = %v, want 2s", time.Duration(first.Timeout))
+	}
+	if cfg.Plugins[1].Command != "other-parser" {
+		t.Errorf("command on PATH should be kept: %q", cfg.Plugins[1].Command)
+	}
+
+	if err := cfg.Register(); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	e, ok := ecosystem.Lookup("config-test-alias")
+	if !ok || !e.MatchPath("repo/.tool-versions") {
+		t.Errorf("plugin ecosystem not registered: %+v", e)
+	}
+}
+
+func TestLoad_PluginFromCurrentDirectory(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile("clingy.yaml", []byte("plugins:\n  - name: config-test-local\n    command: ./plug.sh\n    patterns: [\"*.local\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load("clingy.yaml")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got, want := cfg.Plugins[0].Command, filepath.Join(dir, "plug.sh"); got != want {
+		t.Errorf("Command = %q, want %q rather than a name looked up on PATH", got, want)
+	}
+}
+
+func TestLoad_Parsers(t *testing.T) {
+	path := writeConfig(t, `
+parsers:
+  - name: config-test-deps
+    patterns: ["deps.json"]
+    format: json
+    dependencies:
+      - path: $.requires
+      - path: $.tools
+        name: $.id
+        version: $.pin
+        category: dev
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if err := cfg.Register(); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	e, ok := ecosystem.Lookup("config-test-deps")
+	if !ok || !e.MatchPath("svc/deps.json") {
+		t.Fatalf("parser ecosystem not registered: %+v", e)
+	}
+
+	deps, err := e.NewParser().Parse([]byte(`{"requires": {"core": "1.2.0"}, "tools": [{"id": "lint", "pin": "0.3"}]}`))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	want := []ecosystem.Dependency{
+		{Name: "core", Version: "1.2.0", Category: "prod", Line: 1, Column: 15},
+		{Name: "lint", Version: "0.3", Category: "dev", Line: 1, Column: 43},
+	}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("Parse() = %+v, want %+v", deps, want)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unknown field", "plugins:\n  - name: x\n    comand: y\n", "field comand not found"},
+		{"missing command", "plugins:\n  - name: x\n    patterns: [a]\n", "requires a name, a command and patterns"},
+		{"parser without patterns", "parsers:\n  - name: x\n    format: json\n", "requires a name and patterns"},
+		{"parser format", "parsers:\n  - name: x\n    patterns: [a]\n    format: xml\n    dependencies: [{path: $.a}]\n", "unsupported format"},
+		{"parser selector", "parsers:\n  - name: x\n    patterns: [a]\n    format: json\n    dependencies: [{path: \"$.a[\"}]\n", "missing ]"},
+		{"invalid timeout", "plugins:\n  - name: x\n    command: y\n    patterns: [a]\n    timeout: soon\n", "invalid duration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_EmptyFile(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil || len(cfg.Plugins) != 0 {
+		t.Errorf("Load() = %+v, %v; want empty config", cfg, err)
+	}
+}
@@ -0,0 +1,196 @@
+// Package declarative implements dependency parsers described by data rather
+// than code: a format and selectors pointing at the dependencies inside the
+// decoded document.
+package declarative
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/location"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported document formats.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// Selector locates one group of dependencies. Path selects the containers
+// holding the dependencies, either maps or lists. The other selectors are
+// relative to each entry of a container.
+//
+// For a map, the key is the name and a scalar value is the version, as in
+// {"dependencies": {"lodash": "^4.17.21"}}. Name overrides the key and
+// Version selects the version of values which are themselves maps.
+//
+// For a list, Name and Version select the fields of each item. A scalar item
+// is the name when Name is empty.
+type Selector struct {
+	Path         string `yaml:"path"`         // Containers, e.g. $.dependencies
+	Name         string `yaml:"name"`         // Name of an entry, e.g. $.id
+	Version      string `yaml:"version"`      // Version of an entry, e.g. $.version
+	Category     string `yaml:"category"`     // Category of every entry, prod by default
+	CategoryPath string `yaml:"categoryPath"` // Category of an entry, overrides Category when found
+}
+
+// Parser extracts the dependencies matched by its selectors.
+type Parser struct {
+	format string
+	groups []group
+}
+
+type group struct {
+	path, name, version, category selector
+	defaultCategory               string
+}
+
+// New returns a parser of documents in the given format.
+func New(format string, selectors []Selector) (*Parser, error) {
+	format = strings.ToLower(format)
+	switch format {
+	case FormatJSON, FormatYAML, FormatTOML:
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected json, yaml or toml", format)
+	}
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("at least one dependency selector is required")
+	}
+
+	p := &Parser{format: format}
+	for i, s := range selectors {
+		if strings.TrimSpace(s.Path) == "" {
+			return nil, fmt.Errorf("dependency selector %d: path is required", i+1)
+		}
+		g := group{defaultCategory: s.Category}
+		if g.defaultCategory == "" {
+			g.defaultCategory = "prod"
+		}
+		for _, c := range []struct {
+			expr string
+			sel  *selector
+		}{
+			{s.Path, &g.path},
+			{s.Name, &g.name},
+			{s.Version, &g.version},
+			{s.CategoryPath, &g.category},
+		} {
+			if c.expr == "" {
+				continue
+			}
+			sel, err := compileSelector(c.expr)
+			if err != nil {
+				return nil, fmt.Errorf("dependency selector %d: %w", i+1, err)
+			}
+			*c.sel = sel
+		}
+		p.groups = append(p.groups, g)
+	}
+	return p, nil
+}
+
+// Parse decodes the content and returns the selected dependencies, group by
+// group, with map entries sorted by key. Each dependency is located at its
+// key in a map or at its item in a list.
+func (p *Parser) Parse(content []byte) ([]ecosystem.Dependency, error) {
+	doc, positions, err := p.decode(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []ecosystem.Dependency
+	add := func(dep ecosystem.Dependency, ok bool, path []string) {
+		if !ok {
+			return
+		}
+		pos := positions.Of(path...)
+		dep.Line, dep.Column = pos.Line, pos.Column
+		deps = append(deps, dep)
+	}
+	for _, g := range p.groups {
+		for _, container := range g.path.eval(doc) {
+			switch v := container.value.(type) {
+			case map[string]any:
+				for _, key := range sortedKeys(v) {
+					dep, ok := g.entry(key, v[key])
+					add(dep, ok, appendPath(container.path, key))
+				}
+			case []any:
+				for i, item := range v {
+					dep, ok := g.entry("", item)
+					add(dep, ok, appendPath(container.path, strconv.Itoa(i)))
+				}
+			}
+		}
+	}
+	return deps, nil
+}
+
+func (p *Parser) decode(content []byte) (any, location.Paths, error) {
+	var doc any
+	switch p.format {
+	case FormatJSON:
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, nil, err
+		}
+		return doc, location.JSON(content), nil
+	case FormatYAML:
+		var node yaml.Node
+		if err := yaml.Unmarshal(content, &node); err != nil {
+			return nil, nil, err
+		}
+		if err := node.Decode(&doc); err != nil {
+			return nil, nil, err
+		}
+		return doc, location.YAMLNode(&node), nil
+	default:
+		table, positions, err := decodeTOML(content)
+		if err != nil {
+			return nil, nil, err
+		}
+		return table, positions, nil
+	}
+}
+
+// entry builds the dependency of a container entry, key being empty for list
+// items. Entries without a name are skipped.
+func (g group) entry(key string, value any) (ecosystem.Dependency, bool) {
+	dep := ecosystem.Dependency{Name: key, Category: g.defaultCategory}
+	scalar, isScalar := scalarString(value)
+
+	switch {
+	case g.name != nil:
+		dep.Name, _ = g.name.first(value)
+	case key == "" && isScalar:
+		dep.Name = scalar
+	}
+
+	if g.version != nil {
+		dep.Version, _ = g.version.first(value)
+	}
+	if dep.Version == "" && key != "" && isScalar {
+		dep.Version = scalar
+	}
+
+	if g.category != nil {
+		if category, ok := g.category.first(value); ok && category != "" {
+			dep.Category = category
+		}
+	}
+	return dep, dep.Name != ""
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
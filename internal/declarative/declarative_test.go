@@ -0,0 +1,152 @@
+package declarative
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+func TestParser_Parse(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		selectors []Selector
+		input     string
+		want      []ecosystem.Dependency
+		wantErr   bool
+	}{
+		{
+			name:   "json maps like package.json",
+			format: FormatJSON,
+			selectors: []Selector{
+				{Path: "$.dependencies"},
+				{Path: "$.devDependencies", Category: "dev"},
+			},
+			input: `{"dependencies": {"react": "^18.0.0", "axios": "1.6.0"}, "devDependencies": {"jest": "29"}}`,
+			want: []ecosystem.Dependency{
+				{Name: "axios", Version: "1.6.0", Category: "prod", Line: 1, Column: 39},
+				{Name: "react", Version: "^18.0.0", Category: "prod", Line: 1, Column: 19},
+				{Name: "jest", Version: "29", Category: "dev", Line: 1, Column: 78},
+			},
+		},
+		{
+			name:   "yaml map with nested versions",
+			format: FormatYAML,
+			selectors: []Selector{
+				{Path: "$.modules", Version: "$.version", CategoryPath: "$.scope"},
+			},
+			input: `
+modules:
+  auth:
+    version: 2.1.0
+  testkit:
+    version: 0.9
+    scope: dev
+  local:
+    path: ../local
+`,
+			want: []ecosystem.Dependency{
+				{Name: "auth", Version: "2.1.0", Category: "prod", Line: 3, Column: 3},
+				{Name: "local", Version: "", Category: "prod", Line: 8, Column: 3},
+				{Name: "testkit", Version: "0.9", Category: "dev", Line: 5, Column: 3},
+			},
+		},
+		{
+			name:   "toml tables like Cargo.toml",
+			format: FormatTOML,
+			selectors: []Selector{
+				{Path: "$.dependencies", Version: "$.version"},
+				{Path: "$['dev-dependencies']", Category: "dev"},
+			},
+			input: `
+[dependencies]
+serde = "1.0"
+tokio = { version = "1.2", features = ["full"] }
+
+[dev-dependencies]
+criterion = "0.5"
+`,
+			want: []ecosystem.Dependency{
+				{Name: "serde", Version: "1.0", Category: "prod", Line: 3, Column: 1},
+				{Name: "tokio", Version: "1.2", Category: "prod", Line: 4, Column: 1},
+				{Name: "criterion", Version: "0.5", Category: "dev", Line: 7, Column: 1},
+			},
+		},
+		{
+			name:   "list of objects and of scalars",
+			format: FormatJSON,
+			selectors: []Selector{
+				{Path: "$.packages", Name: "$.id", Version: "$.pin"},
+				{Path: "$.tools"},
+			},
+			input: `{"packages": [{"id": "a", "pin": "1"}, {"pin": "2"}], "tools": ["make", 3]}`,
+			want: []ecosystem.Dependency{
+				{Name: "a", Version: "1", Category: "prod", Line: 1, Column: 15},
+				{Name: "make", Version: "", Category: "prod", Line: 1, Column: 65},
+				{Name: "3", Version: "", Category: "prod", Line: 1, Column: 73},
+			},
+		},
+		{
+			name:      "wildcard containers",
+			format:    FormatJSON,
+			selectors: []Selector{{Path: "$.groups.*"}},
+			input:     `{"groups": {"web": {"x": "1"}, "api": {"y": "2"}}}`,
+			want: []ecosystem.Dependency{
+				{Name: "y", Version: "2", Category: "prod", Line: 1, Column: 40},
+				{Name: "x", Version: "1", Category: "prod", Line: 1, Column: 21},
+			},
+		},
+		{
+			name:      "missing path",
+			format:    FormatJSON,
+			selectors: []Selector{{Path: "$.dependencies"}},
+			input:     `{}`,
+			want:      nil,
+		},
+		{
+			name:      "invalid document",
+			format:    FormatJSON,
+			selectors: []Selector{{Path: "$.dependencies"}},
+			input:     `{`,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.format, tt.selectors)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			got, err := p.Parse([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		selectors []Selector
+	}{
+		{"unknown format", "xml", []Selector{{Path: "$.a"}}},
+		{"no selectors", FormatJSON, nil},
+		{"empty path", FormatJSON, []Selector{{Name: "$.a"}}},
+		{"invalid selector", FormatJSON, []Selector{{Path: "$.a", Version: "$.b["}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.format, tt.selectors); err == nil {
+				t.Error("New() expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package declarative
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// step is one segment of a compiled selector.
+type step struct {
+	key      string // Object member, when not a wildcard or an index
+	index    int    // Array index, when isIndex
+	isIndex  bool
+	wildcard bool // Every member of an object or element of an array
+}
+
+// selector is a compiled JSONPath-like expression such as $.a.b, $.a[*].c,
+// $.a.*, $.a[0] or $['key.with.dots']. A leading $ or @ is optional.
+type selector []step
+
+// compileSelector parses a selector expression.
+func compileSelector(expr string) (selector, error) {
+	rest := strings.TrimSpace(expr)
+	rest = strings.TrimPrefix(strings.TrimPrefix(rest, "$"), "@")
+	sel := selector{}
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".*"):
+			sel = append(sel, step{wildcard: true})
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("selector %q: missing ]", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				sel = append(sel, step{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				sel = append(sel, step{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("selector %q: invalid index %q", expr, inner)
+				}
+				sel = append(sel, step{index: index, isIndex: true})
+			}
+		default:
+			// A member name, with or without a leading dot
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("selector %q: empty member name", expr)
+			}
+			sel = append(sel, step{key: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+	return sel, nil
+}
+
+// match is a node selected in a document with the path leading to it.
+type match struct {
+	value any
+	path  []string
+}
+
+// eval returns the nodes of the document selected by the selector.
+func (sel selector) eval(doc any) []match {
+	nodes := []match{{value: doc}}
+	for _, st := range sel {
+		var next []match
+		for _, node := range nodes {
+			switch v := node.value.(type) {
+			case map[string]any:
+				switch {
+				case st.wildcard:
+					for _, k := range sortedKeys(v) {
+						next = append(next, match{v[k], appendPath(node.path, k)})
+					}
+				case !st.isIndex:
+					if child, ok := v[st.key]; ok {
+						next = append(next, match{child, appendPath(node.path, st.key)})
+					}
+				}
+			case []any:
+				switch {
+				case st.wildcard:
+					for i, child := range v {
+						next = append(next, match{child, appendPath(node.path, strconv.Itoa(i))})
+					}
+				case st.isIndex && st.index >= 0 && st.index < len(v):
+					next = append(next, match{v[st.index], appendPath(node.path, strconv.Itoa(st.index))})
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// first returns the first scalar selected as a string.
+func (sel selector) first(doc any) (string, bool) {
+	for _, node := range sel.eval(doc) {
+		if s, ok := scalarString(node.value); ok {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// scalarString converts a scalar value to a string.
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// appendPath returns a new path without sharing the backing array of prefix.
+func appendPath(prefix []string, segment string) []string {
+	path := make([]string, len(prefix)+1)
+	copy(path, prefix)
+	path[len(prefix)] = segment
+	return path
+}
@@ -0,0 +1,59 @@
+package declarative
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectorEval(t *testing.T) {
+	doc := map[string]any{
+		"a": map[string]any{
+			"b":      "x",
+			"c":      "y",
+			"dot.ed": "z",
+		},
+		"list": []any{
+			map[string]any{"id": "first"},
+			map[string]any{"id": "second"},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want []any
+	}{
+		{"$", []any{doc}},
+		{"$.a.b", []any{"x"}},
+		{"a.b", []any{"x"}},
+		{"$.a.*", []any{"x", "y", "z"}},
+		{"$.a['dot.ed']", []any{"z"}},
+		{"$.list[*].id", []any{"first", "second"}},
+		{"$.list[1].id", []any{"second"}},
+		{"$.list[5].id", nil},
+		{"$.missing.b", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			sel, err := compileSelector(tt.expr)
+			if err != nil {
+				t.Fatalf("compileSelector(%q) error: %v", tt.expr, err)
+			}
+			var got []any
+			for _, m := range sel.eval(doc) {
+				got = append(got, m.value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileSelector_Errors(t *testing.T) {
+	for _, expr := range []string{"$.a[", "$.a[x]", "$.a..b"} {
+		if _, err := compileSelector(expr); err == nil {
+			t.Errorf("compileSelector(%q) expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package declarative
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+
+	"github.com/flarebyte/clingy-code-detective/internal/location"
+)
+
+// decodeTOML decodes a TOML document into generic maps, dates and times being
+// kept as strings. It also returns the positions of the keys and array
+// elements.
+func decodeTOML(content []byte) (map[string]any, location.Paths, error) {
+	var doc map[string]any
+	if err := toml.Unmarshal(content, &doc); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			line, _ := decodeErr.Position()
+			return nil, nil, fmt.Errorf("toml line %d: %w", line, err)
+		}
+		return nil, nil, err
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	formatDates(doc)
+	return doc, locateTOML(content), nil
+}
+
+// formatDates replaces the dates and times of a decoded value by strings.
+func formatDates(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = formatDates(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = formatDates(item)
+		}
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case toml.LocalDate:
+		return v.String()
+	case toml.LocalTime:
+		return v.String()
+	case toml.LocalDateTime:
+		return v.String()
+	}
+	return value
+}
+
+// tomlLocator records the positions of a valid TOML document while walking
+// its expressions.
+type tomlLocator struct {
+	content []byte
+	index   *location.Index
+	paths   location.Paths
+	tables  map[string]int // Number of tables of each array of tables
+}
+
+// locateTOML locates the members and elements of a valid TOML document. A
+// member is located at the header or key-value declaring it first.
+func locateTOML(content []byte) location.Paths {
+	l := &tomlLocator{content: content, index: location.NewIndex(content), paths: location.Paths{}, tables: map[string]int{}}
+	var table []string
+
+	var p unstable.Parser
+	p.Reset(content)
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind {
+		case unstable.Table:
+			keys, pos := l.header(expr)
+			table = l.descend(nil, keys, pos)
+		case unstable.ArrayTable:
+			keys, pos := l.header(expr)
+			path := appendPath(l.descend(nil, keys[:len(keys)-1], pos), keys[len(keys)-1])
+			l.locate(path, pos)
+			key := location.Path(path...)
+			table = appendPath(path, strconv.Itoa(l.tables[key]))
+			l.tables[key]++
+			l.locate(table, pos)
+		case unstable.KeyValue:
+			l.keyValue(table, expr)
+		}
+	}
+	return l.paths
+}
+
+// locate records the position of a path unless it is already known, keeping
+// the first declaration of tables extended by later headers or dotted keys.
+func (l *tomlLocator) locate(path []string, pos location.Position) {
+	key := location.Path(path...)
+	if _, ok := l.paths[key]; !ok {
+		l.paths[key] = pos
+	}
+}
+
+// header returns the keys of a table header and the position of its first
+// bracket.
+func (l *tomlLocator) header(expr *unstable.Node) ([]string, location.Position) {
+	keys, first := nodeKeys(expr)
+	offset := int(first.Raw.Offset)
+	for offset > 0 && (l.content[offset-1] == '[' || l.content[offset-1] == ' ' || l.content[offset-1] == '\t') {
+		offset--
+	}
+	return keys, l.index.Position(offset)
+}
+
+// descend returns the path of the table at the dotted keys below a path,
+// entering the last table of arrays of tables. The members entered are
+// located at pos unless already known.
+func (l *tomlLocator) descend(path, keys []string, pos location.Position) []string {
+	for _, k := range keys {
+		path = appendPath(path, k)
+		l.locate(path, pos)
+		if n := l.tables[location.Path(path...)]; n > 0 {
+			path = appendPath(path, strconv.Itoa(n-1))
+		}
+	}
+	return path
+}
+
+func (l *tomlLocator) keyValue(table []string, expr *unstable.Node) {
+	keys, _ := nodeKeys(expr)
+	pos := l.index.Position(int(expr.Raw.Offset))
+	path := appendPath(l.descend(table, keys[:len(keys)-1], pos), keys[len(keys)-1])
+	l.locate(path, pos)
+	l.value(path, expr.Value())
+}
+
+// value locates the elements of arrays and the members of inline tables.
+func (l *tomlLocator) value(path []string, node *unstable.Node) {
+	switch node.Kind {
+	case unstable.Array:
+		i := 0
+		for it := node.Children(); it.Next(); {
+			child := it.Node()
+			if child.Kind == unstable.Comment {
+				continue
+			}
+			elementPath := appendPath(path, strconv.Itoa(i))
+			if offset, ok := nodeOffset(child); ok {
+				l.locate(elementPath, l.index.Position(offset))
+			}
+			l.value(elementPath, child)
+			i++
+		}
+	case unstable.InlineTable:
+		for it := node.Children(); it.Next(); {
+			if child := it.Node(); child.Kind == unstable.KeyValue {
+				l.keyValue(path, child)
+			}
+		}
+	}
+}
+
+// nodeKeys returns the dotted keys of a table header or key-value, and the
+// node of the first key.
+func nodeKeys(expr *unstable.Node) ([]string, *unstable.Node) {
+	var keys []string
+	var first *unstable.Node
+	for it := expr.Key(); it.Next(); {
+		if first == nil {
+			first = it.Node()
+		}
+		keys = append(keys, string(it.Node().Data))
+	}
+	return keys, first
+}
+
+// nodeOffset returns the offset of the first byte of a value. Arrays have no
+// range of their own and are found at their first element.
+func nodeOffset(node *unstable.Node) (int, bool) {
+	if node.Raw.Length > 0 {
+		return int(node.Raw.Offset), true
+	}
+	for it := node.Children(); it.Next(); {
+		if offset, ok := nodeOffset(it.Node()); ok {
+			return offset, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,163 @@
+package declarative
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/location"
+)
+
+func TestDecodeTOML(t *testing.T) {
+	content := `
+# Project
+name = "demo"
+version = 3
+ratio = 1.5
+enabled = true
+released = 2024-01-02
+
+[dependencies]
+serde = "1.0"
+tokio = { version = "1.2", features = ["full", "rt"] }
+"quoted.key" = 'literal\n'
+
+[tool.poetry.dev-dependencies]
+pytest = "^7.0" # trailing comment
+
+[[bin]]
+name = "one"
+
+[[bin]]
+name = "two"
+path.main = "src/two.rs"
+
+list = [
+  "a",
+  "b", # comment
+]
+`
+	want := map[string]any{
+		"name":     "demo",
+		"version":  int64(3),
+		"ratio":    1.5,
+		"enabled":  true,
+		"released": "2024-01-02",
+		"dependencies": map[string]any{
+			"serde":      "1.0",
+			"tokio":      map[string]any{"version": "1.2", "features": []any{"full", "rt"}},
+			"quoted.key": `literal\n`,
+		},
+		"tool": map[string]any{"poetry": map[string]any{
+			"dev-dependencies": map[string]any{"pytest": "^7.0"},
+		}},
+		"bin": []any{
+			map[string]any{"name": "one"},
+			map[string]any{
+				"name": "two",
+				"path": map[string]any{"main": "src/two.rs"},
+				"list": []any{"a", "b"},
+			},
+		},
+	}
+
+	got, positions, err := decodeTOML([]byte(content))
+	if err != nil {
+		t.Fatalf("decodeTOML() error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeTOML() = %#v\nwant %#v", got, want)
+	}
+
+	wantPositions := map[string]location.Position{
+		location.Path("name"):                                         {Line: 3, Column: 1},
+		location.Path("dependencies"):                                 {Line: 9, Column: 1},
+		location.Path("dependencies", "tokio"):                        {Line: 11, Column: 1},
+		location.Path("dependencies", "tokio", "features", "1"):       {Line: 11, Column: 48},
+		location.Path("tool", "poetry", "dev-dependencies", "pytest"): {Line: 15, Column: 1},
+		location.Path("bin", "1"):                                     {Line: 20, Column: 1},
+		location.Path("bin", "1", "path", "main"):                     {Line: 22, Column: 1},
+		location.Path("bin", "1", "list", "1"):                        {Line: 26, Column: 3},
+	}
+	for path, want := range wantPositions {
+		if got := positions[path]; got != want {
+			t.Errorf("position of %q = %+v, want %+v", strings.ReplaceAll(path, "\x00", "."), got, want)
+		}
+	}
+}
+
+func TestDecodeTOML_MultiLineStringsAndInlineTables(t *testing.T) {
+	content := `[package]
+name = "demo"
+description = """
+A demo crate
+with a "quoted" word.
+"""
+notes = '''
+raw \d+
+'''
+
+[dependencies]
+serde = { version = "1.0", features = [
+  "derive",
+] }
+regex = { git = "https://github.com/rust-lang/regex", rev = "9f9f693" }
+log = "0.4"
+`
+	got, positions, err := decodeTOML([]byte(content))
+	if err != nil {
+		t.Fatalf("decodeTOML() error: %v", err)
+	}
+
+	want := map[string]any{
+		"package": map[string]any{
+			"name":        "demo",
+			"description": "A demo crate\nwith a \"quoted\" word.\n",
+			"notes":       "raw \\d+\n",
+		},
+		"dependencies": map[string]any{
+			"serde": map[string]any{"version": "1.0", "features": []any{"derive"}},
+			"regex": map[string]any{"git": "https://github.com/rust-lang/regex", "rev": "9f9f693"},
+			"log":   "0.4",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeTOML() = %#v\nwant %#v", got, want)
+	}
+
+	wantPositions := map[string]location.Position{
+		location.Path("package", "notes"):                       {Line: 7, Column: 1},
+		location.Path("dependencies", "serde"):                  {Line: 12, Column: 1},
+		location.Path("dependencies", "serde", "version"):       {Line: 12, Column: 11},
+		location.Path("dependencies", "serde", "features", "0"): {Line: 13, Column: 3},
+		location.Path("dependencies", "regex"):                  {Line: 15, Column: 1},
+		location.Path("dependencies", "log"):                    {Line: 16, Column: 1},
+	}
+	for path, want := range wantPositions {
+		if got := positions[path]; got != want {
+			t.Errorf("position of %q = %+v, want %+v", strings.ReplaceAll(path, "\x00", "."), got, want)
+		}
+	}
+}
+
+func TestDecodeTOML_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unterminated string", `a = "x`},
+		{"missing value", `a = `},
+		{"duplicate key", "a = 1\na = 2"},
+		{"unterminated table", `[a`},
+		{"garbage after value", `a = "x" y`},
+		{"unterminated multi-line string", "a = \"\"\"x\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeTOML([]byte(tt.content)); err == nil {
+				t.Errorf("decodeTOML(%q) expected an error", tt.content)
+			}
+		})
+	}
+}
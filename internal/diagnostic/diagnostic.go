@@ -0,0 +1,169 @@
+// Package diagnostic collects the errors and warnings found while scanning,
+// so that they are reported with the dependencies instead of being lost on
+// stderr.
+package diagnostic
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Severity tells whether a diagnostic is an error or a warning.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Code identifies the kind of a diagnostic.
+type Code string
+
+const (
+	CodeUsage        Code = "usage"                  // Invalid arguments or configuration
+	CodeParse        Code = "parse-error"            // Manifest which cannot be parsed
+	CodePartial      Code = "partial-parse"          // Malformed manifest whose dependencies were partly recovered
+	CodeConflict     Code = "merge-conflict"         // Manifest with unresolved merge conflict blocks
+	CodeUnsupported  Code = "unsupported-file"       // Explicit file no parser understands
+	CodeRead         Code = "read-error"             // File which cannot be read
+	CodeWalk         Code = "walk-error"             // Directory, archive or tree which cannot be walked
+	CodeOutput       Code = "output-error"           // Report which cannot be rendered
+	CodeIncomplete   Code = "incomplete"             // Scan stopped by --timeout or a signal
+	CodeMaxDepth     Code = "max-depth"              // Path skipped by --max-depth
+	CodeMaxFileSize  Code = "max-file-size"          // Manifest skipped by --max-file-size
+	CodeMaxFiles     Code = "max-files"              // Manifests skipped by --max-files
+	CodeArchiveEntry Code = "archive-max-entry-size" // Archive entry above the size limit
+)
+
+// Exit codes of the process. Errors change the exit code, and warnings too
+// with --strict.
+const (
+	ExitOK         = 0 // Complete scan, or warnings only without --strict
+	ExitUsage      = 1 // Invalid arguments or configuration
+	ExitParse      = 2 // Manifests which could not be parsed
+	ExitPolicy     = 3 // Paths skipped by a limit
+	ExitIO         = 4 // Paths which could not be read or walked, or output failure
+	ExitIncomplete = 5 // Scan stopped before completion, always reported
+)
+
+// ExitCode returns the exit code of a failure with this code.
+func (c Code) ExitCode() int {
+	switch c {
+	case CodeUsage:
+		return ExitUsage
+	case CodeParse, CodePartial, CodeConflict, CodeUnsupported:
+		return ExitParse
+	case CodeMaxDepth, CodeMaxFileSize, CodeMaxFiles, CodeArchiveEntry:
+		return ExitPolicy
+	case CodeIncomplete:
+		return ExitIncomplete
+	default:
+		return ExitIO
+	}
+}
+
+// ExitCode returns the exit code of a scan which completed with the given
+// diagnostics: the highest exit code of its errors, warnings included when
+// strict, or ExitOK.
+func ExitCode(diags []Diagnostic, strict bool) int {
+	code := ExitOK
+	for _, d := range diags {
+		if strict || d.Severity == Error {
+			code = max(code, d.Code.ExitCode())
+		}
+	}
+	return code
+}
+
+// Incomplete returns true if the scan stopped before completion.
+func Incomplete(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Code == CodeIncomplete {
+			return true
+		}
+	}
+	return false
+}
+
+// Diagnostic is an error or a warning about a path, or about the whole scan
+// when Path is empty.
+type Diagnostic struct {
+	Severity Severity
+	Code     Code
+	Path     string `json:",omitempty"`
+	Line     int    `json:",omitempty"`
+	Message  string
+}
+
+// Location returns the path followed by the line when known.
+func (d Diagnostic) Location() string {
+	if d.Line <= 0 {
+		return d.Path
+	}
+	return fmt.Sprintf("%s:%d", d.Path, d.Line)
+}
+
+func (d Diagnostic) String() string {
+	if d.Path == "" {
+		return fmt.Sprintf("%s[%s]: %s", d.Severity, d.Code, d.Message)
+	}
+	return fmt.Sprintf("%s[%s] %s: %s", d.Severity, d.Code, d.Location(), d.Message)
+}
+
+// Errorf returns an error diagnostic.
+func Errorf(code Code, path string, format string, args ...any) Diagnostic {
+	return Diagnostic{Severity: Error, Code: code, Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// Warnf returns a warning diagnostic.
+func Warnf(code Code, path string, format string, args ...any) Diagnostic {
+	return Diagnostic{Severity: Warning, Code: code, Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// Collector gathers diagnostics from concurrent goroutines. A nil Collector
+// discards them.
+type Collector struct {
+	mu          sync.Mutex
+	diagnostics []Diagnostic
+	reported    map[Diagnostic]bool
+}
+
+// Report adds a diagnostic, unless the same one was already reported, as for
+// a lockfile shared by the manifests of a project.
+func (c *Collector) Report(d Diagnostic) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reported[d] {
+		return
+	}
+	if c.reported == nil {
+		c.reported = make(map[Diagnostic]bool)
+	}
+	c.reported[d] = true
+	c.diagnostics = append(c.diagnostics, d)
+}
+
+// Diagnostics returns the diagnostics sorted by path, line and code.
+func (c *Collector) Diagnostics() []Diagnostic {
+	if c == nil {
+		return []Diagnostic{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sorted := append([]Diagnostic{}, c.diagnostics...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Code < b.Code
+	})
+	return sorted
+}
@@ -0,0 +1,102 @@
+package diagnostic
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestDiagnostic_String(t *testing.T) {
+	d := Errorf(CodeParse, "apps/web/package.json", "unexpected %s", "}")
+	d.Line = 4
+	if got, want := d.String(), "error[parse-error] apps/web/package.json:4: unexpected }"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := Warnf(CodeMaxFiles, "", "stopped").String(), "warning[max-files]: stopped"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestCode_ExitCode(t *testing.T) {
+	tests := []struct {
+		code Code
+		want int
+	}{
+		{CodeUsage, ExitUsage},
+		{CodeParse, ExitParse},
+		{CodePartial, ExitParse},
+		{CodeConflict, ExitParse},
+		{CodeUnsupported, ExitParse},
+		{CodeMaxFiles, ExitPolicy},
+		{CodeArchiveEntry, ExitPolicy},
+		{CodeRead, ExitIO},
+		{CodeWalk, ExitIO},
+		{CodeOutput, ExitIO},
+		{CodeIncomplete, ExitIncomplete},
+	}
+	for _, tt := range tests {
+		if got := tt.code.ExitCode(); got != tt.want {
+			t.Errorf("%s.ExitCode() = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	parse := Errorf(CodeParse, "package.json", "invalid")
+	partial := Warnf(CodePartial, "package.json", "recovered")
+	limit := Warnf(CodeMaxDepth, "deep", "skipped")
+	walk := Errorf(CodeWalk, "missing", "not found")
+	incomplete := Errorf(CodeIncomplete, "", "timed out")
+
+	tests := []struct {
+		name   string
+		diags  []Diagnostic
+		strict bool
+		want   int
+	}{
+		{"no diagnostics", nil, true, ExitOK},
+		{"errors without strict", []Diagnostic{parse, walk}, false, ExitIO},
+		{"parse error without strict", []Diagnostic{parse, limit}, false, ExitParse},
+		{"warnings without strict", []Diagnostic{limit, partial}, false, ExitOK},
+		{"warnings with strict", []Diagnostic{partial}, true, ExitParse},
+		{"parse error", []Diagnostic{parse}, true, ExitParse},
+		{"limit warning", []Diagnostic{limit, parse}, true, ExitPolicy},
+		{"highest code wins", []Diagnostic{walk, limit, parse}, true, ExitIO},
+		{"incomplete without strict", []Diagnostic{parse, incomplete}, false, ExitIncomplete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.diags, tt.strict); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollector(t *testing.T) {
+	c := &Collector{}
+	var wg sync.WaitGroup
+	for _, d := range []Diagnostic{
+		{Severity: Error, Code: CodeParse, Path: "b", Line: 2},
+		{Severity: Warning, Code: CodeMaxDepth, Path: "b", Line: 1},
+		{Severity: Error, Code: CodeWalk, Path: "a"},
+		{Severity: Error, Code: CodeWalk, Path: "a"}, // Reported once
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Report(d)
+		}()
+	}
+	wg.Wait()
+
+	got := c.Diagnostics()
+	if len(got) != 3 || got[0].Path != "a" || got[1].Line != 1 || got[2].Line != 2 {
+		t.Errorf("Diagnostics() not sorted by path and line: %+v", got)
+	}
+
+	var discard *Collector
+	discard.Report(got[0])
+	if len(discard.Diagnostics()) != 0 {
+		t.Error("a nil Collector should discard diagnostics")
+	}
+}
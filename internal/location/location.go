@@ -0,0 +1,154 @@
+// Package location finds where the entries of a dependency file are declared.
+package location
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a 1-based line and byte column, zero when unknown.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Index converts byte offsets of a content to positions.
+type Index struct {
+	lineStarts []int
+}
+
+// NewIndex indexes the line starts of the content.
+func NewIndex(content []byte) *Index {
+	starts := []int{0}
+	for i, c := range content {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &Index{lineStarts: starts}
+}
+
+// Position returns the position of a byte offset.
+func (x *Index) Position(offset int) Position {
+	line := sort.SearchInts(x.lineStarts, offset+1) - 1
+	return Position{Line: line + 1, Column: offset - x.lineStarts[line] + 1}
+}
+
+// Paths holds the positions of the members and elements of a document, keyed
+// by Path. A member is located at its key and an element at its first byte.
+type Paths map[string]Position
+
+// Path joins the object keys and array indexes leading to a value.
+func Path(segments ...string) string {
+	return strings.Join(segments, "\x00")
+}
+
+// Of returns the position at the path, zero when unknown.
+func (p Paths) Of(segments ...string) Position {
+	return p[Path(segments...)]
+}
+
+// JSON locates the members and elements of a JSON document. The positions
+// found before a syntax error are kept.
+func JSON(content []byte) Paths {
+	paths := Paths{}
+	index := NewIndex(content)
+	dec := json.NewDecoder(bytes.NewReader(content))
+
+	// next returns the offset of the next token, skipping separators.
+	next := func() int {
+		offset := int(dec.InputOffset())
+		for offset < len(content) && strings.IndexByte(" \t\r\n,:", content[offset]) >= 0 {
+			offset++
+		}
+		return offset
+	}
+
+	var walk func(prefix []string) error
+	walk = func(prefix []string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				start := next()
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				path := appendPath(prefix, key.(string))
+				paths[Path(path...)] = index.Position(start)
+				if err := walk(path); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				path := appendPath(prefix, strconv.Itoa(i))
+				paths[Path(path...)] = index.Position(next())
+				if err := walk(path); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		}
+		return err
+	}
+	_ = walk(nil)
+	return paths
+}
+
+// YAML locates the members and elements of the first document of a YAML
+// content. Nothing is located when the content is invalid.
+func YAML(content []byte) Paths {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return Paths{}
+	}
+	return YAMLNode(&doc)
+}
+
+// YAMLNode locates the members and elements below a decoded YAML node.
+func YAMLNode(node *yaml.Node) Paths {
+	paths := Paths{}
+	var walk func(n *yaml.Node, prefix []string)
+	walk = func(n *yaml.Node, prefix []string) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, child := range n.Content {
+				walk(child, prefix)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key := n.Content[i]
+				path := appendPath(prefix, key.Value)
+				paths[Path(path...)] = Position{Line: key.Line, Column: key.Column}
+				walk(n.Content[i+1], path)
+			}
+		case yaml.SequenceNode:
+			for i, child := range n.Content {
+				path := appendPath(prefix, strconv.Itoa(i))
+				paths[Path(path...)] = Position{Line: child.Line, Column: child.Column}
+				walk(child, path)
+			}
+		}
+	}
+	walk(node, nil)
+	return paths
+}
+
+// appendPath returns a new path without sharing the backing array of prefix.
+func appendPath(prefix []string, segment string) []string {
+	path := make([]string, len(prefix)+1)
+	copy(path, prefix)
+	path[len(prefix)] = segment
+	return path
+}
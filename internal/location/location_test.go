@@ -0,0 +1,88 @@
+package location
+
+import "testing"
+
+func TestIndex_Position(t *testing.T) {
+	index := NewIndex([]byte("ab\ncd\n\nef"))
+	tests := []struct {
+		offset int
+		want   Position
+	}{
+		{0, Position{1, 1}},
+		{1, Position{1, 2}},
+		{3, Position{2, 1}},
+		{6, Position{3, 1}},
+		{8, Position{4, 2}},
+	}
+	for _, tt := range tests {
+		if got := index.Position(tt.offset); got != tt.want {
+			t.Errorf("Position(%d) = %+v, want %+v", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	content := `{
+  "dependencies": {
+    "react": "^18.0.0",
+    "a\"b": "1"
+  },
+  "files": ["dist", {"x": 1}]
+}`
+	positions := JSON([]byte(content))
+
+	tests := []struct {
+		path []string
+		want Position
+	}{
+		{[]string{"dependencies"}, Position{2, 3}},
+		{[]string{"dependencies", "react"}, Position{3, 5}},
+		{[]string{"dependencies", `a"b`}, Position{4, 5}},
+		{[]string{"files", "0"}, Position{6, 13}},
+		{[]string{"files", "1", "x"}, Position{6, 22}},
+		{[]string{"missing"}, Position{}},
+	}
+	for _, tt := range tests {
+		if got := positions.Of(tt.path...); got != tt.want {
+			t.Errorf("Of(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestJSON_Invalid(t *testing.T) {
+	positions := JSON([]byte("{\"a\": 1,\n \"b\": }"))
+	if got := positions.Of("a"); got != (Position{1, 2}) {
+		t.Errorf("positions before the error should be kept, got %+v", got)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	content := `dependencies:
+  http: ^0.13.3
+  flutter:
+    sdk: flutter
+tools:
+  - make
+  - name: lint
+`
+	positions := YAML([]byte(content))
+
+	tests := []struct {
+		path []string
+		want Position
+	}{
+		{[]string{"dependencies", "http"}, Position{2, 3}},
+		{[]string{"dependencies", "flutter", "sdk"}, Position{4, 5}},
+		{[]string{"tools", "0"}, Position{6, 5}},
+		{[]string{"tools", "1", "name"}, Position{7, 5}},
+	}
+	for _, tt := range tests {
+		if got := positions.Of(tt.path...); got != tt.want {
+			t.Errorf("Of(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+
+	if got := YAML([]byte("a: [")); len(got) != 0 {
+		t.Errorf("invalid YAML should not be located, got %v", got)
+	}
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	ecosystem.MustRegister(ecosystem.Ecosystem{
+		Name:            "node",
+		Aliases:         []string{"js", "ts"},
+		Patterns:        []string{"package.json", "package.*.json"},
+		SniffExtensions: []string{".json"},
+		Sniff:           sniffPackageJSON,
+		Lockfiles:       []string{"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lock"},
+		NewParser:       func() Parser { return nodeParser{} },
+	})
+	ecosystem.MustRegister(ecosystem.Ecosystem{
+		Name:            "dart",
+		Aliases:         []string{"flutter"},
+		Patterns:        []string{"pubspec.yaml", "pubspec.*.yaml"},
+		SniffExtensions: []string{".yaml", ".yml"},
+		Sniff:           sniffPubspec,
+		Lockfiles:       []string{"pubspec.lock"},
+		NewParser:       func() Parser { return dartParser{} },
+	})
+	ecosystem.MustRegister(ecosystem.Ecosystem{
+		Name:            "go",
+		Aliases:         []string{"golang"},
+		Patterns:        []string{"go.mod"},
+		SniffExtensions: []string{".mod"},
+		Sniff:           sniffGoMod,
+		Lockfiles:       []string{"go.sum"},
+		NewParser:       func() Parser { return goModParser{} },
+	})
+	ecosystem.MustRegister(ecosystem.Ecosystem{
+		Name:    "python",
+		Aliases: []string{"py", "pip"},
+		Patterns: []string{
+			"requirements.txt", "requirements*.txt", "*requirements.txt",
+			"requirements*.in", "*requirements.in", "requirements/*.txt", "requirements/*.in",
+		},
+		CategoryFromName: true,
+		SniffExtensions:  []string{".txt", ".in"},
+		Sniff:            sniffRequirements,
+		NewParser:        func() Parser { return pythonParser{} },
+	})
+}
+
+// isSniffable returns true if the content of the file may be sniffed by one
+// of the registered ecosystems.
+func isSniffable(filePath string) bool {
+	for _, e := range ecosystem.All() {
+		if e.Sniffable(filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// devNameTokens are the filename tokens denoting non production dependencies.
+var devNameTokens = map[string]struct{}{
+	"dev": {}, "develop": {}, "development": {}, "test": {}, "tests": {},
+	"testing": {}, "lint": {}, "docs": {}, "doc": {}, "ci": {},
+}
+
+// CategoryFromFilename infers the category of the dependencies declared in a
+// file from its name, e.g. "dev" for requirements-dev.txt or
+// requirements/test.txt, and "prod" otherwise.
+func CategoryFromFilename(filePath string) string {
+	base := strings.ToLower(filepath.Base(filePath))
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	tokens := strings.FieldsFunc(base, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	for _, token := range tokens {
+		if _, ok := devNameTokens[token]; ok {
+			return "dev"
+		}
+	}
+	return "prod"
+}
+
+func sniffPackageJSON(content []byte) bool {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+	_, hasDeps := doc["dependencies"]
+	_, hasDevDeps := doc["devDependencies"]
+	return hasDeps || hasDevDeps
+}
+
+func sniffPubspec(content []byte) bool {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+	_, hasName := doc["name"]
+	_, hasEnvironment := doc["environment"]
+	_, hasDeps := doc["dependencies"]
+	return hasName && (hasEnvironment || hasDeps)
+}
+
+func sniffGoMod(content []byte) bool {
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("module ")) {
+			return true
+		}
+	}
+	return false
+}
+
+var requirementLine = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(\[[^\]]*\])?\s*((===?|~=|!=|<=?|>=?)\s*[^\s;#]+\s*(,\s*(===?|~=|!=|<=?|>=?)\s*[^\s;#]+\s*)*)?(;.*)?(#.*)?$`)
+
+func sniffRequirements(content []byte) bool {
+	found := false
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if !requirementLine.MatchString(line) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+func TestBuiltinEcosystems_Match(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"app/package.json", "node"},
+		{"app/package.template.json", "node"},
+		{"app/package-lock.json", ""},
+		{"pubspec.yaml", "dart"},
+		{"go.mod", "go"},
+		{"requirements.txt", "python"},
+		{"requirements-dev.txt", "python"},
+		{"dev-requirements.in", "python"},
+		{"requirements/base.txt", "python"},
+		{"REQUIREMENTS.TXT", "python"},
+		{"notes.txt", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			e, ok := ecosystem.Match(tt.path)
+			if ok != (tt.want != "") || e.Name != tt.want {
+				t.Errorf("ecosystem.Match(%q) = %q, %v; want %q", tt.path, e.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategoryFromFilename(t *testing.T) {
+	tests := map[string]string{
+		"requirements.txt":           "prod",
+		"requirements-dev.txt":       "dev",
+		"dev-requirements.in":        "dev",
+		"requirements/base.txt":      "prod",
+		"requirements/test.txt":      "dev",
+		"requirements_testing.txt":   "dev",
+		"requirements-devices.txt":   "prod",
+		"requirements/lint-docs.txt": "dev",
+	}
+	for path, want := range tests {
+		if got := CategoryFromFilename(path); got != want {
+			t.Errorf("CategoryFromFilename(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestBuiltinEcosystems_Sniff(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{"package json", "manifest.json", `{"name":"x","dependencies":{"a":"1"}}`, "node"},
+		{"tsconfig json", "tsconfig.json", `{"compilerOptions":{}}`, ""},
+		{"pubspec", "app.yaml", "name: app\nenvironment:\n  sdk: '>=3.0.0'\n", "dart"},
+		{"other yaml", "ci.yml", "jobs:\n  build: {}\n", ""},
+		{"go module", "tools.mod", "module example.com/tools\n", "go"},
+		{"requirements", "deps.txt", "-r base.txt\nrequests==2.0\nflask>=1.0,<2 ; python_version > '3'\n", "python"},
+		{"prose", "notes.txt", "Remember to update the docs.\n", ""},
+		{"unsniffable extension", "deps.cfg", "requests==2.0\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, ok := ecosystem.Sniff(tt.path, []byte(tt.content))
+			if ok != (tt.want != "") || e.Name != tt.want {
+				t.Errorf("ecosystem.Sniff(%q) = %q, %v; want %q", tt.path, e.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDependencyFile_FilenamePatterns(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	devFile := ParseDependencyFile(write("requirements-dev.txt", "-r requirements.txt\npytest==7.0\n"))
+	if devFile.Err != nil || devFile.Packaging != "python" {
+		t.Fatalf("requirements-dev.txt: packaging %q, err %v", devFile.Packaging, devFile.Err)
+	}
+	wantDev := []Dependency{{Name: "pytest", Version: "7.0", Category: "dev", Line: 2, Column: 1}}
+	if !reflect.DeepEqual(devFile.Dependencies, wantDev) {
+		t.Errorf("requirements-dev.txt: got %v, want %v", devFile.Dependencies, wantDev)
+	}
+
+	sniffed := ParseDependencyFile(write("web/manifest.json", `{"dependencies":{"react":"18.0.0"}}`))
+	if sniffed.Err != nil || sniffed.Packaging != "node" {
+		t.Errorf("manifest.json: packaging %q, err %v", sniffed.Packaging, sniffed.Err)
+	}
+
+	notManifest := ParseDependencyFile(write("tsconfig.json", `{"compilerOptions":{}}`))
+	if !errors.Is(notManifest.Err, ErrNotManifest) {
+		t.Errorf("tsconfig.json: expected ErrNotManifest, got %v", notManifest.Err)
+	}
+
+	unsupported := ParseDependencyFile(write("Cargo.toml", ""))
+	if !errors.Is(unsupported.Err, ErrUnsupportedFile) {
+		t.Errorf("Cargo.toml: expected ErrUnsupportedFile, got %v", unsupported.Err)
+	}
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+// bunLock is a bun.lock file, JSON with trailing commas. Packages are keyed
+// by their path in node_modules, e.g. a/@b/c for @b/c nested under a, and
+// described by an array starting with name@version.
+type bunLock struct {
+	Workspaces map[string]bunWorkspace      `json:"workspaces"`
+	Packages   map[string][]json.RawMessage `json:"packages"`
+}
+
+type bunWorkspace struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// readBunLock resolves the dependencies of each package from the closest
+// node_modules, as bun installs them.
+func readBunLock(content []byte) (ecosystem.Resolution, error) {
+	var lock bunLock
+	if err := json.Unmarshal(sanitizeJSON(content), &lock); err != nil {
+		return ecosystem.Resolution{}, err
+	}
+
+	resolve := func(key string, deps bunWorkspace) []string {
+		var ids []string
+		for _, section := range []map[string]string{deps.Dependencies, deps.DevDependencies, deps.OptionalDependencies, deps.PeerDependencies} {
+			for name := range section {
+				for dir := key; ; dir = bunParent(dir) {
+					candidate := name
+					if dir != "" {
+						candidate = dir + "/" + name
+					}
+					if _, ok := lock.Packages[candidate]; ok {
+						ids = append(ids, candidate)
+						break
+					}
+					if dir == "" {
+						break
+					}
+				}
+			}
+		}
+		return ids
+	}
+
+	packages := make(map[string]ecosystem.ResolvedPackage)
+	for key, fields := range lock.Packages {
+		var spec string
+		if len(fields) == 0 || json.Unmarshal(fields[0], &spec) != nil {
+			continue
+		}
+		name, version := packageSpecName(spec)
+		// Dependencies are in the first object, or in the workspace
+		var deps bunWorkspace
+		if workspace, found := strings.CutPrefix(version, "workspace:"); found {
+			deps = lock.Workspaces[workspace]
+			if deps.Version != "" {
+				version = deps.Version
+			}
+		} else {
+			for _, field := range fields[1:] {
+				if json.Unmarshal(field, &deps) == nil {
+					break
+				}
+			}
+		}
+		packages[key] = ecosystem.ResolvedPackage{Name: name, Version: version, Dependencies: resolve(key, deps)}
+	}
+	return newResolution(resolve("", lock.Workspaces[""]), packages, false), nil
+}
+
+// bunParent returns the key of the package a nested package is installed
+// under, e.g. a for a/@b/c, or nothing for a top level package.
+func bunParent(key string) string {
+	segments := strings.Split(key, "/")
+	last := len(segments) - 1
+	if last > 0 && strings.HasPrefix(segments[last-1], "@") {
+		last--
+	}
+	return strings.Join(segments[:last], "/")
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+// ConflictError is returned for files with unresolved merge conflict blocks.
+// Both sides are parsed, so that the dependencies they disagree on can be
+// reported instead of a syntax error.
+type ConflictError struct {
+	Line    int    // Line of the first conflict marker
+	Blocks  int    // Number of conflict blocks
+	Ours    string // Label of the current side, e.g. HEAD
+	Theirs  string // Label of the incoming side, e.g. a branch name
+	Changes []VersionChange
+}
+
+// VersionChange is a dependency declared differently on both sides of a
+// conflict. A version is empty when the dependency is missing on that side.
+type VersionChange struct {
+	Name   string
+	Ours   string
+	Theirs string
+}
+
+func (e *ConflictError) Error() string {
+	conflicts := "unresolved merge conflict"
+	if e.Blocks > 1 {
+		conflicts = fmt.Sprintf("%d unresolved merge conflicts", e.Blocks)
+	}
+	if len(e.Changes) == 0 {
+		return fmt.Sprintf("%s between %s and %s", conflicts, e.Ours, e.Theirs)
+	}
+	changes := make([]string, 0, len(e.Changes))
+	for _, c := range e.Changes {
+		changes = append(changes, fmt.Sprintf("%s %s (%s) vs %s (%s)", c.Name, orMissing(c.Ours), e.Ours, orMissing(c.Theirs), e.Theirs))
+	}
+	return fmt.Sprintf("%s: %s", conflicts, strings.Join(changes, ", "))
+}
+
+func orMissing(version string) string {
+	if version == "" {
+		return "missing"
+	}
+	return version
+}
+
+// findConflict returns the position and labels of the merge conflict blocks
+// of the content, if any.
+func findConflict(content []byte) (*ConflictError, bool) {
+	var conflict *ConflictError
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		trimmed := string(bytes.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(trimmed, conflictOurs):
+			if conflict == nil {
+				conflict = &ConflictError{
+					Line:   i + 1,
+					Ours:   conflictLabel(trimmed, conflictOurs, "ours"),
+					Theirs: "theirs",
+				}
+			}
+			conflict.Blocks++
+		case conflict != nil && conflict.Blocks == 1 && strings.HasPrefix(trimmed, conflictTheirs):
+			conflict.Theirs = conflictLabel(trimmed, conflictTheirs, "theirs")
+		}
+	}
+	return conflict, conflict != nil
+}
+
+// conflictLabel returns the label following a marker, such as the branch
+// name, or fallback when there is none.
+func conflictLabel(line, marker, fallback string) string {
+	if label := strings.TrimSpace(strings.TrimLeft(line, marker[:1])); label != "" {
+		return label
+	}
+	return fallback
+}
+
+// parseConflict parses both sides of a file with conflict blocks and returns
+// the dependencies of the current side, or an error when it cannot be read.
+func parseConflict(ctx context.Context, parser Parser, path string, content []byte, conflict *ConflictError) ([]Dependency, error) {
+	ours, err := parseSide(ctx, parser, path, resolveConflicts(content, keepOurs))
+	theirs, _ := parseSide(ctx, parser, path, resolveConflicts(content, keepTheirs))
+	conflict.Changes = versionChanges(ours, theirs)
+	return ours, err
+}
+
+// parseSide parses one side of a conflict, recovering what it can when the
+// side is still malformed.
+func parseSide(ctx context.Context, parser Parser, path string, content []byte) ([]Dependency, error) {
+	deps, err := parseContent(ctx, parser, path, content)
+	if err == nil {
+		return deps, nil
+	}
+	if r, ok := parser.(ecosystem.Recoverer); ok {
+		if recovered, rerr := r.Recover(content); rerr == nil && len(recovered) > 0 {
+			return recovered, nil
+		}
+	}
+	return nil, err
+}
+
+// versionChanges lists the dependencies whose versions differ between both
+// sides, sorted by name.
+func versionChanges(ours, theirs []Dependency) []VersionChange {
+	versions := func(deps []Dependency) map[string]string {
+		m := make(map[string]string, len(deps))
+		for _, d := range deps {
+			m[d.Name] = d.Version
+		}
+		return m
+	}
+	oursVersions, theirsVersions := versions(ours), versions(theirs)
+
+	var changes []VersionChange
+	for name, v := range oursVersions {
+		if tv, ok := theirsVersions[name]; !ok || tv != v {
+			changes = append(changes, VersionChange{Name: name, Ours: v, Theirs: tv})
+		}
+	}
+	for name, v := range theirsVersions {
+		if _, ok := oursVersions[name]; !ok {
+			changes = append(changes, VersionChange{Name: name, Theirs: v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
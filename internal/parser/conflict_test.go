@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+func TestFindConflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *ConflictError
+	}{
+		{"none", "a\n=======\nb\n", nil},
+		{
+			"labelled", "a\n<<<<<<< HEAD\nb\n=======\nc\n>>>>>>> renovate/lodash\n",
+			&ConflictError{Line: 2, Blocks: 1, Ours: "HEAD", Theirs: "renovate/lodash"},
+		},
+		{
+			"unlabelled blocks", "<<<<<<<\nb\n=======\nc\n>>>>>>>\n  <<<<<<< HEAD\nd\n=======\n>>>>>>> x\n",
+			&ConflictError{Line: 1, Blocks: 2, Ours: "ours", Theirs: "theirs"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := findConflict([]byte(tt.content))
+			if ok != (tt.want != nil) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findConflict() = %+v, %v, want %+v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestConflictError_Error(t *testing.T) {
+	err := &ConflictError{Line: 3, Blocks: 1, Ours: "HEAD", Theirs: "feature"}
+	if got, want := err.Error(), "unresolved merge conflict between HEAD and feature"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	err.Blocks = 2
+	err.Changes = []VersionChange{{Name: "a", Ours: "1.0.0", Theirs: "1.1.0"}, {Name: "b", Theirs: "2.0.0"}}
+	want := "2 unresolved merge conflicts: a 1.0.0 (HEAD) vs 1.1.0 (feature), b missing (HEAD) vs 2.0.0 (feature)"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestParseDependencyFileWith_Conflict(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		file    string
+		content string
+		changes []VersionChange
+		ours    int // Dependencies kept in tolerant mode
+	}{
+		{
+			file:    "package.json",
+			content: conflictedPackageJSON,
+			changes: []VersionChange{{Name: "lodash", Ours: "^4.17.21", Theirs: "^4.17.15"}},
+			ours:    2,
+		},
+		{
+			file:    "pubspec.yaml",
+			content: "name: x\ndependencies:\n<<<<<<< HEAD\n  http: ^1.1.0\n=======\n  http: ^1.0.0\n  path: ^1.8.0\n>>>>>>> feature\n",
+			changes: []VersionChange{{Name: "http", Ours: "^1.1.0", Theirs: "^1.0.0"}, {Name: "path", Theirs: "^1.8.0"}},
+			ours:    1,
+		},
+		{
+			file:    "go.mod",
+			content: "module example.com/x\n\nrequire (\n<<<<<<< HEAD\n\tgithub.com/pkg/errors v0.9.1\n=======\n\tgithub.com/pkg/errors v0.8.0\n>>>>>>> feature\n)\n",
+			changes: []VersionChange{{Name: "github.com/pkg/errors", Ours: "v0.9.1", Theirs: "v0.8.0"}},
+			ours:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			depFile := ParseDependencyFileWith(context.Background(), path, Options{})
+			conflict, ok := depFile.Err.(*ConflictError)
+			if !ok {
+				t.Fatalf("Err = %v, want a ConflictError", depFile.Err)
+			}
+			if !reflect.DeepEqual(conflict.Changes, tt.changes) {
+				t.Errorf("Changes = %+v, want %+v", conflict.Changes, tt.changes)
+			}
+			if len(depFile.Dependencies) != 0 {
+				t.Errorf("Dependencies = %+v, want none", depFile.Dependencies)
+			}
+			if d, _ := depFile.Diagnostic(); d.Code != diagnostic.CodeConflict || d.Severity != diagnostic.Error || d.Line != conflict.Line {
+				t.Errorf("Diagnostic() = %+v, want a merge-conflict error at line %d", d, conflict.Line)
+			}
+
+			tolerant := ParseDependencyFileWith(context.Background(), path, Options{Tolerant: true})
+			if !tolerant.Partial || len(tolerant.Dependencies) != tt.ours {
+				t.Errorf("tolerant = %+v, want %d dependencies flagged as partial", tolerant, tt.ours)
+			}
+			if d, _ := tolerant.Diagnostic(); d.Code != diagnostic.CodeConflict || d.Severity != diagnostic.Warning {
+				t.Errorf("tolerant Diagnostic() = %+v, want a merge-conflict warning", d)
+			}
+		})
+	}
+}
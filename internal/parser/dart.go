@@ -2,13 +2,18 @@ package parser
 
 import (
 	"sort"
+	"strings"
 
+	"github.com/flarebyte/clingy-code-detective/internal/location"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
 	"gopkg.in/yaml.v3"
 )
 
 type dartParser struct{}
 
 type pubspecYAML struct {
+	Name            string                 `yaml:"name"`
+	Version         string                 `yaml:"version"`
 	Dependencies    map[string]interface{} `yaml:"dependencies"`
 	DevDependencies map[string]interface{} `yaml:"dev_dependencies"`
 }
@@ -18,8 +23,9 @@ func (p dartParser) Parse(content []byte) ([]Dependency, error) {
 	if err := yaml.Unmarshal(content, &spec); err != nil {
 		return nil, err
 	}
+	positions := location.YAML(content)
 
-	parseMap := func(m map[string]interface{}, cat string) []Dependency {
+	parseMap := func(section string, m map[string]interface{}, cat string) []Dependency {
 		var deps []Dependency
 		keys := make([]string, 0, len(m))
 		for k := range m {
@@ -28,20 +34,69 @@ func (p dartParser) Parse(content []byte) ([]Dependency, error) {
 		sort.Strings(keys)
 
 		for _, name := range keys {
-			val := m[name]
-			switch v := val.(type) {
+			pos := positions.Of(section, name)
+			dep := Dependency{Name: name, Category: cat, Line: pos.Line, Column: pos.Column}
+			switch v := m[name].(type) {
 			case string:
-				deps = append(deps, Dependency{Name: name, Version: v, Category: cat})
-			default:
-				deps = append(deps, Dependency{Name: name, Version: "", Category: cat})
+				dep.Version = v
+			case map[string]interface{}:
+				if localPath, ok := v["path"].(string); ok {
+					dep.LocalPath = localPath
+				}
 			}
+			deps = append(deps, dep)
 		}
 		return deps
 	}
 
 	var deps []Dependency
-	deps = append(deps, parseMap(spec.Dependencies, "prod")...)
-	deps = append(deps, parseMap(spec.DevDependencies, "dev")...)
+	deps = append(deps, parseMap("dependencies", spec.Dependencies, "prod")...)
+	deps = append(deps, parseMap("dev_dependencies", spec.DevDependencies, "dev")...)
 
 	return deps, nil
 }
+
+// ReadIdentity reads the name and version of the package.
+func (p dartParser) ReadIdentity(content []byte) (ecosystem.Identity, error) {
+	var spec pubspecYAML
+	if err := yaml.Unmarshal(content, &spec); err != nil {
+		return ecosystem.Identity{}, err
+	}
+	return ecosystem.Identity{Name: spec.Name, Version: spec.Version}, nil
+}
+
+// Recover parses pubspec.yaml files with merge conflicts, keeping our side,
+// and falls back to a line by line scan of the dependency sections.
+func (p dartParser) Recover(content []byte) ([]Dependency, error) {
+	if deps, err := p.Parse(resolveConflicts(content, keepOurs)); err == nil {
+		return deps, nil
+	}
+	entries := scanYAMLSections(resolveConflicts(content, keepOurs), "dependencies", "dev_dependencies")
+	return recoveredDependencies(entries, map[string]string{"dependencies": "prod", "dev_dependencies": "dev"}), nil
+}
+
+// pubspecLock is a pubspec.lock file, which lists the installed packages
+// without the dependencies between them.
+type pubspecLock struct {
+	Packages map[string]struct {
+		Dependency string `yaml:"dependency"` // e.g. "direct main" or "transitive"
+		Version    string `yaml:"version"`
+	} `yaml:"packages"`
+}
+
+// ReadLockfile reads a pubspec.lock, whose direct dependencies are marked.
+func (p dartParser) ReadLockfile(_ string, content []byte, _ []Dependency) (ecosystem.Resolution, error) {
+	var lock pubspecLock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return ecosystem.Resolution{}, err
+	}
+	var roots []string
+	packages := make(map[string]ecosystem.ResolvedPackage, len(lock.Packages))
+	for name, pkg := range lock.Packages {
+		packages[name] = ecosystem.ResolvedPackage{Name: name, Version: pkg.Version}
+		if strings.HasPrefix(pkg.Dependency, "direct") {
+			roots = append(roots, name)
+		}
+	}
+	return newResolution(roots, packages, true), nil
+}
@@ -25,6 +25,11 @@ dev_dependencies:
 dependencies:
   flutter:
     sdk: flutter
+`
+	yamlPathDependency = `
+dependencies:
+  acme_ui:
+    path: ../packages/acme_ui
 `
 	yamlInvalid = `dependencies: [`
 )
@@ -40,30 +45,37 @@ func Test_dartParser_Parse(t *testing.T) {
 			name:  "only prod deps",
 			input: yamlOnlyProd,
 			want: []Dependency{
-				{Name: "http", Version: "^0.13.3", Category: "prod"},
-				{Name: "path", Version: "^1.8.0", Category: "prod"},
+				{Name: "http", Version: "^0.13.3", Category: "prod", Line: 3, Column: 3},
+				{Name: "path", Version: "^1.8.0", Category: "prod", Line: 4, Column: 3},
 			},
 		},
 		{
 			name:  "only dev deps",
 			input: yamlOnlyDev,
 			want: []Dependency{
-				{Name: "test", Version: "^1.16.0", Category: "dev"},
+				{Name: "test", Version: "^1.16.0", Category: "dev", Line: 3, Column: 3},
 			},
 		},
 		{
 			name:  "prod and dev deps",
 			input: yamlProdAndDev,
 			want: []Dependency{
-				{Name: "http", Version: "^0.13.3", Category: "prod"},
-				{Name: "test", Version: "^1.16.0", Category: "dev"},
+				{Name: "http", Version: "^0.13.3", Category: "prod", Line: 3, Column: 3},
+				{Name: "test", Version: "^1.16.0", Category: "dev", Line: 5, Column: 3},
 			},
 		},
 		{
 			name:  "complex dependency version",
 			input: yamlComplexVersion,
 			want: []Dependency{
-				{Name: "flutter", Version: "", Category: "prod"},
+				{Name: "flutter", Version: "", Category: "prod", Line: 3, Column: 3},
+			},
+		},
+		{
+			name:  "path dependency",
+			input: yamlPathDependency,
+			want: []Dependency{
+				{Name: "acme_ui", Category: "prod", Line: 3, Column: 3, LocalPath: "../packages/acme_ui"},
 			},
 		},
 		{
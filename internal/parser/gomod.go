@@ -3,20 +3,30 @@ package parser
 import (
 	"bufio"
 	"bytes"
+	"path/filepath"
+	"strconv"
 	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
 )
 
 type goModParser struct{}
 
 // Parse extracts dependencies from a go.mod file. It identifies "prod" or "dev"
 // based on the presence of "// indirect" comments in the require block.
+// Modules replaced by a local directory get its path as LocalPath.
 func (p goModParser) Parse(content []byte) ([]Dependency, error) {
 	deps := make([]Dependency, 0)
 	scanner := bufio.NewScanner(bytes.NewReader(content))
 
 	inRequireBlock := false
+	inReplaceBlock := false
+	replaced := make(map[string]string)
+	lineNumber := 0
 
 	for scanner.Scan() {
+		lineNumber++
 		rawLine := scanner.Text()
 		line := strings.TrimSpace(rawLine)
 
@@ -28,6 +38,20 @@ func (p goModParser) Parse(content []byte) ([]Dependency, error) {
 			inRequireBlock = false
 			continue
 		}
+		if strings.HasPrefix(line, "replace (") {
+			inReplaceBlock = true
+			continue
+		}
+		if inReplaceBlock && line == ")" {
+			inReplaceBlock = false
+			continue
+		}
+		if inReplaceBlock || strings.HasPrefix(line, "replace ") {
+			if module, dir, ok := localReplacement(strings.TrimPrefix(line, "replace ")); ok {
+				replaced[module] = dir
+			}
+			continue
+		}
 
 		var depLine string
 		if inRequireBlock {
@@ -55,8 +79,107 @@ func (p goModParser) Parse(content []byte) ([]Dependency, error) {
 			Name:     fields[0],
 			Version:  fields[1],
 			Category: category,
+			Line:     lineNumber,
+			Column:   strings.Index(rawLine, fields[0]) + 1,
 		})
 	}
 
+	for i := range deps {
+		deps[i].LocalPath = replaced[deps[i].Name]
+	}
 	return deps, scanner.Err()
 }
+
+// localReplacement parses a replace directive such as
+// "example.com/lib => ../lib", returning the module and the directory
+// replacing it, or false when it is replaced by another module.
+func localReplacement(directive string) (string, string, bool) {
+	if idx := strings.Index(directive, "//"); idx != -1 {
+		directive = directive[:idx]
+	}
+	old, replacement, found := strings.Cut(directive, "=>")
+	oldFields, newFields := strings.Fields(old), strings.Fields(replacement)
+	if !found || len(oldFields) == 0 || len(newFields) != 1 {
+		return "", "", false
+	}
+	dir := newFields[0]
+	if !strings.HasPrefix(dir, "./") && !strings.HasPrefix(dir, "../") && !filepath.IsAbs(dir) {
+		return "", "", false
+	}
+	return oldFields[0], dir, true
+}
+
+// ReadIdentity reads the path of the module, which has no version.
+func (p goModParser) ReadIdentity(content []byte) (ecosystem.Identity, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(line, "//"); idx != -1 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		module, found := strings.CutPrefix(line, "module ")
+		if !found {
+			continue
+		}
+		module = strings.TrimSpace(module)
+		if unquoted, err := strconv.Unquote(module); err == nil {
+			module = unquoted
+		}
+		return ecosystem.Identity{Name: module}, nil
+	}
+	return ecosystem.Identity{}, scanner.Err()
+}
+
+// ReadLockfile reads a go.sum, which lists the modules whose content was
+// downloaded without the dependencies between them. As the minimal version
+// selection of Go builds a single version of each module, the versions of
+// go.sum which go.mod no longer requires are left out, and only the highest
+// version is kept for modules go.mod does not list. The direct dependencies
+// are the modules required by go.mod without an // indirect comment.
+func (p goModParser) ReadLockfile(_ string, content []byte, declared []Dependency) (ecosystem.Resolution, error) {
+	required := make(map[string]string, len(declared))
+	for _, d := range declared {
+		required[d.Name] = d.Version
+	}
+	selected := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Lines ending with /go.mod only hash the go.mod of the version
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		module, version := fields[0], fields[1]
+		if want, ok := required[module]; ok {
+			if version == want {
+				selected[module] = version
+			}
+		} else if current, ok := selected[module]; !ok || compareGoVersions(version, current) > 0 {
+			selected[module] = version
+		}
+	}
+	packages := make(map[string]ecosystem.ResolvedPackage, len(selected))
+	for module, version := range selected {
+		packages[module+"@"+version] = ecosystem.ResolvedPackage{Name: module, Version: version}
+	}
+	var roots []string
+	for _, d := range declared {
+		if id := d.Name + "@" + d.Version; d.Category == "prod" {
+			if _, ok := packages[id]; ok {
+				roots = append(roots, id)
+			}
+		}
+	}
+	return newResolution(roots, packages, true), scanner.Err()
+}
+
+// compareGoVersions compares two module versions, in lexical order when
+// either is not a semantic version.
+func compareGoVersions(a, b string) int {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return va.Compare(vb)
+}
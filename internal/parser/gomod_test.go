@@ -25,6 +25,23 @@ require (
 	github.com/gin-gonic/gin v1.8.1
 	github.com/pkg/errors v0.9.1 // indirect
 )
+`
+
+	goModWithLocalReplace = `
+module example.com/app
+
+require (
+	example.com/lib v0.0.0
+	example.com/tools v1.2.0
+	example.com/forked v1.0.0
+)
+
+replace example.com/lib => ../lib
+
+replace (
+	example.com/tools v1.2.0 => ./tools // vendored
+	example.com/forked => example.com/fork v1.0.1
+)
 `
 
 	goModWithComments = `
@@ -69,6 +86,8 @@ func Test_goModParser_Parse(t *testing.T) {
 					Name:     "github.com/stretchr/testify",
 					Version:  "v1.7.0",
 					Category: "prod",
+					Line:     6,
+					Column:   9,
 				},
 			},
 			wantErr: false,
@@ -83,15 +102,30 @@ func Test_goModParser_Parse(t *testing.T) {
 					Name:     "github.com/gin-gonic/gin",
 					Version:  "v1.8.1",
 					Category: "prod",
+					Line:     7,
+					Column:   2,
 				},
 				{
 					Name:     "github.com/pkg/errors",
 					Version:  "v0.9.1",
 					Category: "dev",
+					Line:     8,
+					Column:   2,
 				},
 			},
 			wantErr: false,
 		},
+		{
+			name: "Modules replaced by local directories",
+			args: args{
+				content: []byte(goModWithLocalReplace),
+			},
+			want: []Dependency{
+				{Name: "example.com/lib", Version: "v0.0.0", Category: "prod", Line: 5, Column: 2, LocalPath: "../lib"},
+				{Name: "example.com/tools", Version: "v1.2.0", Category: "prod", Line: 6, Column: 2, LocalPath: "./tools"},
+				{Name: "example.com/forked", Version: "v1.0.0", Category: "prod", Line: 7, Column: 2},
+			},
+		},
 		{
 			name: "Require block with comments and whitespace",
 			args: args{
@@ -102,11 +136,15 @@ func Test_goModParser_Parse(t *testing.T) {
 					Name:     "github.com/labstack/echo/v4",
 					Version:  "v4.9.0",
 					Category: "prod",
+					Line:     6,
+					Column:   2,
 				},
 				{
 					Name:     "golang.org/x/sys",
 					Version:  "v0.15.0",
 					Category: "dev",
+					Line:     9,
+					Column:   2,
 				},
 			},
 			wantErr: false,
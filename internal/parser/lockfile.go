@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+// ErrUnsupportedLockfile is returned for lockfiles the parser of their
+// ecosystem cannot read.
+var ErrUnsupportedLockfile = errors.New("unsupported lockfile")
+
+// ReadLockfile reads the lockfile of a project with the parser of its
+// ecosystem. The dependencies declared by its manifests give the direct
+// dependencies of lockfiles which do not record them. Errors wrap
+// ErrUnsupportedLockfile, ErrRead, ErrFileTooLarge or a ParseError, like
+// those of DependencyFile. Lockfiles with merge conflict blocks fail with a
+// ConflictError, along with the resolution of the current side in tolerant
+// mode.
+func ReadLockfile(project Project, declared []Dependency, opts Options) (ecosystem.Resolution, error) {
+	read := opts.Read
+	if read == nil {
+		read = os.ReadFile
+	}
+
+	eco, ok := ecosystem.Lookup(project.Ecosystem)
+	if !ok {
+		return ecosystem.Resolution{}, fmt.Errorf("%w: unknown ecosystem %q", ErrUnsupportedLockfile, project.Ecosystem)
+	}
+	reader, ok := eco.NewParser().(ecosystem.LockfileReader)
+	if !ok {
+		return ecosystem.Resolution{}, fmt.Errorf("%w: %s lockfiles cannot be read", ErrUnsupportedLockfile, eco.Name)
+	}
+
+	content, err := read(project.Lockfile)
+	if err != nil {
+		if !errors.Is(err, ErrFileTooLarge) {
+			err = fmt.Errorf("%w: %w", ErrRead, err)
+		}
+		return ecosystem.Resolution{}, err
+	}
+	if conflict, ok := findConflict(content); ok {
+		ours, err := readConflict(reader, project.Lockfile, content, declared, conflict)
+		if opts.Tolerant && err == nil {
+			return ours, conflict
+		}
+		return ecosystem.Resolution{}, conflict
+	}
+	resolution, err := reader.ReadLockfile(project.Lockfile, content, declared)
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedLockfile) {
+			return ecosystem.Resolution{}, err
+		}
+		return ecosystem.Resolution{}, newParseError(content, err)
+	}
+	return resolution, nil
+}
+
+// lockfileConflict returns the diagnostic of the lockfile of a project with
+// merge conflict blocks, so that scans which do not read lockfiles still
+// report them. Other lockfile errors are left to ReadLockfile.
+func lockfileConflict(project Project, declared []Dependency, opts Options) *diagnostic.Diagnostic {
+	if project.Lockfile == "" {
+		return nil
+	}
+	read := opts.Read
+	if read == nil {
+		read = os.ReadFile
+	}
+	content, err := read(project.Lockfile)
+	if err != nil {
+		return nil
+	}
+	if _, ok := findConflict(content); !ok {
+		return nil
+	}
+	resolution, err := ReadLockfile(project, declared, opts)
+	d := LockfileDiagnostic(project.Lockfile, err, resolution.Packages != nil)
+	return &d
+}
+
+// readConflict reads both sides of a lockfile with conflict blocks, listing
+// the packages installed in other versions by the incoming side, and returns
+// the resolution of the current side.
+func readConflict(reader ecosystem.LockfileReader, path string, content []byte, declared []Dependency, conflict *ConflictError) (ecosystem.Resolution, error) {
+	ours, err := reader.ReadLockfile(path, resolveConflicts(content, keepOurs), declared)
+	theirs, _ := reader.ReadLockfile(path, resolveConflicts(content, keepTheirs), declared)
+	conflict.Changes = versionChanges(installedVersions(ours), installedVersions(theirs))
+	return ours, err
+}
+
+// installedVersions returns a dependency per package name of a resolution,
+// whose version lists the installed versions.
+func installedVersions(r ecosystem.Resolution) []Dependency {
+	versions := make(map[string][]string)
+	for _, pkg := range r.Packages {
+		if !slices.Contains(versions[pkg.Name], pkg.Version) {
+			versions[pkg.Name] = append(versions[pkg.Name], pkg.Version)
+		}
+	}
+	deps := make([]Dependency, 0, len(versions))
+	for name, v := range versions {
+		slices.Sort(v)
+		deps = append(deps, Dependency{Name: name, Version: strings.Join(v, " and ")})
+	}
+	return deps
+}
+
+// ReadLockfile reads package-lock.json, npm-shrinkwrap.json, yarn.lock,
+// pnpm-lock.yaml and bun.lock files.
+func (p nodeParser) ReadLockfile(path string, content []byte, declared []Dependency) (ecosystem.Resolution, error) {
+	switch name := strings.ToLower(filepath.Base(path)); name {
+	case "package-lock.json", "npm-shrinkwrap.json":
+		return readPackageLock(content, declared)
+	case "yarn.lock":
+		return readYarnLock(content, declared)
+	case "pnpm-lock.yaml":
+		return readPnpmLock(content)
+	case "bun.lock":
+		return readBunLock(content)
+	default:
+		return ecosystem.Resolution{}, fmt.Errorf("%w: %s", ErrUnsupportedLockfile, name)
+	}
+}
+
+// newResolution returns the resolution of the packages, sorted by ID, with
+// sorted roots and dependencies. Duplicated IDs are removed.
+func newResolution(roots []string, packages map[string]ecosystem.ResolvedPackage, unlinked bool) ecosystem.Resolution {
+	resolution := ecosystem.Resolution{
+		Roots:    sortedIDs(roots),
+		Packages: make([]ecosystem.ResolvedPackage, 0, len(packages)),
+		Unlinked: unlinked,
+	}
+	for id, pkg := range packages {
+		pkg.ID = id
+		pkg.Dependencies = sortedIDs(pkg.Dependencies)
+		resolution.Packages = append(resolution.Packages, pkg)
+	}
+	sort.Slice(resolution.Packages, func(i, j int) bool { return resolution.Packages[i].ID < resolution.Packages[j].ID })
+	return resolution
+}
+
+func sortedIDs(ids []string) []string {
+	sorted := slices.Clone(ids)
+	sort.Strings(sorted)
+	return slices.Compact(sorted)
+}
+
+// packageSpecName returns the name of a package specification, e.g. @a/b for
+// "@a/b@^1.0.0", and the rest after the @.
+func packageSpecName(spec string) (string, string) {
+	if i := strings.Index(spec[min(1, len(spec)):], "@"); i >= 0 {
+		return spec[:i+1], spec[i+2:]
+	}
+	return spec, ""
+}
@@ -0,0 +1,447 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+const (
+	packageLockV3 = `{
+  "name": "app", "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"express": "^4.18.0"}, "devDependencies": {"jest": "^29.0.0"}},
+    "node_modules/express": {"version": "4.18.2", "dependencies": {"qs": "6.11.0", "missing": "1.0.0"}},
+    "node_modules/qs": {"version": "6.11.0"},
+    "node_modules/jest": {"version": "29.0.0", "dev": true, "dependencies": {"qs": "^5.0.0", "ui": "*"}},
+    "node_modules/jest/node_modules/qs": {"version": "5.2.1", "dev": true},
+    "node_modules/ui": {"resolved": "packages/ui", "link": true},
+    "packages/ui": {"name": "ui", "version": "1.0.0", "dependencies": {"qs": "^6.0.0"}}
+  }
+}`
+
+	packageLockV1 = `{"name": "old", "lockfileVersion": 1, "dependencies": {
+  "a": {"version": "1.0.0", "requires": {"b": "^2.0.0"}, "dependencies": {"b": {"version": "2.0.0"}}},
+  "b": {"version": "3.0.0"}
+}}`
+
+	yarnLockV1 = `# yarn lockfile v1
+
+
+"@babel/code-frame@^7.0.0", "@babel/code-frame@^7.10.4":
+  version "7.12.13"
+  resolved "https://registry.yarnpkg.com/@babel/code-frame/-/code-frame-7.12.13.tgz"
+  dependencies:
+    "@babel/highlight" "^7.12.13"
+
+"@babel/highlight@^7.12.13":
+  version "7.13.10"
+  dependencies:
+    js-tokens "^3.0.0"
+
+js-tokens@^3.0.0:
+  version "3.0.2"
+
+js-tokens@^4.0.0:
+  version "4.0.0"
+`
+
+	yarnLockBerry = `__metadata:
+  version: 6
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "app@workspace:."
+  dependencies:
+    lodash: ^4.17.21
+    ui: "workspace:^"
+
+"lodash@npm:^4.17.20, lodash@npm:^4.17.21":
+  version: 4.17.21
+  resolution: "lodash@npm:4.17.21"
+
+"ui@workspace:^, ui@workspace:packages/ui":
+  version: 0.0.0-use.local
+  resolution: "ui@workspace:packages/ui"
+  dependencies:
+    lodash: "npm:^4.17.20"
+`
+
+	pnpmLockV9 = `lockfileVersion: '9.0'
+
+importers:
+  .:
+    dependencies:
+      react-dom:
+        specifier: ^18.2.0
+        version: 18.2.0(react@18.2.0)
+      ui:
+        specifier: workspace:*
+        version: link:packages/ui
+  packages/ui:
+    dependencies:
+      react:
+        specifier: ^18.2.0
+        version: 18.2.0
+
+packages:
+  react-dom@18.2.0:
+    resolution: {integrity: sha512-b}
+  react@18.2.0:
+    resolution: {integrity: sha512-c}
+
+snapshots:
+  react-dom@18.2.0(react@18.2.0):
+    dependencies:
+      react: 18.2.0
+  react@18.2.0: {}
+`
+
+	pnpmLockV6 = `lockfileVersion: '6.0'
+
+dependencies:
+  react:
+    specifier: ^18.2.0
+    version: 18.2.0
+
+packages:
+  /loose-envify@1.4.0:
+    resolution: {integrity: sha512-b}
+  /react@18.2.0:
+    resolution: {integrity: sha512-c}
+    dependencies:
+      loose-envify: 1.4.0
+`
+
+	pnpmLockV5 = `lockfileVersion: 5.4
+
+dependencies:
+  react: 18.2.0_typescript@5.0.0
+
+packages:
+  /loose-envify/1.4.0:
+    resolution: {integrity: sha512-b}
+  /react/18.2.0_typescript@5.0.0:
+    resolution: {integrity: sha512-c}
+    dependencies:
+      loose-envify: 1.4.0
+`
+
+	bunLockContent = `{
+  "lockfileVersion": 1,
+  "workspaces": {
+    "": {
+      "name": "app",
+      "dependencies": {
+        "a": "^1.0.0",
+        "@s/b": "^2.0.0",
+      },
+    },
+  },
+  "packages": {
+    "a": ["a@1.0.0", "", { "dependencies": { "@s/b": "^1.0.0" } }, "sha512-a"],
+    "@s/b": ["@s/b@2.0.0", "", {}, "sha512-b"],
+    "a/@s/b": ["@s/b@1.0.0", "", {}, "sha512-c"],
+  }
+}`
+
+	pubspecLockContent = `packages:
+  http:
+    dependency: "direct main"
+    source: hosted
+    version: "1.1.0"
+  meta:
+    dependency: transitive
+    source: hosted
+    version: "1.9.1"
+  test:
+    dependency: "direct dev"
+    source: hosted
+    version: "1.24.3"
+`
+
+	goSumContent = `github.com/pkg/errors v0.9.1 h1:abc=
+github.com/pkg/errors v0.9.1/go.mod h1:def=
+golang.org/x/text v0.3.0 h1:old=
+golang.org/x/text v0.13.0/go.mod h1:x=
+golang.org/x/text v0.14.0 h1:y=
+gopkg.in/yaml.v3 v3.0.0 h1:a=
+gopkg.in/yaml.v3 v3.0.1 h1:b=
+`
+)
+
+// resolutionEdges returns the roots of a resolution and the dependencies of
+// each package, as name@version.
+func resolutionEdges(r ecosystem.Resolution) ([]string, map[string][]string) {
+	labels := make(map[string]string)
+	for _, pkg := range r.Packages {
+		labels[pkg.ID] = pkg.Name + "@" + pkg.Version
+	}
+	label := func(ids []string) []string {
+		var result []string
+		for _, id := range ids {
+			result = append(result, labels[id])
+		}
+		sort.Strings(result)
+		return result
+	}
+	edges := make(map[string][]string)
+	for _, pkg := range r.Packages {
+		edges[labels[pkg.ID]] = label(pkg.Dependencies)
+	}
+	return label(r.Roots), edges
+}
+
+func TestReadLockfile_Formats(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		content   string
+		declared  []Dependency
+		wantRoots []string
+		wantEdges map[string][]string
+		unlinked  bool
+	}{
+		{
+			name:      "package-lock.json v3 with a workspace",
+			path:      "app/package-lock.json",
+			content:   packageLockV3,
+			wantRoots: []string{"express@4.18.2", "jest@29.0.0"},
+			wantEdges: map[string][]string{
+				"express@4.18.2": {"qs@6.11.0"},
+				"qs@6.11.0":      nil,
+				"jest@29.0.0":    {"qs@5.2.1", "ui@1.0.0"},
+				"qs@5.2.1":       nil,
+				"ui@1.0.0":       {"qs@6.11.0"},
+			},
+		},
+		{
+			name:      "npm-shrinkwrap.json v1 nesting its packages",
+			path:      "npm-shrinkwrap.json",
+			content:   packageLockV1,
+			declared:  []Dependency{{Name: "a", Version: "^1.0.0"}},
+			wantRoots: []string{"a@1.0.0"},
+			wantEdges: map[string][]string{"a@1.0.0": {"b@2.0.0"}, "b@2.0.0": nil, "b@3.0.0": nil},
+		},
+		{
+			name:      "yarn.lock v1 rooted in the declared dependencies",
+			path:      "yarn.lock",
+			content:   yarnLockV1,
+			declared:  []Dependency{{Name: "@babel/code-frame", Version: "^7.10.4"}, {Name: "js-tokens", Version: "^4.0.0"}},
+			wantRoots: []string{"@babel/code-frame@7.12.13", "js-tokens@4.0.0"},
+			wantEdges: map[string][]string{
+				"@babel/code-frame@7.12.13": {"@babel/highlight@7.13.10"},
+				"@babel/highlight@7.13.10":  {"js-tokens@3.0.2"},
+				"js-tokens@3.0.2":           nil,
+				"js-tokens@4.0.0":           nil,
+			},
+		},
+		{
+			name:      "yarn.lock v2+ rooted in the root workspace",
+			path:      "yarn.lock",
+			content:   yarnLockBerry,
+			wantRoots: []string{"lodash@4.17.21", "ui@0.0.0-use.local"},
+			wantEdges: map[string][]string{"lodash@4.17.21": nil, "ui@0.0.0-use.local": {"lodash@4.17.21"}},
+		},
+		{
+			name:      "pnpm-lock.yaml v9 with snapshots and a linked workspace",
+			path:      "pnpm-lock.yaml",
+			content:   pnpmLockV9,
+			wantRoots: []string{"react-dom@18.2.0", "ui@link:packages/ui"},
+			wantEdges: map[string][]string{
+				"react-dom@18.2.0":    {"react@18.2.0"},
+				"react@18.2.0":        nil,
+				"ui@link:packages/ui": {"react@18.2.0"},
+			},
+		},
+		{
+			name:      "pnpm-lock.yaml v6",
+			path:      "pnpm-lock.yaml",
+			content:   pnpmLockV6,
+			wantRoots: []string{"react@18.2.0"},
+			wantEdges: map[string][]string{"react@18.2.0": {"loose-envify@1.4.0"}, "loose-envify@1.4.0": nil},
+		},
+		{
+			name:      "pnpm-lock.yaml v5 with peer suffixes",
+			path:      "pnpm-lock.yaml",
+			content:   pnpmLockV5,
+			wantRoots: []string{"react@18.2.0"},
+			wantEdges: map[string][]string{"react@18.2.0": {"loose-envify@1.4.0"}, "loose-envify@1.4.0": nil},
+		},
+		{
+			name:      "bun.lock with a nested copy",
+			path:      "bun.lock",
+			content:   bunLockContent,
+			wantRoots: []string{"@s/b@2.0.0", "a@1.0.0"},
+			wantEdges: map[string][]string{"a@1.0.0": {"@s/b@1.0.0"}, "@s/b@1.0.0": nil, "@s/b@2.0.0": nil},
+		},
+		{
+			name:      "pubspec.lock without the dependencies between packages",
+			path:      "pubspec.lock",
+			content:   pubspecLockContent,
+			wantRoots: []string{"http@1.1.0", "test@1.24.3"},
+			wantEdges: map[string][]string{"http@1.1.0": nil, "meta@1.9.1": nil, "test@1.24.3": nil},
+			unlinked:  true,
+		},
+		{
+			name:    "go.sum rooted in the direct requirements",
+			path:    "go.sum",
+			content: goSumContent,
+			declared: []Dependency{
+				{Name: "github.com/pkg/errors", Version: "v0.9.1", Category: "prod"},
+				{Name: "golang.org/x/text", Version: "v0.14.0", Category: "dev"},
+			},
+			wantRoots: []string{"github.com/pkg/errors@v0.9.1"},
+			// Only the versions selected by go.mod, or the highest one of
+			// modules it does not list, are installed
+			wantEdges: map[string][]string{"github.com/pkg/errors@v0.9.1": nil, "golang.org/x/text@v0.14.0": nil, "gopkg.in/yaml.v3@v3.0.1": nil},
+			unlinked:  true,
+		},
+	}
+
+	ecosystems := map[string]string{"pubspec.lock": "dart", "go.sum": "go"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eco := ecosystems[tt.path]
+			if eco == "" {
+				eco = "node"
+			}
+			project := Project{Ecosystem: eco, Lockfile: tt.path}
+			read := func(string) ([]byte, error) { return []byte(tt.content), nil }
+
+			got, err := ReadLockfile(project, tt.declared, Options{Read: read})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			roots, edges := resolutionEdges(got)
+			if !reflect.DeepEqual(roots, tt.wantRoots) {
+				t.Errorf("roots = %v, want %v", roots, tt.wantRoots)
+			}
+			if !reflect.DeepEqual(edges, tt.wantEdges) {
+				t.Errorf("edges = %v, want %v", edges, tt.wantEdges)
+			}
+			if got.Unlinked != tt.unlinked {
+				t.Errorf("Unlinked = %v, want %v", got.Unlinked, tt.unlinked)
+			}
+		})
+	}
+}
+
+func TestReadLockfile_Errors(t *testing.T) {
+	content := func(s string) ContentReader {
+		return func(string) ([]byte, error) { return []byte(s), nil }
+	}
+	failing := func(string) ([]byte, error) { return nil, errors.New("boom") }
+
+	_, err := ReadLockfile(Project{Ecosystem: "python", Lockfile: "poetry.lock"}, nil, Options{Read: content("")})
+	if !errors.Is(err, ErrUnsupportedLockfile) {
+		t.Errorf("python lockfile: err = %v, want ErrUnsupportedLockfile", err)
+	}
+	_, err = ReadLockfile(Project{Ecosystem: "node", Lockfile: "shrinkwrap.yaml"}, nil, Options{Read: content("")})
+	if !errors.Is(err, ErrUnsupportedLockfile) {
+		t.Errorf("unknown node lockfile: err = %v, want ErrUnsupportedLockfile", err)
+	}
+	_, err = ReadLockfile(Project{Ecosystem: "node", Lockfile: "package-lock.json"}, nil, Options{Read: failing})
+	if !errors.Is(err, ErrRead) {
+		t.Errorf("unreadable lockfile: err = %v, want ErrRead", err)
+	}
+
+	_, err = ReadLockfile(Project{Ecosystem: "node", Lockfile: "package-lock.json"}, nil, Options{Read: content("{\n  \"packages\": [\n}")})
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 3 {
+		t.Errorf("malformed lockfile: err = %v, want a ParseError on line 3", err)
+	}
+	if d := LockfileDiagnostic("package-lock.json", err, false); d.Code != "parse-error" || d.Line != 3 {
+		t.Errorf("LockfileDiagnostic = %+v, want a parse-error on line 3", d)
+	}
+}
+
+func TestReadLockfile_Conflict(t *testing.T) {
+	content := `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"qs": "^6.0.0", "ms": "^2.0.0"}},
+<<<<<<< HEAD
+    "node_modules/qs": {"version": "6.11.0"},
+=======
+    "node_modules/qs": {"version": "6.12.1"},
+>>>>>>> renovate/qs
+    "node_modules/ms": {"version": "2.1.3"}
+  }
+}`
+	project := Project{Ecosystem: "node", Lockfile: "package-lock.json"}
+	read := func(string) ([]byte, error) { return []byte(content), nil }
+
+	resolution, err := ReadLockfile(project, nil, Options{Read: read})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || resolution.Packages != nil {
+		t.Fatalf("err = %v, resolution = %+v, want a ConflictError only", err, resolution)
+	}
+	want := []VersionChange{{Name: "qs", Ours: "6.11.0", Theirs: "6.12.1"}}
+	if !reflect.DeepEqual(conflict.Changes, want) {
+		t.Errorf("Changes = %+v, want %+v", conflict.Changes, want)
+	}
+	d := LockfileDiagnostic("package-lock.json", err, false)
+	if d.Code != diagnostic.CodeConflict || d.Severity != diagnostic.Error || d.Line != 5 {
+		t.Errorf("LockfileDiagnostic = %+v, want a conflict error on line 5", d)
+	}
+	if want := "qs 6.11.0 (HEAD) vs 6.12.1 (renovate/qs)"; !strings.Contains(d.Message, want) {
+		t.Errorf("message %q does not contain %q", d.Message, want)
+	}
+
+	resolution, err = ReadLockfile(project, nil, Options{Read: read, Tolerant: true})
+	if !errors.As(err, &conflict) || len(resolution.Packages) != 2 {
+		t.Fatalf("tolerant: err = %v, resolution = %+v, want the current side", err, resolution)
+	}
+	if d := LockfileDiagnostic("package-lock.json", err, true); d.Severity != diagnostic.Warning {
+		t.Errorf("tolerant LockfileDiagnostic = %+v, want a warning", d)
+	}
+}
+
+func TestParseDependencyFileWith_LockfileConflict(t *testing.T) {
+	files := map[string]string{
+		"app/package.json": `{"dependencies": {"qs": "^6.0.0"}}`,
+		"app/package-lock.json": `{
+  "lockfileVersion": 3,
+  "packages": {
+<<<<<<< HEAD
+    "node_modules/qs": {"version": "6.11.0"}
+=======
+    "node_modules/qs": {"version": "6.12.1"}
+>>>>>>> renovate/qs
+  }
+}`,
+	}
+	opts := Options{
+		Read:   func(path string) ([]byte, error) { return []byte(files[path]), nil },
+		Exists: func(path string) bool { _, ok := files[path]; return ok },
+	}
+
+	file := ParseDependencyFileWith(context.Background(), "app/package.json", opts)
+	if file.Err != nil || len(file.Dependencies) != 1 {
+		t.Fatalf("ParseDependencyFileWith() = %+v, want the manifest parsed", file)
+	}
+	d := file.LockfileConflict
+	if d == nil || d.Code != diagnostic.CodeConflict || d.Path != "app/package-lock.json" || d.Line != 4 {
+		t.Errorf("LockfileConflict = %+v, want a conflict of app/package-lock.json on line 4", d)
+	}
+
+	files["app/package-lock.json"] = `{"lockfileVersion": 3, "packages": {}}`
+	if file := ParseDependencyFileWith(context.Background(), "app/package.json", opts); file.LockfileConflict != nil {
+		t.Errorf("LockfileConflict = %+v, want none", file.LockfileConflict)
+	}
+}
+
+func Test_bunParent(t *testing.T) {
+	for key, want := range map[string]string{"a": "", "@s/b": "", "a/@s/b": "a", "@s/b/c/d": "@s/b/c"} {
+		if got := bunParent(key); got != want {
+			t.Errorf("bunParent(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
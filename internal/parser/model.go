@@ -1,21 +1,68 @@
 package parser
 
+import (
+	"errors"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
 // Dependency represents a single declared dependency.
-type Dependency struct {
-	Name     string
-	Version  string
-	Category string // e.g., "prod", "dev"
-}
+type Dependency = ecosystem.Dependency
 
 // DependencyFile holds the metadata and results of parsing a dependency file.
 type DependencyFile struct {
 	Path         string
 	Packaging    string
 	Dependencies []Dependency
+	Project      Project // Project owning the file, once its ecosystem is known
 	Err          error
+	Partial      bool // Dependencies were recovered despite Err
+	// LockfileConflict reports the merge conflict blocks of the lockfile of
+	// the project, if any.
+	LockfileConflict *diagnostic.Diagnostic
 }
 
-// Parser is implemented by each language-specific dependency file parser.
-type Parser interface {
-	Parse(content []byte) ([]Dependency, error)
+// Diagnostic describes the error of the file, if any. Files with generic
+// names which turn out not to be dependency files are not reported.
+func (f DependencyFile) Diagnostic() (diagnostic.Diagnostic, bool) {
+	var parseErr *ParseError
+	var conflict *ConflictError
+	switch {
+	case f.Err == nil, errors.Is(f.Err, ErrNotManifest):
+		return diagnostic.Diagnostic{}, false
+	case errors.Is(f.Err, ErrFileTooLarge):
+		return diagnostic.Warnf(diagnostic.CodeMaxFileSize, f.Path, "skipped by max-file-size (%v)", f.Err), true
+	case errors.Is(f.Err, ErrUnsupportedFile), errors.Is(f.Err, ErrUnsupportedLockfile):
+		return diagnostic.Warnf(diagnostic.CodeUnsupported, f.Path, "%v", f.Err), true
+	case errors.Is(f.Err, ErrRead):
+		return diagnostic.Errorf(diagnostic.CodeRead, f.Path, "%v", f.Err), true
+	case errors.As(f.Err, &conflict) && f.Partial:
+		d := diagnostic.Warnf(diagnostic.CodeConflict, f.Path, "%v, keeping %s", conflict, conflict.Ours)
+		d.Line = conflict.Line
+		return d, true
+	case errors.As(f.Err, &conflict):
+		d := diagnostic.Errorf(diagnostic.CodeConflict, f.Path, "%v", conflict)
+		d.Line = conflict.Line
+		return d, true
+	case errors.As(f.Err, &parseErr) && f.Partial:
+		d := diagnostic.Warnf(diagnostic.CodePartial, f.Path, "partially parsed, %d dependencies recovered: %v", len(f.Dependencies), parseErr.Err)
+		d.Line = parseErr.Line
+		return d, true
+	case errors.As(f.Err, &parseErr):
+		d := diagnostic.Errorf(diagnostic.CodeParse, f.Path, "%v", parseErr.Err)
+		d.Line = parseErr.Line
+		return d, true
+	}
+	return diagnostic.Errorf(diagnostic.CodeParse, f.Path, "%v", f.Err), true
 }
+
+// LockfileDiagnostic describes an error returned by ReadLockfile, partial
+// when a resolution was returned along with it.
+func LockfileDiagnostic(path string, err error, partial bool) diagnostic.Diagnostic {
+	d, _ := DependencyFile{Path: path, Err: err, Partial: partial}.Diagnostic()
+	return d
+}
+
+// Parser is implemented by each language-specific dependency file parser.
+type Parser = ecosystem.Parser
@@ -3,11 +3,16 @@ package parser
 import (
 	"encoding/json"
 	"sort"
+
+	"github.com/flarebyte/clingy-code-detective/internal/location"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
 )
 
 type nodeParser struct{}
 
 type packageJSON struct {
+	Name            string            `json:"name"`
+	Version         string            `json:"version"`
 	Dependencies    map[string]string `json:"dependencies"`
 	DevDependencies map[string]string `json:"devDependencies"`
 }
@@ -17,8 +22,9 @@ func (p nodeParser) Parse(content []byte) ([]Dependency, error) {
 	if err := json.Unmarshal(content, &pkg); err != nil {
 		return nil, err
 	}
+	positions := location.JSON(content)
 
-	collectDeps := func(m map[string]string, cat string) []Dependency {
+	collectDeps := func(section string, m map[string]string, cat string) []Dependency {
 		var deps []Dependency
 		keys := make([]string, 0, len(m))
 		for k := range m {
@@ -27,14 +33,35 @@ func (p nodeParser) Parse(content []byte) ([]Dependency, error) {
 		sort.Strings(keys)
 
 		for _, name := range keys {
-			deps = append(deps, Dependency{Name: name, Version: m[name], Category: cat})
+			pos := positions.Of(section, name)
+			deps = append(deps, Dependency{Name: name, Version: m[name], Category: cat, Line: pos.Line, Column: pos.Column})
 		}
 		return deps
 	}
 
 	var deps []Dependency
-	deps = append(deps, collectDeps(pkg.Dependencies, "prod")...)
-	deps = append(deps, collectDeps(pkg.DevDependencies, "dev")...)
+	deps = append(deps, collectDeps("dependencies", pkg.Dependencies, "prod")...)
+	deps = append(deps, collectDeps("devDependencies", pkg.DevDependencies, "dev")...)
 
 	return deps, nil
 }
+
+// ReadIdentity reads the name and version of the package.
+func (p nodeParser) ReadIdentity(content []byte) (ecosystem.Identity, error) {
+	var pkg packageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return ecosystem.Identity{}, err
+	}
+	return ecosystem.Identity{Name: pkg.Name, Version: pkg.Version}, nil
+}
+
+// Recover parses package.json files with comments, trailing commas or merge
+// conflicts, keeping our side of conflicts, and falls back to a line by line
+// scan of the dependency sections.
+func (p nodeParser) Recover(content []byte) ([]Dependency, error) {
+	if deps, err := p.Parse(sanitizeJSON(content)); err == nil {
+		return deps, nil
+	}
+	entries := scanJSONSections(resolveConflicts(content, keepOurs), "dependencies", "devDependencies")
+	return recoveredDependencies(entries, map[string]string{"dependencies": "prod", "devDependencies": "dev"}), nil
+}
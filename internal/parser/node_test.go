@@ -21,8 +21,8 @@ func TestNodeParser_Parse(t *testing.T) {
 				},
 			},
 			expected: []Dependency{
-				{Name: "express", Version: "4.17.1", Category: "prod"},
-				{Name: "lodash", Version: "4.17.21", Category: "prod"},
+				{Name: "express", Version: "4.17.1", Category: "prod", Line: 3, Column: 5},
+				{Name: "lodash", Version: "4.17.21", Category: "prod", Line: 4, Column: 5},
 			},
 		},
 		{
@@ -33,7 +33,7 @@ func TestNodeParser_Parse(t *testing.T) {
 				},
 			},
 			expected: []Dependency{
-				{Name: "mocha", Version: "9.0.0", Category: "dev"},
+				{Name: "mocha", Version: "9.0.0", Category: "dev", Line: 3, Column: 5},
 			},
 		},
 		{
@@ -47,8 +47,8 @@ func TestNodeParser_Parse(t *testing.T) {
 				},
 			},
 			expected: []Dependency{
-				{Name: "react", Version: "17.0.2", Category: "prod"},
-				{Name: "eslint", Version: "7.32.0", Category: "dev"},
+				{Name: "react", Version: "17.0.2", Category: "prod", Line: 3, Column: 5},
+				{Name: "eslint", Version: "7.32.0", Category: "dev", Line: 6, Column: 5},
 			},
 		},
 		{
@@ -62,7 +62,7 @@ func TestNodeParser_Parse(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			content, err := json.Marshal(tt.input)
+			content, err := json.MarshalIndent(tt.input, "", "  ")
 			if err != nil {
 				t.Fatalf("failed to marshal input: %v", err)
 			}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+// packageLock is a package-lock.json or npm-shrinkwrap.json file. Version 1
+// nests the installed packages under dependencies, later versions list them
+// under packages, keyed by their directory, e.g. node_modules/a.
+type packageLock struct {
+	Packages     map[string]packageLockEntry   `json:"packages"`
+	Dependencies map[string]packageLockV1Entry `json:"dependencies"`
+}
+
+type packageLockEntry struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Link                 bool              `json:"link"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+type packageLockV1Entry struct {
+	Version      string                        `json:"version"`
+	Requires     map[string]string             `json:"requires"`
+	Dependencies map[string]packageLockV1Entry `json:"dependencies"`
+}
+
+// readPackageLock resolves the dependencies of each directory of a
+// package-lock.json as Node.js does, from the closest node_modules.
+func readPackageLock(content []byte, declared []Dependency) (ecosystem.Resolution, error) {
+	var lock packageLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return ecosystem.Resolution{}, err
+	}
+	entries := lock.Packages
+	if entries == nil {
+		entries = make(map[string]packageLockEntry)
+		flattenPackageLockV1(entries, "", lock.Dependencies)
+	}
+	if _, ok := entries[""]; !ok {
+		// Version 1 does not record the dependencies of the project
+		root := packageLockEntry{Dependencies: make(map[string]string)}
+		for _, d := range declared {
+			root.Dependencies[d.Name] = d.Version
+		}
+		entries[""] = root
+	}
+
+	// resolve returns the directory of the package name required from dir,
+	// following links to workspaces
+	resolve := func(dir, name string) (string, bool) {
+		for {
+			candidate := path.Join(dir, "node_modules", name)
+			if entry, ok := entries[candidate]; ok {
+				if _, found := entries[entry.Resolved]; entry.Link && found {
+					return entry.Resolved, true
+				}
+				return candidate, true
+			}
+			if dir == "" {
+				return "", false
+			}
+			if dir = path.Dir(dir); dir == "." {
+				dir = ""
+			}
+		}
+	}
+	dependencies := func(dir string, entry packageLockEntry) []string {
+		var ids []string
+		for _, section := range []map[string]string{entry.Dependencies, entry.DevDependencies, entry.OptionalDependencies, entry.PeerDependencies} {
+			for name := range section {
+				if id, ok := resolve(dir, name); ok {
+					ids = append(ids, id)
+				}
+			}
+		}
+		return ids
+	}
+
+	packages := make(map[string]ecosystem.ResolvedPackage)
+	for dir, entry := range entries {
+		if _, found := entries[entry.Resolved]; dir == "" || entry.Link && found {
+			continue
+		}
+		name := entry.Name
+		if i := strings.LastIndex(dir, "node_modules/"); name == "" && i >= 0 {
+			name = dir[i+len("node_modules/"):]
+		} else if name == "" {
+			name = path.Base(dir)
+		}
+		packages[dir] = ecosystem.ResolvedPackage{Name: name, Version: entry.Version, Dependencies: dependencies(dir, entry)}
+	}
+	return newResolution(dependencies("", entries[""]), packages, false), nil
+}
+
+// flattenPackageLockV1 adds the nested dependencies of a version 1 lockfile
+// to entries, keyed by their directory as in later versions.
+func flattenPackageLockV1(entries map[string]packageLockEntry, dir string, deps map[string]packageLockV1Entry) {
+	for name, dep := range deps {
+		key := path.Join(dir, "node_modules", name)
+		entries[key] = packageLockEntry{Name: name, Version: dep.Version, Dependencies: dep.Requires}
+		flattenPackageLockV1(entries, key, dep.Dependencies)
+	}
+}
@@ -1,53 +1,212 @@
 package parser
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/flarebyte/clingy-code-detective/internal/location"
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+var (
+	// ErrFileTooLarge is returned for dependency files above the size limit.
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrUnsupportedFile is returned for files no parser is registered for.
+	ErrUnsupportedFile = errors.New("unsupported file type")
+	// ErrNotManifest is returned when content sniffing does not recognise a
+	// file with a generic name as a dependency file.
+	ErrNotManifest = errors.New("not a dependency file")
+	// ErrRead wraps the errors of a ContentReader other than ErrFileTooLarge.
+	ErrRead = errors.New("cannot read file")
 )
 
+// ParseError is returned when a parser rejects the content of a file.
+type ParseError struct {
+	Line int // 1-based line of the error, 0 when unknown
+	Err  error
+}
+
+func (e *ParseError) Error() string { return e.Err.Error() }
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// errorLinePattern finds the line in the messages of the YAML and TOML
+// decoders, e.g. "yaml: line 3: did not find expected key".
+var errorLinePattern = regexp.MustCompile(`\bline (\d+)\b`)
+
+// newParseError wraps a parser error with the line it refers to.
+func newParseError(content []byte, err error) *ParseError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &ParseError{Line: location.NewIndex(content).Position(int(syntaxErr.Offset)).Line, Err: err}
+	case errors.As(err, &typeErr):
+		return &ParseError{Line: location.NewIndex(content).Position(int(typeErr.Offset)).Line, Err: err}
+	}
+	if m := errorLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &ParseError{Line: line, Err: err}
+	}
+	return &ParseError{Err: err}
+}
+
+// ContentReader returns the raw content of the dependency file at path.
+type ContentReader func(path string) ([]byte, error)
+
+// ReadFileLimit returns a ContentReader for the local filesystem which rejects
+// files larger than maxSize bytes with ErrFileTooLarge, without reading them
+// entirely. A maxSize of 0 means no limit.
+func ReadFileLimit(maxSize int64) ContentReader {
+	if maxSize <= 0 {
+		return os.ReadFile
+	}
+	return func(path string) ([]byte, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		// The size may change after a stat, so the read is bounded as well
+		content, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(content)) > maxSize {
+			return nil, fmt.Errorf("%w: more than %d bytes", ErrFileTooLarge, maxSize)
+		}
+		return content, nil
+	}
+}
+
+// Options controls how dependency files are read and parsed.
+type Options struct {
+	Read     ContentReader // Reads the content of files, os.ReadFile when nil
+	Exists   FileExists    // Finds the lockfiles, on the local filesystem when nil
+	Tolerant bool          // Recovers what it can from malformed files
+}
+
 // ParseDependencyFile opens and parses a file using the appropriate parser.
 func ParseDependencyFile(path string) DependencyFile {
-	var parser Parser
-	var packaging string
-
-	switch filepath.Base(path) {
-	case "package.json":
-		parser = nodeParser{}
-		packaging = "node"
-	case "pubspec.yaml":
-		parser = dartParser{}
-		packaging = "dart"
-	case "go.mod":
-		parser = goModParser{}
-		packaging = "go"
-	case "requirements.txt":
-		parser = pythonParser{}
-		packaging = "python"
-	default:
-		return DependencyFile{Path: path, Packaging: "", Err: errors.New("unsupported file type")}
+	return ParseDependencyFileWith(context.Background(), path, Options{})
+}
+
+// ParseDependencyFileWith parses a file using the appropriate parser, which
+// may stop early when ctx is done. In tolerant mode, the dependencies
+// recovered from a file the parser rejects are returned along with the parse
+// error, and the file is marked Partial. Files with merge conflict blocks fail
+// with a ConflictError, and keep the dependencies of the current side in
+// tolerant mode. The lockfile of the project is checked for conflict blocks
+// too.
+func ParseDependencyFileWith(ctx context.Context, path string, opts Options) DependencyFile {
+	read := opts.Read
+	if read == nil {
+		read = os.ReadFile
+	}
+	exists := opts.Exists
+	if exists == nil {
+		exists = localFileExists
+	}
+
+	eco, matched := ecosystem.Match(path)
+	if !matched && !isSniffable(path) {
+		return DependencyFile{Path: path, Packaging: "", Err: ErrUnsupportedFile}
 	}
 
-	content, ferr := os.ReadFile(path)
+	content, ferr := read(path)
 	if ferr != nil {
+		if !errors.Is(ferr, ErrFileTooLarge) {
+			ferr = fmt.Errorf("%w: %w", ErrRead, ferr)
+		}
 		return DependencyFile{
 			Path: path,
 			Err:  ferr,
 		}
 	}
 
-	deps, err := parser.Parse(content)
+	if !matched {
+		if eco, matched = ecosystem.Sniff(path, content); !matched {
+			return DependencyFile{Path: path, Err: ErrNotManifest}
+		}
+	}
+
+	parser := eco.NewParser()
+	deps, err := parseContent(ctx, parser, path, content)
+	partial := false
+	if conflict, ok := findConflict(content); ok {
+		// Conflicts are reported even when the parser skips the markers
+		ours, oursErr := parseConflict(ctx, parser, path, content, conflict)
+		deps, err = nil, conflict
+		if opts.Tolerant && oursErr == nil && len(ours) > 0 {
+			deps, partial = ours, true
+		}
+	} else if err != nil {
+		err = newParseError(content, err)
+		if r, ok := parser.(ecosystem.Recoverer); ok && opts.Tolerant {
+			if recovered, rerr := r.Recover(content); rerr == nil && len(recovered) > 0 {
+				deps, partial = recovered, true
+			}
+		}
+	}
+	if eco.CategoryFromName {
+		category := CategoryFromFilename(path)
+		for i := range deps {
+			deps[i].Category = category
+		}
+	}
+	project := newProject(eco, parser, path, content, exists)
 	return DependencyFile{
-		Path:         path,
-		Packaging:    packaging,
-		Dependencies: deps,
-		Err:          err,
+		Path:             path,
+		Packaging:        eco.Name,
+		Dependencies:     deps,
+		Project:          project,
+		Err:              err,
+		Partial:          partial,
+		LockfileConflict: lockfileConflict(project, deps, opts),
 	}
 }
 
-func ProduceDependencyFile(filePathChan <-chan string, resultChan chan<- DependencyFile) {
-	for path := range filePathChan {
-		depFile := ParseDependencyFile(path)
-		resultChan <- depFile
+// parseContent parses the content of the file at path.
+func parseContent(ctx context.Context, parser Parser, path string, content []byte) ([]Dependency, error) {
+	switch p := parser.(type) {
+	case ecosystem.ContextParser:
+		return p.ParseContext(ctx, path, content)
+	case ecosystem.PathParser:
+		return p.ParsePath(path, content)
+	default:
+		return p.Parse(content)
+	}
+}
+
+// ProduceDependencyFile parses every path received on filePathChan with the
+// options, and sends the result into resultChan. It returns when filePathChan
+// is closed or ctx is done, dropping the results of files parsed meanwhile.
+func ProduceDependencyFile(ctx context.Context, opts Options, filePathChan <-chan string, resultChan chan<- DependencyFile) {
+	for {
+		var path string
+		select {
+		case <-ctx.Done():
+			return
+		case p, ok := <-filePathChan:
+			if !ok {
+				return
+			}
+			path = p
+		}
+		depFile := ParseDependencyFileWith(ctx, path, opts)
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case resultChan <- depFile:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+func TestReadFileLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte(`{"dependencies":{"a":"1.0.0"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadFileLimit(0)(path); err != nil {
+		t.Errorf("no limit: unexpected error %v", err)
+	}
+	if _, err := ReadFileLimit(1024)(path); err != nil {
+		t.Errorf("under limit: unexpected error %v", err)
+	}
+
+	depFile := ParseDependencyFileWith(context.Background(), path, Options{Read: ReadFileLimit(10)})
+	if !errors.Is(depFile.Err, ErrFileTooLarge) {
+		t.Errorf("over limit: expected ErrFileTooLarge, got %v", depFile.Err)
+	}
+}
+
+func TestDependencyFile_Diagnostic(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		read     ContentReader
+		want     diagnostic.Code
+		severity diagnostic.Severity
+		line     int
+		reported bool
+	}{
+		{name: "valid", path: write("ok/package.json", `{}`), read: os.ReadFile},
+		{name: "not a manifest", path: write("data.json", `{"x": 1}`), read: os.ReadFile},
+		{
+			name: "json syntax error", path: write("json/package.json", "{\n  \"dependencies\": {\n    \"a\": \"1\",\n  }\n}"),
+			read: os.ReadFile, want: diagnostic.CodeParse, severity: diagnostic.Error, line: 4, reported: true,
+		},
+		{
+			name: "yaml syntax error", path: write("yaml/pubspec.yaml", "name: x\ndependencies:\n  a: [\n"),
+			read: os.ReadFile, want: diagnostic.CodeParse, severity: diagnostic.Error, line: 3, reported: true,
+		},
+		{
+			name: "unsupported", path: write("README.md", "# x"),
+			read: os.ReadFile, want: diagnostic.CodeUnsupported, severity: diagnostic.Warning, reported: true,
+		},
+		{
+			name: "unreadable", path: filepath.Join(dir, "missing", "package.json"),
+			read: os.ReadFile, want: diagnostic.CodeRead, severity: diagnostic.Error, reported: true,
+		},
+		{
+			name: "too large", path: write("big/package.json", `{"dependencies": {}}`),
+			read: ReadFileLimit(4), want: diagnostic.CodeMaxFileSize, severity: diagnostic.Warning, reported: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseDependencyFileWith(context.Background(), tt.path, Options{Read: tt.read}).Diagnostic()
+			if ok != tt.reported {
+				t.Fatalf("Diagnostic() reported = %v, want %v (%+v)", ok, tt.reported, got)
+			}
+			if !ok {
+				return
+			}
+			if got.Code != tt.want || got.Severity != tt.severity || got.Line != tt.line || got.Path != tt.path {
+				t.Errorf("Diagnostic() = %+v, want code %s, severity %s, line %d", got, tt.want, tt.severity, tt.line)
+			}
+		})
+	}
+}
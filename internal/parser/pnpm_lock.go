@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"path"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+	"gopkg.in/yaml.v3"
+)
+
+// pnpmLock is a pnpm-lock.yaml file. Workspaces are listed under importers,
+// keyed by their directory, and single projects at the top level before
+// version 9. Packages are keyed by /name/version before version 6,
+// /name@version before version 9, then name@version, with their
+// dependencies under snapshots.
+type pnpmLock struct {
+	LockfileVersion string                  `yaml:"lockfileVersion"`
+	Importers       map[string]pnpmImporter `yaml:"importers"`
+	pnpmImporter    `yaml:",inline"`
+	Packages        map[string]pnpmPackage `yaml:"packages"`
+	Snapshots       map[string]pnpmPackage `yaml:"snapshots"`
+}
+
+type pnpmImporter struct {
+	Dependencies         map[string]pnpmReference `yaml:"dependencies"`
+	DevDependencies      map[string]pnpmReference `yaml:"devDependencies"`
+	OptionalDependencies map[string]pnpmReference `yaml:"optionalDependencies"`
+}
+
+type pnpmPackage struct {
+	Name                 string            `yaml:"name"`
+	Version              string            `yaml:"version"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// pnpmReference is the resolved version of a dependency of an importer, a
+// string before version 6 and a mapping with the specifier since.
+type pnpmReference string
+
+func (r *pnpmReference) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*r = pnpmReference(node.Value)
+		return nil
+	}
+	var ref struct {
+		Version string `yaml:"version"`
+	}
+	if err := node.Decode(&ref); err != nil {
+		return err
+	}
+	*r = pnpmReference(ref.Version)
+	return nil
+}
+
+// readPnpmLock resolves the dependencies of the root importer. Workspaces
+// linked with link: are packages named importer:<directory>.
+func readPnpmLock(content []byte) (ecosystem.Resolution, error) {
+	var lock pnpmLock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return ecosystem.Resolution{}, err
+	}
+	v5 := strings.HasPrefix(lock.LockfileVersion, "5")
+	entries := lock.Snapshots
+	if entries == nil {
+		entries = lock.Packages
+	}
+
+	resolve := func(name, ref string) (string, bool) {
+		for _, key := range []string{ref, "/" + name + "/" + ref, "/" + name + "@" + ref, name + "@" + ref} {
+			if _, ok := entries[key]; ok {
+				return key, true
+			}
+		}
+		return "", false
+	}
+	packages := make(map[string]ecosystem.ResolvedPackage)
+	var resolveImporter func(dir string, importer pnpmImporter) []string
+	resolveImporter = func(dir string, importer pnpmImporter) []string {
+		var ids []string
+		for _, section := range []map[string]pnpmReference{importer.Dependencies, importer.DevDependencies, importer.OptionalDependencies} {
+			for name, ref := range section {
+				target, link := strings.CutPrefix(string(ref), "link:")
+				if !link {
+					if id, ok := resolve(name, string(ref)); ok {
+						ids = append(ids, id)
+					}
+					continue
+				}
+				target = path.Join(dir, target)
+				id := "importer:" + target
+				if _, done := packages[id]; !done {
+					packages[id] = ecosystem.ResolvedPackage{Name: name, Version: string(ref)}
+					if workspace, ok := lock.Importers[target]; ok {
+						packages[id] = ecosystem.ResolvedPackage{Name: name, Version: string(ref), Dependencies: resolveImporter(target, workspace)}
+					}
+				}
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+
+	for key, entry := range entries {
+		name, version := pnpmPackageKey(key, v5)
+		if entry.Name != "" {
+			name, version = entry.Name, entry.Version
+		}
+		var deps []string
+		for _, section := range []map[string]string{entry.Dependencies, entry.OptionalDependencies} {
+			for depName, ref := range section {
+				if id, ok := resolve(depName, ref); ok {
+					deps = append(deps, id)
+				}
+			}
+		}
+		packages[key] = ecosystem.ResolvedPackage{Name: name, Version: version, Dependencies: deps}
+	}
+
+	root, ok := lock.Importers["."]
+	if !ok {
+		root = lock.pnpmImporter
+	}
+	return newResolution(resolveImporter(".", root), packages, false), nil
+}
+
+// pnpmPackageKey returns the name and version of a package key, without the
+// peer dependencies the version is suffixed with.
+func pnpmPackageKey(key string, v5 bool) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+	if v5 {
+		i := strings.LastIndex(key, "/")
+		if i < 0 {
+			return key, ""
+		}
+		version, _, _ := strings.Cut(key[i+1:], "_")
+		return key[:i], version
+	}
+	key, _, _ = strings.Cut(key, "(")
+	return packageSpecName(key)
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+// Project is a project of an ecosystem, identified by the directory of its
+// manifests. It owns the manifests of the ecosystem found in the directory,
+// such as requirements.txt, requirements-dev.txt and requirements/test.txt,
+// and their lockfile.
+type Project struct {
+	Root      string   // Directory of the manifests, above requirements/ for requirements/*.txt
+	Ecosystem string   // e.g. "node"
+	Name      string   `json:",omitempty"` // Declared by the manifest, e.g. the module of go.mod
+	Version   string   `json:",omitempty"` // Declared by the manifest
+	Manifests []string // Paths of the manifests
+	Lockfile  string   `json:",omitempty"` // Path of the lockfile, when there is one
+}
+
+// ProjectRoot returns the root directory of the project owning a manifest of
+// an ecosystem, such as api for api/requirements/dev.txt.
+func ProjectRoot(manifest, ecosystemName string) string {
+	if eco, ok := ecosystem.Lookup(ecosystemName); ok {
+		return eco.Root(manifest)
+	}
+	return filepath.Dir(manifest)
+}
+
+// Label returns the name of the project, or its root directory when the
+// manifest does not declare one.
+func (p Project) Label() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Root
+}
+
+// Merge adds the manifests of another file of the same project, keeping the
+// identity and lockfile already known.
+func (p *Project) Merge(other Project) {
+	p.Manifests = append(p.Manifests, other.Manifests...)
+	if p.Name == "" {
+		p.Name, p.Version = other.Name, other.Version
+	}
+	if p.Lockfile == "" {
+		p.Lockfile = other.Lockfile
+	}
+}
+
+// FileExists tells whether a regular file exists at path.
+type FileExists func(path string) bool
+
+// localFileExists checks the local filesystem.
+func localFileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// newProject returns the project owning the manifest at path, with the
+// identity read by the parser, if it can, and the first lockfile of the
+// ecosystem found next to the manifest.
+func newProject(eco ecosystem.Ecosystem, parser Parser, path string, content []byte, exists FileExists) Project {
+	project := Project{
+		Root:      eco.Root(path),
+		Ecosystem: eco.Name,
+		Manifests: []string{path},
+	}
+	if r, ok := parser.(ecosystem.IdentityReader); ok {
+		if identity, err := r.ReadIdentity(content); err == nil {
+			project.Name, project.Version = identity.Name, identity.Version
+		}
+	}
+	for _, name := range eco.Lockfiles {
+		if lockfile := filepath.Join(project.Root, name); exists(lockfile) {
+			project.Lockfile = lockfile
+			break
+		}
+	}
+	return project
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+func TestReadIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		parser   ecosystem.IdentityReader
+		content  string
+		expected ecosystem.Identity
+	}{
+		{
+			name:     "package.json",
+			parser:   nodeParser{},
+			content:  `{"name": "@acme/web", "version": "1.4.0", "dependencies": {}}`,
+			expected: ecosystem.Identity{Name: "@acme/web", Version: "1.4.0"},
+		},
+		{
+			name:     "private package.json",
+			parser:   nodeParser{},
+			content:  `{"private": true}`,
+			expected: ecosystem.Identity{},
+		},
+		{
+			name:     "pubspec.yaml",
+			parser:   dartParser{},
+			content:  "name: acme_app\nversion: 2.0.1+3\n",
+			expected: ecosystem.Identity{Name: "acme_app", Version: "2.0.1+3"},
+		},
+		{
+			name:     "go.mod",
+			parser:   goModParser{},
+			content:  "// Acme tools\nmodule example.com/acme/tools // deprecated: moved\n\ngo 1.22\n",
+			expected: ecosystem.Identity{Name: "example.com/acme/tools"},
+		},
+		{
+			name:     "quoted module",
+			parser:   goModParser{},
+			content:  "module \"example.com/acme\"\n",
+			expected: ecosystem.Identity{Name: "example.com/acme"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parser.ReadIdentity([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("ReadIdentity() error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("ReadIdentity() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDependencyFileWith_Project(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	web := write("web/package.json", `{"name": "web", "version": "1.0.0"}`)
+	write("web/yarn.lock", "")
+	write("web/pnpm-lock.yaml", "")
+	app := write("app/pubspec.yaml", "name: app\n")
+	python := write("api/requirements.txt", "flask==3.0.0\n")
+
+	tests := []struct {
+		name     string
+		path     string
+		exists   FileExists
+		expected Project
+	}{
+		{
+			name:     "first lockfile in order of preference",
+			path:     web,
+			expected: Project{Root: filepath.Dir(web), Ecosystem: "node", Name: "web", Version: "1.0.0", Manifests: []string{web}, Lockfile: filepath.Join(dir, "web", "yarn.lock")},
+		},
+		{
+			name:     "without lockfile",
+			path:     app,
+			expected: Project{Root: filepath.Dir(app), Ecosystem: "dart", Name: "app", Manifests: []string{app}},
+		},
+		{
+			name:     "without identity",
+			path:     python,
+			expected: Project{Root: filepath.Dir(python), Ecosystem: "python", Manifests: []string{python}},
+		},
+		{
+			name:     "custom lookup",
+			path:     app,
+			exists:   func(path string) bool { return filepath.Base(path) == "pubspec.lock" },
+			expected: Project{Root: filepath.Dir(app), Ecosystem: "dart", Name: "app", Manifests: []string{app}, Lockfile: filepath.Join(dir, "app", "pubspec.lock")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseDependencyFileWith(context.Background(), tt.path, Options{Exists: tt.exists})
+			if got.Err != nil {
+				t.Fatalf("unexpected error: %v", got.Err)
+			}
+			if !reflect.DeepEqual(got.Project, tt.expected) {
+				t.Errorf("Project = %+v, want %+v", got.Project, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProject_Merge(t *testing.T) {
+	project := Project{Root: "api", Ecosystem: "python", Manifests: []string{"api/requirements.txt"}}
+	project.Merge(Project{Root: "api", Ecosystem: "python", Manifests: []string{"api/requirements-dev.txt"}, Lockfile: "api/requirements.lock"})
+
+	expected := Project{
+		Root:      "api",
+		Ecosystem: "python",
+		Manifests: []string{"api/requirements.txt", "api/requirements-dev.txt"},
+		Lockfile:  "api/requirements.lock",
+	}
+	if !reflect.DeepEqual(project, expected) {
+		t.Errorf("Merge() = %+v, want %+v", project, expected)
+	}
+	if project.Label() != "api" {
+		t.Errorf("Label() = %q, want the root without a name", project.Label())
+	}
+}
+
+func TestProjectRoot(t *testing.T) {
+	tests := []struct {
+		manifest  string
+		ecosystem string
+		expected  string
+	}{
+		{filepath.Join("api", "requirements.txt"), "python", "api"},
+		{filepath.Join("api", "requirements", "dev.txt"), "python", "api"},
+		{filepath.Join("requirements", "test.in"), "python", "."},
+		{filepath.Join("web", "package.json"), "node", "web"},
+		{filepath.Join("tools", "deps.txt"), "python", "tools"}, // Sniffed, matching no pattern
+		{filepath.Join("svc", "Cargo.toml"), "unknown", "svc"},
+	}
+	for _, tt := range tests {
+		if got := ProjectRoot(tt.manifest, tt.ecosystem); got != tt.expected {
+			t.Errorf("ProjectRoot(%q, %q) = %q, want %q", tt.manifest, tt.ecosystem, got, tt.expected)
+		}
+	}
+}
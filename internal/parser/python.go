@@ -10,18 +10,19 @@ func (p pythonParser) Parse(content []byte) ([]Dependency, error) {
 
 	lines := strings.Split(string(content), "\n")
 	deps := make([]Dependency, 0, len(lines)) // ensures non-nil slice
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
+	for i, rawLine := range lines {
+		line := strings.TrimSpace(rawLine)
+		// Options such as -r base.txt or --index-url are not dependencies
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
 			continue
 		}
 		// Rough split for `package==version` style
+		dep := Dependency{Name: line, Category: "prod", Line: i + 1, Column: strings.Index(rawLine, line) + 1}
 		parts := strings.SplitN(line, "==", 2)
 		if len(parts) == 2 {
-			deps = append(deps, Dependency{Name: parts[0], Version: parts[1], Category: "prod"})
-		} else {
-			deps = append(deps, Dependency{Name: line, Version: "", Category: "prod"})
+			dep.Name, dep.Version = parts[0], parts[1]
 		}
+		deps = append(deps, dep)
 	}
 	return deps, nil
 }
@@ -39,40 +39,47 @@ func Test_pythonParser_Parse(t *testing.T) {
 			name:  "single dependency with version",
 			input: "requests==2.25.1",
 			want: []Dependency{
-				{Name: "requests", Version: "2.25.1", Category: "prod"},
+				{Name: "requests", Version: "2.25.1", Category: "prod", Line: 1, Column: 1},
 			},
 		},
 		{
 			name:  "single dependency without version",
 			input: "flask",
 			want: []Dependency{
-				{Name: "flask", Version: "", Category: "prod"},
+				{Name: "flask", Version: "", Category: "prod", Line: 1, Column: 1},
 			},
 		},
 		{
 			name:  "multiple dependencies",
 			input: multipleDepsExaample,
 			want: []Dependency{
-				{Name: "requests", Version: "2.25.1", Category: "prod"},
-				{Name: "flask", Version: "1.1.2", Category: "prod"},
-				{Name: "numpy", Version: "", Category: "prod"},
+				{Name: "requests", Version: "2.25.1", Category: "prod", Line: 2, Column: 2},
+				{Name: "flask", Version: "1.1.2", Category: "prod", Line: 3, Column: 2},
+				{Name: "numpy", Version: "", Category: "prod", Line: 4, Column: 2},
 			},
 		},
 		{
 			name:  "with comments and empty lines",
 			input: exampleWithEmptyLines,
 			want: []Dependency{
-				{Name: "requests", Version: "2.25.1", Category: "prod"},
-				{Name: "flask", Version: "", Category: "prod"},
+				{Name: "requests", Version: "2.25.1", Category: "prod", Line: 3, Column: 2},
+				{Name: "flask", Version: "", Category: "prod", Line: 6, Column: 2},
 			},
 		},
 		{
 			name:  "trailing and leading whitespace",
 			input: exampleWithTrailingSpaces,
 			want: []Dependency{
-				{Name: "requests", Version: "2.25.1", Category: "prod"},
-				{Name: "flask", Version: "1.1.2", Category: "prod"},
-				{Name: "numpy", Version: "", Category: "prod"},
+				{Name: "requests", Version: "2.25.1", Category: "prod", Line: 2, Column: 5},
+				{Name: "flask", Version: "1.1.2", Category: "prod", Line: 3, Column: 3},
+				{Name: "numpy", Version: "", Category: "prod", Line: 4, Column: 2},
+			},
+		},
+		{
+			name:  "options and includes are ignored",
+			input: "-r base.txt\n--index-url https://pypi.example.com\nrequests==2.25.1",
+			want: []Dependency{
+				{Name: "requests", Version: "2.25.1", Category: "prod", Line: 3, Column: 1},
 			},
 		},
 		{
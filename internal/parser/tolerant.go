@@ -0,0 +1,207 @@
+package parser
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// Merge conflict markers left by git at the start of a line.
+const (
+	conflictOurs   = "<<<<<<<"
+	conflictBase   = "|||||||"
+	conflictSplit  = "======="
+	conflictTheirs = ">>>>>>>"
+)
+
+// Sides of a merge conflict kept by resolveConflicts.
+const (
+	keepOurs = iota
+	keepTheirs
+)
+
+// resolveConflicts keeps one side of every merge conflict block. The marker
+// lines and the other side are blanked rather than removed, so that lines and
+// columns of the result match the original content.
+func resolveConflicts(content []byte, keep int) []byte {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	const (
+		outside = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+	state := outside
+	for i, line := range lines {
+		trimmed := string(bytes.TrimLeft(line, " \t"))
+		blank := false
+		switch {
+		case strings.HasPrefix(trimmed, conflictOurs):
+			state, blank = inOurs, true
+		case state != outside && strings.HasPrefix(trimmed, conflictBase):
+			state, blank = inBase, true
+		case state != outside && strings.HasPrefix(trimmed, conflictSplit):
+			state, blank = inTheirs, true
+		case state != outside && strings.HasPrefix(trimmed, conflictTheirs):
+			state, blank = outside, true
+		case state == inBase,
+			state == inOurs && keep != keepOurs,
+			state == inTheirs && keep != keepTheirs:
+			blank = true
+		}
+		if blank {
+			lines[i] = blankLine(line)
+		}
+	}
+	return bytes.Join(lines, nil)
+}
+
+// blankLine replaces every character of a line by a space, keeping its end.
+func blankLine(line []byte) []byte {
+	blank := bytes.Repeat([]byte(" "), len(bytes.TrimRight(line, "\r\n")))
+	return append(blank, line[len(blank):]...)
+}
+
+// sanitizeJSON turns JSONC, as found in tsconfig.json, and JSON with trailing
+// commas or merge conflicts into plain JSON. Removed characters are replaced
+// by spaces so that positions are preserved.
+func sanitizeJSON(content []byte) []byte {
+	out := resolveConflicts(content, keepOurs)
+	inString := false
+	lastSignificant := -1 // Offset of the last character outside comments and whitespace
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			lastSignificant = i
+			continue
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+			continue
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case (c == '}' || c == ']') && lastSignificant >= 0 && out[lastSignificant] == ',':
+			out[lastSignificant] = ' '
+		}
+		lastSignificant = i
+	}
+	return out
+}
+
+// sectionEntry is a "name: version" pair found by scanJSONSections or
+// scanYAMLSections.
+type sectionEntry struct {
+	section, name, version string
+	line, column           int
+}
+
+var (
+	jsonSectionStart = regexp.MustCompile(`^\s*"([^"]+)"\s*:\s*\{`)
+	jsonSectionEntry = regexp.MustCompile(`^(\s*)"([^"]+)"\s*:\s*"([^"]*)"`)
+	yamlSectionStart = regexp.MustCompile(`^([A-Za-z_][\w-]*)\s*:\s*(#.*)?$`)
+	yamlSectionEntry = regexp.MustCompile(`^(\s+)([\w.-]+)\s*:\s*(?:["']?([^"'#\s\[{][^"'#]*?)["']?)?\s*(#.*)?$`)
+)
+
+// scanJSONSections extracts the string members of the given top level
+// objects line by line, for JSON too broken to be decoded. It expects one
+// member per line, as written by package managers.
+func scanJSONSections(content []byte, sections ...string) []sectionEntry {
+	var entries []sectionEntry
+	current := ""
+	for i, line := range strings.Split(string(content), "\n") {
+		if current == "" {
+			if m := jsonSectionStart.FindStringSubmatch(line); m != nil && contains(sections, m[1]) {
+				current = m[1]
+			}
+			continue
+		}
+		if m := jsonSectionEntry.FindStringSubmatch(line); m != nil {
+			entries = append(entries, sectionEntry{current, m[2], m[3], i + 1, len(m[1]) + 1})
+		}
+		if strings.Contains(line, "}") {
+			current = ""
+		}
+	}
+	return entries
+}
+
+// scanYAMLSections extracts the entries of the given top level mappings line
+// by line, for YAML too broken to be decoded. Entries without a scalar value
+// have an empty version.
+func scanYAMLSections(content []byte, sections ...string) []sectionEntry {
+	var entries []sectionEntry
+	current, indent := "", ""
+	for i, line := range strings.Split(strings.ReplaceAll(string(content), "\r", ""), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			current, indent = "", ""
+			if m := yamlSectionStart.FindStringSubmatch(line); m != nil && contains(sections, m[1]) {
+				current = m[1]
+			}
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		m := yamlSectionEntry.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if indent == "" {
+			indent = m[1]
+		}
+		if m[1] == indent {
+			entries = append(entries, sectionEntry{current, m[2], strings.TrimSpace(m[3]), i + 1, len(m[1]) + 1})
+		}
+	}
+	return entries
+}
+
+// recoveredDependencies converts the entries of scanned sections, using the
+// category of each section.
+func recoveredDependencies(entries []sectionEntry, categories map[string]string) []Dependency {
+	deps := make([]Dependency, 0, len(entries))
+	for _, e := range entries {
+		deps = append(deps, Dependency{
+			Name:     e.name,
+			Version:  e.version,
+			Category: categories[e.section],
+			Line:     e.line,
+			Column:   e.column,
+		})
+	}
+	return deps
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
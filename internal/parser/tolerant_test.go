@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+const conflictedPackageJSON = `{
+  "dependencies": {
+<<<<<<< HEAD
+    "lodash": "^4.17.21",
+=======
+    "lodash": "^4.17.15",
+>>>>>>> feature
+    "react": "^18.2.0"
+  }
+}`
+
+func TestResolveConflicts(t *testing.T) {
+	content := "a\n<<<<<<< HEAD\nours\n||||||| base\nbase\n=======\ntheirs\n>>>>>>> feature\nb\n"
+	tests := []struct {
+		name string
+		keep int
+		want string
+	}{
+		{"ours", keepOurs, "a\n            \nours\n            \n    \n       \n      \n               \nb\n"},
+		{"theirs", keepTheirs, "a\n            \n    \n            \n    \n       \ntheirs\n               \nb\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(resolveConflicts([]byte(content), tt.keep))
+			if got != tt.want {
+				t.Errorf("resolveConflicts() = %q, want %q", got, tt.want)
+			}
+			if len(got) != len(content) {
+				t.Errorf("resolveConflicts() changed the length from %d to %d", len(content), len(got))
+			}
+		})
+	}
+}
+
+func TestSanitizeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain", `{"a": "1"}`, `{"a": "1"}`},
+		{"line comment", "{\n  // note\n  \"a\": \"1\"\n}", "{\n         \n  \"a\": \"1\"\n}"},
+		{"block comment", `{/* x */"a": "1"}`, `{       "a": "1"}`},
+		{"trailing commas", `{"a": ["1",], "b": "2",}`, `{"a": ["1" ], "b": "2" }`},
+		{"comment markers in strings", `{"a": "http://x/*"}`, `{"a": "http://x/*"}`},
+		{"escaped quote", `{"a": "\"//",}`, `{"a": "\"//" }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(sanitizeJSON([]byte(tt.content))); got != tt.want {
+				t.Errorf("sanitizeJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanJSONSections(t *testing.T) {
+	content := "{\n  \"name\": \"x\",\n  \"dependencies\": {\n    \"a\": \"1.0.0\",\n    \"b\": \"2.0.0\"\n  },,\n  \"devDependencies\": {\n    \"c\": \"3.0.0\"\n  }\n"
+	want := []sectionEntry{
+		{"dependencies", "a", "1.0.0", 4, 5},
+		{"dependencies", "b", "2.0.0", 5, 5},
+		{"devDependencies", "c", "3.0.0", 8, 5},
+	}
+	got := scanJSONSections([]byte(content), "dependencies", "devDependencies")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scanJSONSections() = %+v, want %+v", got, want)
+	}
+}
+
+func TestScanYAMLSections(t *testing.T) {
+	content := "name: x\ndependencies:\n  http: ^1.1.0 # pinned\n  flutter:\n    sdk: flutter\n  path: '1.8.0'\n  broken: [\ndev_dependencies:\n  test: any\n"
+	want := []sectionEntry{
+		{"dependencies", "http", "^1.1.0", 3, 3},
+		{"dependencies", "flutter", "", 4, 3},
+		{"dependencies", "path", "1.8.0", 6, 3},
+		{"dev_dependencies", "test", "any", 9, 3},
+	}
+	got := scanYAMLSections([]byte(content), "dependencies", "dev_dependencies")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scanYAMLSections() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser interface {
+			Recover([]byte) ([]Dependency, error)
+		}
+		content string
+		want    []Dependency
+	}{
+		{
+			name:    "package.json with conflicts",
+			parser:  nodeParser{},
+			content: conflictedPackageJSON,
+			want: []Dependency{
+				{Name: "lodash", Version: "^4.17.21", Category: "prod", Line: 4, Column: 5},
+				{Name: "react", Version: "^18.2.0", Category: "prod", Line: 8, Column: 5},
+			},
+		},
+		{
+			name:    "package.json beyond repair",
+			parser:  nodeParser{},
+			content: "{\n  \"dependencies\": {\n    \"a\": \"1.0.0\"\n  }\n  \"devDependencies\": {\n    \"b\": 2.0.0\n    \"c\": \"3.0.0\"\n",
+			want: []Dependency{
+				{Name: "a", Version: "1.0.0", Category: "prod", Line: 3, Column: 5},
+				{Name: "c", Version: "3.0.0", Category: "dev", Line: 7, Column: 5},
+			},
+		},
+		{
+			name:    "pubspec.yaml with conflicts",
+			parser:  dartParser{},
+			content: "name: x\ndependencies:\n<<<<<<< HEAD\n  http: ^1.1.0\n=======\n  http: ^1.0.0\n>>>>>>> feature\n",
+			want: []Dependency{
+				{Name: "http", Version: "^1.1.0", Category: "prod", Line: 4, Column: 3},
+			},
+		},
+		{
+			name:    "pubspec.yaml beyond repair",
+			parser:  dartParser{},
+			content: "name: x\ndependencies:\n  http: ^1.1.0\n  bad: [\ndev_dependencies:\n  test: any\n",
+			want: []Dependency{
+				{Name: "http", Version: "^1.1.0", Category: "prod", Line: 3, Column: 3},
+				{Name: "test", Version: "any", Category: "dev", Line: 6, Column: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parser.Recover([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Recover() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Recover() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDependencyFileWith_Tolerant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	content := "{\n  // pinned\n  \"dependencies\": {\n    \"a\": \"1.0.0\",\n    \"b\": \"2.0.0\",\n  }\n}"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	strict := ParseDependencyFileWith(context.Background(), path, Options{})
+	if strict.Err == nil || strict.Partial || len(strict.Dependencies) != 0 {
+		t.Fatalf("default mode = %+v, want a parse error without dependencies", strict)
+	}
+
+	tolerant := ParseDependencyFileWith(context.Background(), path, Options{Tolerant: true})
+	if tolerant.Err == nil || !tolerant.Partial || len(tolerant.Dependencies) != 2 {
+		t.Fatalf("tolerant mode = %+v, want 2 dependencies flagged as partial", tolerant)
+	}
+	d, ok := tolerant.Diagnostic()
+	if !ok || d.Code != diagnostic.CodePartial || d.Severity != diagnostic.Warning || d.Line != 2 {
+		t.Errorf("Diagnostic() = %+v, want a partial-parse warning at line 2", d)
+	}
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+	"gopkg.in/yaml.v3"
+)
+
+// yarnEntry is a package of a yarn.lock, installed for the specifications of
+// its key, e.g. "lodash@^4.17.0, lodash@^4.17.21".
+type yarnEntry struct {
+	Version              string            `yaml:"version"`
+	Resolution           string            `yaml:"resolution"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// readYarnLock reads the custom format of Yarn 1 and the YAML of later
+// versions, which record the workspaces, including the project, as packages.
+// Yarn 1 does not record the project, whose declared dependencies are used.
+func readYarnLock(content []byte, declared []Dependency) (ecosystem.Resolution, error) {
+	var entries map[string]yarnEntry
+	var err error
+	berry := bytes.Contains(content, []byte("__metadata:"))
+	if berry {
+		err = yaml.Unmarshal(content, &entries)
+		delete(entries, "__metadata")
+	} else {
+		entries, err = parseYarnV1(content)
+	}
+	if err != nil {
+		return ecosystem.Resolution{}, err
+	}
+
+	// Yarn 2+ prefixes ranges with npm: in keys but not in dependencies
+	ids := make(map[string]string)
+	keyID := func(key string, entry yarnEntry) string {
+		if entry.Resolution != "" {
+			return entry.Resolution
+		}
+		name, _ := packageSpecName(strings.Split(key, ",")[0])
+		return name + "@" + entry.Version
+	}
+	for key, entry := range entries {
+		id := keyID(key, entry)
+		for _, spec := range strings.Split(key, ",") {
+			spec = strings.TrimSpace(spec)
+			ids[spec] = id
+			if name, rest := packageSpecName(spec); strings.HasPrefix(rest, "npm:") {
+				ids[name+"@"+strings.TrimPrefix(rest, "npm:")] = id
+			}
+		}
+	}
+	resolve := func(deps ...map[string]string) []string {
+		var resolved []string
+		for _, section := range deps {
+			for name, version := range section {
+				if id, ok := ids[name+"@"+version]; ok {
+					resolved = append(resolved, id)
+				}
+			}
+		}
+		return resolved
+	}
+
+	var roots []string
+	rootFound := false
+	packages := make(map[string]ecosystem.ResolvedPackage)
+	for key, entry := range entries {
+		id := keyID(key, entry)
+		if strings.HasSuffix(id, "@workspace:.") {
+			roots, rootFound = resolve(entry.Dependencies, entry.OptionalDependencies), true
+			continue
+		}
+		name, _ := packageSpecName(id)
+		pkg := packages[id]
+		pkg.Name, pkg.Version = name, entry.Version
+		pkg.Dependencies = append(pkg.Dependencies, resolve(entry.Dependencies, entry.OptionalDependencies)...)
+		packages[id] = pkg
+	}
+	if !rootFound {
+		direct := make(map[string]string)
+		for _, d := range declared {
+			direct[d.Name] = d.Version
+		}
+		roots = resolve(direct)
+	}
+	return newResolution(roots, packages, false), nil
+}
+
+// parseYarnV1 parses the entries of a Yarn 1 lockfile, whose keys are at the
+// start of a line and fields are indented, e.g.:
+//
+//	"@babel/code-frame@^7.0.0", "@babel/code-frame@^7.10.4":
+//	  version "7.12.13"
+//	  dependencies:
+//	    "@babel/highlight" "^7.12.13"
+func parseYarnV1(content []byte) (map[string]yarnEntry, error) {
+	entries := make(map[string]yarnEntry)
+	var key, section string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		field, value := yarnField(trimmed)
+		switch indent := len(line) - len(trimmed); {
+		case indent == 0:
+			var specs []string
+			for _, spec := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				specs = append(specs, yarnUnquote(strings.TrimSpace(spec)))
+			}
+			key, section = strings.Join(specs, ", "), ""
+			entries[key] = yarnEntry{}
+		case key == "":
+			continue
+		case indent <= 2 && value == "" && strings.HasSuffix(field, ":"):
+			section = strings.TrimSuffix(field, ":")
+		case indent <= 2:
+			section = ""
+			if field == "version" {
+				entry := entries[key]
+				entry.Version = value
+				entries[key] = entry
+			}
+		case section == "dependencies" || section == "optionalDependencies":
+			entry := entries[key]
+			if entry.Dependencies == nil {
+				entry.Dependencies = make(map[string]string)
+			}
+			entry.Dependencies[field] = value
+			entries[key] = entry
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// yarnField splits a Yarn 1 line into its possibly quoted field and value.
+func yarnField(line string) (string, string) {
+	if strings.HasPrefix(line, `"`) {
+		if end := strings.Index(line[1:], `"`); end >= 0 {
+			return line[1 : end+1], yarnUnquote(strings.TrimSpace(line[end+2:]))
+		}
+	}
+	field, value, _ := strings.Cut(line, " ")
+	return field, yarnUnquote(strings.TrimSpace(value))
+}
+
+func yarnUnquote(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
@@ -0,0 +1,159 @@
+// Package plugin runs external dependency file parsers over a JSON protocol.
+//
+// For each file, clingy starts the plugin executable and writes a single
+// Request as JSON on its standard input. The plugin writes a single Response
+// as JSON on its standard output and exits with status 0. A failing, slow or
+// misbehaving plugin only fails the file it was given.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+// ProtocolVersion is the version of the protocol sent in every Request.
+const ProtocolVersion = 1
+
+// DefaultTimeout bounds the run of a plugin for one file.
+const DefaultTimeout = 10 * time.Second
+
+// maxOutputSize bounds the response a plugin may write.
+const maxOutputSize = 32 << 20
+
+// Request is sent by clingy to the plugin.
+type Request struct {
+	Version int    `json:"version"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Response is returned by the plugin.
+type Response struct {
+	Dependencies []Dependency `json:"dependencies"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// Dependency is a dependency in a Response. The category defaults to "prod"
+// and the optional line and column locate its declaration.
+type Dependency struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Category string `json:"category,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+// Plugin is a parser delegating to an external executable.
+type Plugin struct {
+	Command string
+	Args    []string
+	Timeout time.Duration // DefaultTimeout when zero
+}
+
+// Parse implements ecosystem.Parser for content without a known path.
+func (p Plugin) Parse(content []byte) ([]ecosystem.Dependency, error) {
+	return p.ParsePath("", content)
+}
+
+// ParsePath runs the plugin for one file and returns its dependencies.
+func (p Plugin) ParsePath(path string, content []byte) ([]ecosystem.Dependency, error) {
+	return p.ParseContext(context.Background(), path, content)
+}
+
+// ParseContext runs the plugin for one file, killing it when ctx is done.
+func (p Plugin) ParseContext(ctx context.Context, path string, content []byte) ([]ecosystem.Dependency, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := json.Marshal(Request{Version: ProtocolVersion, Path: path, Content: string(content)})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(runCtx, p.Command, p.Args...)
+	cmd.Stdin = bytes.NewReader(request)
+	stdout := &limitedBuffer{max: maxOutputSize}
+	stderr := &limitedBuffer{max: 4096}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Do not wait for children of the plugin still holding its output
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("plugin %s: %w", p.Command, ctx.Err())
+		}
+		if runCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("plugin %s: timed out after %s", p.Command, timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s: %w: %s", p.Command, err, msg)
+		}
+		return nil, fmt.Errorf("plugin %s: %w", p.Command, err)
+	}
+	if stdout.overflow {
+		return nil, fmt.Errorf("plugin %s: response larger than %d bytes", p.Command, maxOutputSize)
+	}
+
+	var response Response
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		// Not wrapped, the offset of a syntax error is in the response rather
+		// than in the parsed file
+		return nil, fmt.Errorf("plugin %s: invalid response: %v", p.Command, err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", p.Command, response.Error)
+	}
+
+	deps := make([]ecosystem.Dependency, 0, len(response.Dependencies))
+	for _, d := range response.Dependencies {
+		if d.Name == "" {
+			return nil, errors.New("plugin " + p.Command + ": dependency without a name")
+		}
+		category := d.Category
+		if category == "" {
+			category = "prod"
+		}
+		deps = append(deps, ecosystem.Dependency{
+			Name:     d.Name,
+			Version:  d.Version,
+			Category: category,
+			Line:     d.Line,
+			Column:   d.Column,
+		})
+	}
+	return deps, nil
+}
+
+// limitedBuffer keeps at most max bytes and discards the rest.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.overflow = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte  { return b.buf.Bytes() }
+func (b *limitedBuffer) String() string { return b.buf.String() }
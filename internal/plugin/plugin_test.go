@@ -0,0 +1,132 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+// TestHelperProcess is not a real test: it acts as the plugin executable
+// when the tests run the test binary itself.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	var request Request
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, "bad request:", err)
+		os.Exit(2)
+	}
+
+	switch os.Args[len(os.Args)-1] {
+	case "ok":
+		deps := []Dependency{}
+		for i, line := range strings.Split(strings.TrimSpace(request.Content), "\n") {
+			fields := strings.Fields(line)
+			deps = append(deps, Dependency{Name: fields[0], Version: fields[1], Line: i + 1, Column: 1})
+		}
+		deps = append(deps, Dependency{Name: request.Path, Category: "dev"})
+		_ = json.NewEncoder(os.Stdout).Encode(Response{Dependencies: deps})
+	case "error":
+		_ = json.NewEncoder(os.Stdout).Encode(Response{Error: "cannot parse"})
+	case "garbage":
+		fmt.Print("not json")
+	case "crash":
+		fmt.Fprintln(os.Stderr, "boom")
+		os.Exit(3)
+	case "slow":
+		time.Sleep(5 * time.Second)
+	}
+}
+
+func helperPlugin(t *testing.T, mode string) Plugin {
+	t.Helper()
+	t.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	return Plugin{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestHelperProcess", "--", mode},
+	}
+}
+
+func TestPlugin_ParsePath(t *testing.T) {
+	p := helperPlugin(t, "ok")
+
+	got, err := p.ParsePath("tools/.tool-versions", []byte("golang 1.24.1\nnodejs 22.0.0\n"))
+	if err != nil {
+		t.Fatalf("ParsePath() error: %v", err)
+	}
+
+	want := []ecosystem.Dependency{
+		{Name: "golang", Version: "1.24.1", Category: "prod", Line: 1, Column: 1},
+		{Name: "nodejs", Version: "22.0.0", Category: "prod", Line: 2, Column: 1},
+		{Name: "tools/.tool-versions", Category: "dev"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePath() = %v, want %v", got, want)
+	}
+}
+
+func TestPlugin_Failures(t *testing.T) {
+	tests := []struct {
+		mode    string
+		timeout time.Duration
+		wantErr string
+	}{
+		{mode: "error", wantErr: "cannot parse"},
+		{mode: "garbage", wantErr: "invalid response"},
+		{mode: "crash", wantErr: "boom"},
+		{mode: "slow", timeout: 100 * time.Millisecond, wantErr: "timed out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			p := helperPlugin(t, tt.mode)
+			p.Timeout = tt.timeout
+
+			_, err := p.ParsePath("file", []byte("x 1"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParsePath() error = %v, want %q", err, tt.wantErr)
+			}
+			// An invalid response must not be located in the parsed file
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				t.Errorf("ParsePath() error = %v, wraps a JSON syntax error", err)
+			}
+		})
+	}
+}
+
+func TestPlugin_ParseContext_Cancelled(t *testing.T) {
+	p := helperPlugin(t, "slow")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := p.ParseContext(ctx, "file", []byte("x 1"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ParseContext() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("ParseContext() returned after %s, want the plugin killed on cancellation", elapsed)
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{max: 4}
+	_, _ = io.WriteString(b, "abc")
+	_, _ = io.WriteString(b, "def")
+	if b.String() != "abcd" || !b.overflow {
+		t.Errorf("limitedBuffer = %q, overflow %v; want abcd and overflow", b.String(), b.overflow)
+	}
+}
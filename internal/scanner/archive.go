@@ -0,0 +1,249 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+// ArchiveSeparator separates the path of an archive from the path of an
+// entry inside it, as in delivery.tar.gz!/app/package.json.
+const ArchiveSeparator = "!/"
+
+// Default limits protecting the scan against zip bombs.
+const (
+	DefaultArchiveMaxEntries   = 100000
+	DefaultArchiveMaxEntrySize = 10 << 20 // 10 MiB
+	DefaultArchiveMaxTotalSize = 1 << 30  // 1 GiB
+)
+
+var (
+	errArchiveLimit = errors.New("archive limit exceeded")
+	errMaxFiles     = errors.New("maximum number of files reached")
+)
+
+// Archives treats archive files as virtual directories. Matching manifest
+// entries are read while walking the archive and handed over to ReadFile,
+// so each archive is only decompressed once. The entries left unread are
+// dropped once the context of the walk is done.
+type Archives struct {
+	MaxEntries   int   // Maximum number of entries visited in one archive
+	MaxEntrySize int64 // Maximum uncompressed size of a manifest entry
+	MaxTotalSize int64 // Maximum uncompressed bytes visited in one archive
+	Discover     bool  // Also look inside archives found during the walk
+
+	ReadLocal func(name string) ([]byte, error) // Reads paths outside archives, os.ReadFile when nil
+
+	entries sync.Map // virtual path -> []byte, consumed by ReadFile
+	release sync.Once
+}
+
+// NewArchives returns an Archives using the default limits.
+func NewArchives(discover bool) *Archives {
+	return &Archives{
+		MaxEntries:   DefaultArchiveMaxEntries,
+		MaxEntrySize: DefaultArchiveMaxEntrySize,
+		MaxTotalSize: DefaultArchiveMaxTotalSize,
+		Discover:     discover,
+	}
+}
+
+// IsArchive returns true if the filename has a supported archive extension.
+func IsArchive(filename string) bool {
+	return archiveKind(filename) != ""
+}
+
+func archiveKind(filename string) string {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tgz"
+	case strings.HasSuffix(name, ".tar"):
+		return "tar"
+	case strings.HasSuffix(name, ".zip"), strings.HasSuffix(name, ".jar"), strings.HasSuffix(name, ".whl"):
+		return "zip"
+	}
+	return ""
+}
+
+// Walk sends the virtual path of every required entry of the archive into
+// filePathChan, applying the filters and limits of opts. It does not close
+// filePathChan. It stops without error when ctx is done.
+func (a *Archives) Walk(ctx context.Context, archivePath string, opts WalkOptions, filePathChan chan<- string) error {
+	// Workers stop reading the paths sent to them when ctx is done
+	a.release.Do(func() { context.AfterFunc(ctx, a.entries.Clear) })
+	limits := newLimiter(opts)
+	defer limits.finish()
+	return a.walk(ctx, archivePath, limits, filePathChan)
+}
+
+func (a *Archives) walk(ctx context.Context, archivePath string, limits *limiter, filePathChan chan<- string) error {
+	maxEntrySize := a.MaxEntrySize
+	if limits.opts.MaxFileSize > 0 && limits.opts.MaxFileSize < maxEntrySize {
+		maxEntrySize = limits.opts.MaxFileSize
+	}
+	tooLarge := func(virtualPath string, size int64) bool {
+		if limits.tooLarge(virtualPath, size) {
+			return true
+		}
+		if size > a.MaxEntrySize {
+			limits.skip(virtualPath, diagnostic.CodeArchiveEntry, fmt.Sprintf("%d bytes > %d", size, a.MaxEntrySize))
+			return true
+		}
+		return false
+	}
+
+	emit := func(name string, size int64, r io.Reader) error {
+		virtualPath := archivePath + ArchiveSeparator + name
+		if IsFileExcluded(virtualPath, limits.opts.Excludes) || !isCandidate(name, limits.opts) {
+			return nil
+		}
+		if limits.tooDeep(virtualPath, pathDepth(name)) || tooLarge(virtualPath, size) {
+			return nil
+		}
+		// The declared size cannot be trusted, so the read is bounded as well
+		content, err := io.ReadAll(io.LimitReader(r, maxEntrySize+1))
+		if err != nil {
+			return err
+		}
+		if tooLarge(virtualPath, int64(len(content))) {
+			return nil
+		}
+		if !limits.admit(virtualPath) {
+			return errMaxFiles
+		}
+		a.entries.Store(virtualPath, content)
+		if !send(ctx, filePathChan, virtualPath) {
+			a.entries.Delete(virtualPath)
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	var err error
+	switch archiveKind(archivePath) {
+	case "zip":
+		err = a.walkZip(archivePath, emit)
+	case "tar", "tgz":
+		err = a.walkTar(archivePath, emit)
+	default:
+		err = errors.New("unsupported archive type")
+	}
+	if err == errMaxFiles || ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("archive %s: %w", archivePath, err)
+	}
+	return nil
+}
+
+func (a *Archives) walkZip(archivePath string, emit func(string, int64, io.Reader) error) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	if len(zr.File) > a.MaxEntries {
+		return fmt.Errorf("%w: more than %d entries", errArchiveLimit, a.MaxEntries)
+	}
+	var total int64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		total += int64(f.UncompressedSize64)
+		if total > a.MaxTotalSize {
+			return fmt.Errorf("%w: more than %d uncompressed bytes", errArchiveLimit, a.MaxTotalSize)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = emit(f.Name, int64(f.UncompressedSize64), rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Archives) walkTar(archivePath string, emit func(string, int64, io.Reader) error) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if archiveKind(archivePath) == "tgz" {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	var total int64
+	for count := 0; ; count++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if count >= a.MaxEntries {
+			return fmt.Errorf("%w: more than %d entries", errArchiveLimit, a.MaxEntries)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		total += hdr.Size
+		if total > a.MaxTotalSize {
+			return fmt.Errorf("%w: more than %d uncompressed bytes", errArchiveLimit, a.MaxTotalSize)
+		}
+		if err := emit(strings.TrimPrefix(hdr.Name, "./"), hdr.Size, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadFile returns the content of an archive entry collected by Walk, or
+// reads the file from the local filesystem for any other path.
+func (a *Archives) ReadFile(name string) ([]byte, error) {
+	if content, ok := a.entries.LoadAndDelete(name); ok {
+		return content.([]byte), nil
+	}
+	if strings.Contains(name, ArchiveSeparator) {
+		return nil, fmt.Errorf("archive entry %s was not collected", name)
+	}
+	if a.ReadLocal != nil {
+		return a.ReadLocal(name)
+	}
+	return os.ReadFile(name)
+}
+
+// Exists returns true if a regular file exists at name on the local
+// filesystem. Archive entries are only known once collected by Walk, so
+// they are never reported.
+func (a *Archives) Exists(name string) bool {
+	if strings.Contains(name, ArchiveSeparator) {
+		return false
+	}
+	info, err := os.Stat(name)
+	return err == nil && info.Mode().IsRegular()
+}
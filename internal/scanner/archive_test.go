@@ -0,0 +1,243 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+var archiveEntries = map[string]string{
+	"app/package.json":              `{"dependencies":{"a":"1.0.0"}}`,
+	"app/node_modules/package.json": `{}`,
+	"lib/go.mod":                    "module example.com/lib",
+	"README.md":                     "readme",
+}
+
+// helper to create a tar.gz archive with the given entries
+func createTarGz(t *testing.T, dir, name string, entries map[string]string) string {
+	t.Helper()
+	archivePath := filepath.Join(dir, name)
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for entryName, content := range entries {
+		hdr := &tar.Header{Name: entryName, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+// helper to create a zip archive with the given entries
+func createZip(t *testing.T, dir, name string, entries map[string]string) string {
+	t.Helper()
+	archivePath := filepath.Join(dir, name)
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for entryName, content := range entries {
+		w, err := zw.Create(entryName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func walkArchive(t *testing.T, a *Archives, archivePath string, excludes []string) ([]string, error) {
+	t.Helper()
+	filePathChan := make(chan string)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- a.Walk(context.Background(), archivePath, WalkOptions{Excludes: excludes}, filePathChan)
+		close(filePathChan)
+	}()
+	var found []string
+	for p := range filePathChan {
+		found = append(found, p)
+	}
+	slices.Sort(found)
+	return found, <-errChan
+}
+
+func TestArchives_Walk(t *testing.T) {
+	tmpDir := t.TempDir()
+	archives := []string{
+		createTarGz(t, tmpDir, "delivery.tar.gz", archiveEntries),
+		createZip(t, tmpDir, "delivery.zip", archiveEntries),
+	}
+
+	for _, archivePath := range archives {
+		t.Run(filepath.Base(archivePath), func(t *testing.T) {
+			a := NewArchives(false)
+			found, err := walkArchive(t, a, archivePath, []string{"/node_modules/"})
+			if err != nil {
+				t.Fatalf("Walk() error: %v", err)
+			}
+			want := []string{
+				archivePath + "!/app/package.json",
+				archivePath + "!/lib/go.mod",
+			}
+			if !slices.Equal(found, want) {
+				t.Fatalf("Walk() = %v, want %v", found, want)
+			}
+
+			content, err := a.ReadFile(archivePath + "!/app/package.json")
+			if err != nil {
+				t.Fatalf("ReadFile() error: %v", err)
+			}
+			if string(content) != archiveEntries["app/package.json"] {
+				t.Errorf("ReadFile() = %q", content)
+			}
+		})
+	}
+}
+
+func TestArchives_Cancel(t *testing.T) {
+	archivePath := createZip(t, t.TempDir(), "delivery.zip", archiveEntries)
+	a := NewArchives(false)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The entry sent to a worker which never reads it is dropped on cancel
+	filePathChan := make(chan string, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Walk(ctx, archivePath, WalkOptions{Excludes: []string{"/node_modules/"}}, filePathChan)
+	}()
+	<-filePathChan
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Walk() error: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		remaining := 0
+		a.entries.Range(func(_, _ any) bool { remaining++; return true })
+		if remaining == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d archive entries kept after cancel", remaining)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestArchives_Limits(t *testing.T) {
+	tmpDir := t.TempDir()
+	archivePath := createTarGz(t, tmpDir, "bomb.tgz", archiveEntries)
+
+	t.Run("too many entries", func(t *testing.T) {
+		a := NewArchives(false)
+		a.MaxEntries = 2
+		if _, err := walkArchive(t, a, archivePath, nil); err == nil {
+			t.Error("expected entry count error")
+		}
+	})
+
+	t.Run("too many bytes", func(t *testing.T) {
+		a := NewArchives(false)
+		a.MaxTotalSize = 10
+		if _, err := walkArchive(t, a, archivePath, nil); err == nil {
+			t.Error("expected total size error")
+		}
+	})
+
+	t.Run("oversized entry is skipped", func(t *testing.T) {
+		a := NewArchives(false)
+		a.MaxEntrySize = 25
+		found, err := walkArchive(t, a, archivePath, nil)
+		if err != nil {
+			t.Fatalf("Walk() error: %v", err)
+		}
+		want := []string{
+			archivePath + "!/app/node_modules/package.json",
+			archivePath + "!/lib/go.mod",
+		}
+		if !slices.Equal(found, want) {
+			t.Errorf("Walk() = %v, want %v", found, want)
+		}
+	})
+}
+
+func TestWalkDirectories_Archives(t *testing.T) {
+	tmpDir := t.TempDir()
+	packageJSON := createFile(t, tmpDir, "package.json")
+	nested := createZip(t, tmpDir, "vendor.jar", map[string]string{"go.mod": "module x"})
+	root := createTarGz(t, t.TempDir(), "root.tar.gz", map[string]string{"pubspec.yaml": "name: x"})
+
+	tests := []struct {
+		name          string
+		roots         []string
+		archives      *Archives
+		expectedPaths []string
+	}{
+		{
+			name:          "archive as root",
+			roots:         []string{root},
+			archives:      NewArchives(false),
+			expectedPaths: []string{root + "!/pubspec.yaml"},
+		},
+		{
+			name:          "archives found during walk are ignored by default",
+			roots:         []string{tmpDir},
+			archives:      NewArchives(false),
+			expectedPaths: []string{packageJSON},
+		},
+		{
+			name:          "archives found during walk are discovered",
+			roots:         []string{tmpDir},
+			archives:      NewArchives(true),
+			expectedPaths: []string{packageJSON, nested + "!/go.mod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePathChan := make(chan string)
+			var foundPaths []string
+
+			go WalkDirectories(context.Background(), tt.roots, WalkOptions{Archives: tt.archives}, filePathChan)
+
+			for path := range filePathChan {
+				foundPaths = append(foundPaths, path)
+			}
+
+			slices.Sort(foundPaths)
+			slices.Sort(tt.expectedPaths)
+			if !slices.Equal(foundPaths, tt.expectedPaths) {
+				t.Errorf("WalkDirectories() = %v, want %v", foundPaths, tt.expectedPaths)
+			}
+		})
+	}
+}
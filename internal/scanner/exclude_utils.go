@@ -1,11 +1,20 @@
 package scanner
 
-import "strings"
+import (
+	"path/filepath"
+	"strings"
+)
 
-// IsFileExcluded returns true if filepath contains any of the exclude substrings.
-func IsFileExcluded(filepath string, excludes []string) bool {
+// IsFileExcluded returns true if path contains any of the exclude substrings.
+// The path is cleaned, slash separated and given a leading separator first, so
+// that excludes such as /node_modules/ also match relative paths.
+func IsFileExcluded(path string, excludes []string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
 	for _, ex := range excludes {
-		if strings.Contains(filepath, ex) {
+		if strings.Contains(path, ex) {
 			return true
 		}
 	}
@@ -45,6 +45,18 @@ func TestIsFileExcluded(t *testing.T) {
 			excludes: []string{"tmp-build"},
 			want:     true,
 		},
+		{
+			name:     "relative path matches a leading exclude",
+			filepath: "node_modules/foo/package.json",
+			excludes: []string{"/node_modules/"},
+			want:     true,
+		},
+		{
+			name:     "dot relative path matches a leading exclude",
+			filepath: "./vendor/bar/go.mod",
+			excludes: []string{"/vendor/"},
+			want:     true,
+		},
 		{
 			name:     "case sensitive match - no match",
 			filepath: "/home/user/project/Node_Modules/foo.js",
@@ -0,0 +1,120 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+// GitRevision identifies a commit in a local git repository whose tree is
+// scanned instead of the working copy.
+type GitRevision struct {
+	Dir string // Directory of the local repository, or of a subdirectory to scan
+	Rev string // Any commit-ish understood by git, e.g. a tag, branch or sha
+}
+
+// Verify checks that the revision resolves to a commit in the repository.
+func (g GitRevision) Verify() error {
+	if _, err := g.git(context.Background(), "rev-parse", "--verify", "--quiet", g.Rev+"^{commit}"); err != nil {
+		return fmt.Errorf("unknown git revision %q in %s", g.Rev, g.Dir)
+	}
+	return nil
+}
+
+// WalkTree lists the files of the revision tree below each root and sends the
+// path of required files into filePathChan. Roots, explicit files and paths
+// are relative to Dir, like the paths of git itself. Archives are not supported. It
+// closes filePathChan when done, or as soon as ctx is done.
+func (g GitRevision) WalkTree(ctx context.Context, roots []string, opts WalkOptions, filePathChan chan<- string) {
+	defer close(filePathChan)
+	limits := newLimiter(opts)
+	defer limits.finish()
+
+	sendFiles(ctx, opts.Files, limits, filePathChan)
+	for _, root := range roots {
+		if limits.limitReached() || ctx.Err() != nil {
+			return
+		}
+		if err := g.walkRoot(ctx, root, limits, filePathChan); err != nil && ctx.Err() == nil {
+			opts.Diagnostics.Report(diagnostic.Errorf(diagnostic.CodeWalk, root, "%v", err))
+		}
+	}
+}
+
+func (g GitRevision) walkRoot(ctx context.Context, root string, limits *limiter, filePathChan chan<- string) error {
+	args := []string{"ls-tree", "-r", "-z", "-l", g.Rev}
+	if root = path.Clean(strings.TrimPrefix(root, "./")); root != "." {
+		args = append(args, "--", root)
+	} else {
+		root = ""
+	}
+
+	out, err := g.git(ctx, args...)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// Each entry is "<mode> <type> <object> <size>\t<path>"
+		meta, name, found := strings.Cut(entry, "\t")
+		if !found {
+			continue
+		}
+		if IsFileExcluded(name, limits.opts.Excludes) {
+			continue
+		}
+		if !isCandidate(name, limits.opts) {
+			continue
+		}
+		if limits.tooDeep(name, pathDepth(strings.TrimPrefix(name, root))) {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if size, err := strconv.ParseInt(fields[len(fields)-1], 10, 64); err == nil && limits.tooLarge(name, size) {
+			continue
+		}
+		if !limits.admit(name) || !send(ctx, filePathChan, name) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ReadFile returns the content of the blob stored at name in the revision
+// tree. It fails once ctx is done.
+func (g GitRevision) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	return g.git(ctx, "cat-file", "blob", g.object(name))
+}
+
+// Exists returns true if a blob is stored at name in the revision tree, false
+// once ctx is done.
+func (g GitRevision) Exists(ctx context.Context, name string) bool {
+	out, err := g.git(ctx, "cat-file", "-t", g.object(name))
+	return err == nil && strings.TrimSpace(string(out)) == "blob"
+}
+
+// object names the blob at name, relative to Dir as listed by ls-tree rather
+// than to the top of the repository.
+func (g GitRevision) object(name string) string {
+	return g.Rev + ":./" + name
+}
+
+func (g GitRevision) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", g.Dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
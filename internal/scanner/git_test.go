@@ -0,0 +1,181 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// helper to run git inside the test repository
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+// helper to create a repository with one tagged commit
+func createGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	createFile(t, dir, "go.mod")
+	createFile(t, dir, "README.md")
+	appDir := createDir(t, dir, "app")
+	if err := os.WriteFile(filepath.Join(appDir, "package.json"), []byte(`{"dependencies":{"a":"1.0.0"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	nodeModulesDir := createDir(t, appDir, "node_modules")
+	createFile(t, nodeModulesDir, "package.json")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "first")
+	runGit(t, dir, "tag", "v1")
+
+	// Changes after the tag must not be visible when scanning v1
+	createFile(t, dir, "requirements.txt")
+	if err := os.WriteFile(filepath.Join(appDir, "package.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "second")
+	return dir
+}
+
+func TestGitRevision_WalkTree(t *testing.T) {
+	dir := createGitRepo(t)
+	rev := GitRevision{Dir: dir, Rev: "v1"}
+
+	tests := []struct {
+		name          string
+		roots         []string
+		includes      []string
+		excludes      []string
+		expectedPaths []string
+	}{
+		{
+			name:          "whole tree",
+			roots:         []string{"."},
+			expectedPaths: []string{"app/node_modules/package.json", "app/package.json", "go.mod"},
+		},
+		{
+			name:          "exclude node_modules",
+			roots:         []string{"."},
+			excludes:      []string{"/node_modules/"},
+			expectedPaths: []string{"app/package.json", "go.mod"},
+		},
+		{
+			name:          "subdirectory root",
+			roots:         []string{"./app"},
+			includes:      []string{"node"},
+			expectedPaths: []string{"app/node_modules/package.json", "app/package.json"},
+		},
+		{
+			name:          "includes go only",
+			roots:         []string{"."},
+			includes:      []string{"go"},
+			expectedPaths: []string{"go.mod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePathChan := make(chan string)
+			var foundPaths []string
+
+			go rev.WalkTree(context.Background(), tt.roots, WalkOptions{Includes: tt.includes, Excludes: tt.excludes}, filePathChan)
+
+			for path := range filePathChan {
+				foundPaths = append(foundPaths, path)
+			}
+
+			slices.Sort(foundPaths)
+			if !slices.Equal(foundPaths, tt.expectedPaths) {
+				t.Errorf("WalkTree() = %v, want %v", foundPaths, tt.expectedPaths)
+			}
+		})
+	}
+}
+
+func TestGitRevision_ReadFile(t *testing.T) {
+	dir := createGitRepo(t)
+
+	content, err := GitRevision{Dir: dir, Rev: "v1"}.ReadFile(context.Background(), "app/package.json")
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	if string(content) != `{"dependencies":{"a":"1.0.0"}}` {
+		t.Errorf("ReadFile() = %q, want content at v1", content)
+	}
+
+	if _, err := (GitRevision{Dir: dir, Rev: "v1"}).ReadFile(context.Background(), "requirements.txt"); err == nil {
+		t.Error("expected error for file missing at v1")
+	}
+}
+
+func TestGitRevision_Subdirectory(t *testing.T) {
+	dir := createGitRepo(t)
+	rev := GitRevision{Dir: filepath.Join(dir, "app"), Rev: "v1"}
+
+	filePathChan := make(chan string)
+	go rev.WalkTree(context.Background(), []string{"."}, WalkOptions{Excludes: []string{"/node_modules/"}}, filePathChan)
+	var foundPaths []string
+	for path := range filePathChan {
+		foundPaths = append(foundPaths, path)
+	}
+	if !slices.Equal(foundPaths, []string{"package.json"}) {
+		t.Fatalf("WalkTree() = %v, want the paths relative to app", foundPaths)
+	}
+
+	content, err := rev.ReadFile(context.Background(), foundPaths[0])
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	if string(content) != `{"dependencies":{"a":"1.0.0"}}` {
+		t.Errorf("ReadFile() = %q, want app/package.json at v1", content)
+	}
+	if !rev.Exists(context.Background(), "package.json") || rev.Exists(context.Background(), "go.mod") {
+		t.Error("Exists() must resolve names relative to app")
+	}
+}
+
+func TestGitRevision_Exists(t *testing.T) {
+	dir := createGitRepo(t)
+	rev := GitRevision{Dir: dir, Rev: "v1"}
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"go.mod", true},
+		{"app/package.json", true},
+		{"app", false},              // a tree, not a blob
+		{"requirements.txt", false}, // added after v1
+	}
+	for _, tt := range tests {
+		if got := rev.Exists(context.Background(), tt.name); got != tt.expected {
+			t.Errorf("Exists(%q) = %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestGitRevision_Verify(t *testing.T) {
+	dir := createGitRepo(t)
+
+	if err := (GitRevision{Dir: dir, Rev: "v1"}).Verify(); err != nil {
+		t.Errorf("Verify() unexpected error: %v", err)
+	}
+	if err := (GitRevision{Dir: dir, Rev: "no-such-tag"}).Verify(); err == nil {
+		t.Error("Verify() expected error for unknown revision")
+	}
+}
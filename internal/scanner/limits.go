@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+)
+
+// limiter enforces the limits of WalkOptions across the goroutines of a walk.
+type limiter struct {
+	opts     WalkOptions
+	accepted atomic.Int64
+	full     atomic.Bool
+	deep     atomic.Int64           // Number of paths skipped by the maximum depth
+	deepest  atomic.Pointer[string] // First path skipped by the maximum depth
+	sent     sync.Map               // Cleaned paths of the files already sent
+}
+
+func newLimiter(opts WalkOptions) *limiter {
+	return &limiter{opts: opts}
+}
+
+// skip reports a path left out of the scan because of a limit.
+func (l *limiter) skip(path string, limit diagnostic.Code, detail string) {
+	l.opts.Diagnostics.Report(diagnostic.Warnf(limit, path, "skipped by %s (%s)", limit, detail))
+}
+
+// tooDeep returns true if a path at the given depth below its root exceeds
+// the maximum depth. The paths skipped are counted and reported by finish.
+func (l *limiter) tooDeep(path string, depth int) bool {
+	if l.opts.MaxDepth <= 0 || depth <= l.opts.MaxDepth {
+		return false
+	}
+	l.deepest.CompareAndSwap(nil, &path)
+	l.deep.Add(1)
+	return true
+}
+
+// tooLarge returns true, and reports it, if a file of the given size exceeds
+// the maximum file size.
+func (l *limiter) tooLarge(path string, size int64) bool {
+	if l.opts.MaxFileSize <= 0 || size <= l.opts.MaxFileSize {
+		return false
+	}
+	l.skip(path, diagnostic.CodeMaxFileSize, fmt.Sprintf("%d bytes > %d", size, l.opts.MaxFileSize))
+	return true
+}
+
+// admit counts a file about to be sent to the parsers. It returns false once
+// the maximum number of files is reached, reporting only the first rejection.
+func (l *limiter) admit(path string) bool {
+	if l.opts.MaxFiles <= 0 {
+		return true
+	}
+	if l.accepted.Add(1) <= int64(l.opts.MaxFiles) {
+		return true
+	}
+	if l.full.CompareAndSwap(false, true) {
+		l.skip(path, diagnostic.CodeMaxFiles, fmt.Sprintf("more than %d files, remaining files skipped", l.opts.MaxFiles))
+	}
+	return false
+}
+
+// first returns true the first time a file is seen, false when the same file
+// is reached again through overlapping roots or an explicit file.
+func (l *limiter) first(path string) bool {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = filepath.Clean(path)
+	}
+	_, seen := l.sent.LoadOrStore(key, struct{}{})
+	return !seen
+}
+
+// finish reports the paths skipped by the maximum depth as a single warning,
+// located at the first of them, as most of them hold no manifest at all.
+func (l *limiter) finish() {
+	if n := l.deep.Load(); n > 0 {
+		l.skip(*l.deepest.Load(), diagnostic.CodeMaxDepth, fmt.Sprintf("depth > %d, %d paths in total", l.opts.MaxDepth, n))
+	}
+}
+
+// limitReached returns true once a file has been rejected by admit.
+func (l *limiter) limitReached() bool {
+	return l.full.Load()
+}
+
+// pathDepth returns the number of segments of a slash separated relative path.
+func pathDepth(rel string) int {
+	rel = strings.Trim(rel, "/")
+	if rel == "" || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, "/") + 1
+}
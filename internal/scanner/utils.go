@@ -1,55 +1,31 @@
 package scanner
 
-import "strings"
-
-// categoryToFiles maps categories to supported files.
-var categoryToFiles = map[string][]string{
-	"dart":   {"pubspec.yaml"},
-	"go":     {"go.mod"},
-	"node":   {"package.json"},
-	"python": {"requirements.txt"},
-}
-
-// aliasToCategory maps aliases to canonical categories.
-var aliasToCategory = map[string]string{
-	"js":   "node",
-	"ts":   "node",
-	"node": "node", // for consistency
-}
+import "github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
 
 // isFileRequired returns true if the filename is required based on includes.
 func IsFileRequired(filename string, includes []string) bool {
-	// Build set of allowed files.
-	allowedFiles := make(map[string]struct{})
+	return IsPathRequired(filename, includes)
+}
 
-	if len(includes) == 0 {
-		// If includes is empty, all supported files are allowed.
-		for _, files := range categoryToFiles {
-			for _, f := range files {
-				allowedFiles[f] = struct{}{}
-			}
-		}
-	} else {
-		for _, inc := range includes {
-			// Resolve alias if needed.
-			category := resolveCategory(inc)
-			if files, ok := categoryToFiles[category]; ok {
-				for _, f := range files {
-					allowedFiles[f] = struct{}{}
-				}
-			}
+// IsPathRequired returns true if the file at filePath is required based on
+// includes, matching the patterns of the registered ecosystems against its
+// base name and trailing path segments.
+func IsPathRequired(filePath string, includes []string) bool {
+	for _, e := range ecosystem.Select(includes) {
+		if e.MatchPath(filePath) {
+			return true
 		}
 	}
-
-	_, ok := allowedFiles[strings.ToLower(filename)]
-	return ok
+	return false
 }
 
-// resolveCategory resolves an alias to its canonical category.
-func resolveCategory(alias string) string {
-	if cat, ok := aliasToCategory[strings.ToLower(alias)]; ok {
-		return cat
+// IsFileSniffable returns true if the content of a file with an unrecognised
+// name may be sniffed to find out whether it is a required dependency file.
+func IsFileSniffable(filename string, includes []string) bool {
+	for _, e := range ecosystem.Select(includes) {
+		if e.Sniffable(filename) {
+			return true
+		}
 	}
-	// If not an alias, treat it as its own category.
-	return strings.ToLower(alias)
+	return false
 }
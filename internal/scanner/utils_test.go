@@ -1,6 +1,10 @@
 package scanner
 
-import "testing"
+import (
+	"testing"
+
+	_ "github.com/flarebyte/clingy-code-detective/internal/parser" // registers the built-in ecosystems
+)
 
 func TestIsFileRequired(t *testing.T) {
 	tests := []struct {
@@ -116,3 +120,48 @@ func TestIsFileRequired(t *testing.T) {
 		})
 	}
 }
+
+func TestIsPathRequired(t *testing.T) {
+	tests := []struct {
+		name     string
+		includes []string
+		path     string
+		want     bool
+	}{
+		{"requirements with suffix", nil, "api/requirements-dev.txt", true},
+		{"requirements with prefix", []string{"python"}, "dev-requirements.in", true},
+		{"requirements directory", nil, "api/requirements/base.txt", true},
+		{"text file outside requirements directory", nil, "api/docs/base.txt", false},
+		{"package template", []string{"node"}, "app/package.template.json", true},
+		{"package lock is not a manifest", nil, "app/package-lock.json", false},
+		{"pattern of excluded ecosystem", []string{"go"}, "requirements-dev.txt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPathRequired(tt.path, tt.includes); got != tt.want {
+				t.Errorf("IsPathRequired(%q, %v) = %v; want %v", tt.path, tt.includes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsFileSniffable(t *testing.T) {
+	tests := []struct {
+		includes []string
+		filename string
+		want     bool
+	}{
+		{nil, "manifest.json", true},
+		{[]string{"node"}, "manifest.json", true},
+		{[]string{"python"}, "manifest.json", false},
+		{[]string{"dart"}, "app.yml", true},
+		{nil, "main.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsFileSniffable(tt.filename, tt.includes); got != tt.want {
+			t.Errorf("IsFileSniffable(%q, %v) = %v; want %v", tt.filename, tt.includes, got, tt.want)
+		}
+	}
+}
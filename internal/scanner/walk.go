@@ -1,49 +1,176 @@
 package scanner
 
 import (
-	"log"
+	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
 )
 
+// WalkOptions controls which files are sent to the parsers.
+type WalkOptions struct {
+	Includes    []string              // Ecosystems to include, all of them when empty
+	Excludes    []string              // Path segments to exclude
+	Files       []string              // Explicit manifest files, sent without walking
+	Archives    *Archives             // Walks archives as virtual directories when not nil
+	Sniff       bool                  // Also sends files with generic extensions whose content must be sniffed
+	MaxDepth    int                   // Maximum directory depth below a root, 0 for no limit
+	MaxFileSize int64                 // Maximum size of a manifest in bytes, 0 for no limit
+	MaxFiles    int                   // Maximum number of manifests sent, 0 for no limit
+	Jobs        int                   // Maximum number of roots walked at once, the number of CPUs when 0
+	Diagnostics *diagnostic.Collector // Receives walk errors and paths skipped by a limit, may be nil
+}
+
 // WalkDirectories walks the directory trees starting at each root and sends
-// the path of required files into filePathChan. It closes filePathChan when done.
-func WalkDirectories(roots []string, includes []string, excludes []string, filePathChan chan<- string) {
+// the path of required files into filePathChan. The explicit files of opts
+// bypass the walk but are still filtered by includes and excludes. A file
+// reached more than once, through overlapping roots or an explicit file below
+// a root, is only sent once. It closes filePathChan when done, or as soon as
+// ctx is done.
+func WalkDirectories(ctx context.Context, roots []string, opts WalkOptions, filePathChan chan<- string) {
 	var wg sync.WaitGroup
+	limits := newLimiter(opts)
+
+	if len(opts.Files) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sendFiles(ctx, opts.Files, limits, filePathChan)
+		}()
+	}
 
-	for _, root := range roots {
+	// Roots are walked by a bounded number of goroutines
+	rootChan := make(chan string)
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	for range min(jobs, len(roots)) {
 		wg.Add(1)
-		go func(root string) {
+		go func() {
 			defer wg.Done()
-			err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-				if err != nil {
-					log.Printf("Error accessing path %q: %v\n", path, err)
-					return nil
-				}
-				if IsFileExcluded(path, excludes) {
-					if d.IsDir() {
-						return filepath.SkipDir
-					}
-					return nil
-				}
-				if d.IsDir() {
-					return nil
-				}
-				if IsFileRequired(d.Name(), includes) {
-					filePathChan <- path
-				}
-				return nil
-			})
-			if err != nil {
-				log.Printf("Walk error: %v\n", err)
+			for root := range rootChan {
+				walkRoot(ctx, root, limits, filePathChan)
 			}
-		}(root)
+		}()
 	}
+	go func() {
+		defer close(rootChan)
+		for _, root := range roots {
+			select {
+			case rootChan <- root:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
 	// Close the channel after all goroutines finish
 	go func() {
 		wg.Wait()
+		limits.finish()
 		close(filePathChan)
 	}()
 }
+
+// walkRoot walks the directory tree or the archive at root.
+func walkRoot(ctx context.Context, root string, limits *limiter, filePathChan chan<- string) {
+	opts := limits.opts
+	excludes, archives := opts.Excludes, opts.Archives
+	if archives != nil && IsArchive(root) {
+		if err := archives.walk(ctx, root, limits, filePathChan); err != nil {
+			opts.Diagnostics.Report(diagnostic.Errorf(diagnostic.CodeWalk, root, "%v", err))
+		}
+		return
+	}
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			opts.Diagnostics.Report(diagnostic.Errorf(diagnostic.CodeWalk, path, "%v", err))
+			return nil
+		}
+		if limits.limitReached() {
+			return filepath.SkipAll
+		}
+		if IsFileExcluded(path, excludes) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			// Files directly below a directory at the maximum depth
+			// would be too deep, so the directory is not entered.
+			if path != root && limits.tooDeep(path, relativeDepth(root, path)+1) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if archives != nil && archives.Discover && IsArchive(d.Name()) {
+			if err := archives.walk(ctx, path, limits, filePathChan); err != nil {
+				opts.Diagnostics.Report(diagnostic.Errorf(diagnostic.CodeWalk, path, "%v", err))
+			}
+			return nil
+		}
+		if !isCandidate(path, opts) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && limits.tooLarge(path, info.Size()) {
+			return nil
+		}
+		if !limits.first(path) {
+			return nil
+		}
+		if !limits.admit(path) || !send(ctx, filePathChan, path) {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		opts.Diagnostics.Report(diagnostic.Errorf(diagnostic.CodeWalk, root, "%v", err))
+	}
+}
+
+// send sends path into filePathChan, or returns false if ctx is done first.
+func send(ctx context.Context, filePathChan chan<- string, path string) bool {
+	select {
+	case filePathChan <- path:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sendFiles sends the required files of an explicit list into filePathChan.
+func sendFiles(ctx context.Context, files []string, limits *limiter, filePathChan chan<- string) {
+	for _, file := range files {
+		if IsFileExcluded(file, limits.opts.Excludes) {
+			continue
+		}
+		if !isCandidate(file, limits.opts) || !limits.first(file) {
+			continue
+		}
+		if !limits.admit(file) || !send(ctx, filePathChan, file) {
+			return
+		}
+	}
+}
+
+// isCandidate returns true if the file should be sent to the parsers.
+func isCandidate(filePath string, opts WalkOptions) bool {
+	return IsPathRequired(filePath, opts.Includes) || (opts.Sniff && IsFileSniffable(filePath, opts.Includes))
+}
+
+// relativeDepth returns the depth of path below root.
+func relativeDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return 0
+	}
+	return pathDepth(filepath.ToSlash(rel))
+}
@@ -1,10 +1,13 @@
 package scanner
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"slices"
 	"testing"
+
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
 )
 
 // helper to create a file
@@ -125,7 +128,7 @@ func TestWalkDirectories(t *testing.T) {
 			filePathChan := make(chan string)
 			var foundPaths []string
 
-			go WalkDirectories([]string{tmpDir}, tt.includes, tt.excludes, filePathChan)
+			go WalkDirectories(context.Background(), []string{tmpDir}, WalkOptions{Includes: tt.includes, Excludes: tt.excludes}, filePathChan)
 
 			for path := range filePathChan {
 				foundPaths = append(foundPaths, path)
@@ -148,3 +151,209 @@ func TestWalkDirectories(t *testing.T) {
 
 	}
 }
+
+func TestWalkDirectories_ExplicitFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	packageJSON := createFile(t, tmpDir, "package.json")
+	goMod := createFile(t, tmpDir, "go.mod")
+	readme := createFile(t, tmpDir, "README.md")
+	vendorDir := createDir(t, tmpDir, "vendor")
+	vendorFile := createFile(t, vendorDir, "go.mod")
+
+	files := []string{packageJSON, goMod, readme, vendorFile}
+	filePathChan := make(chan string)
+	var foundPaths []string
+
+	go WalkDirectories(context.Background(), nil, WalkOptions{
+		Includes: []string{"go"},
+		Excludes: []string{"/vendor/"},
+		Files:    files,
+	}, filePathChan)
+
+	for path := range filePathChan {
+		foundPaths = append(foundPaths, path)
+	}
+
+	if !slices.Equal(foundPaths, []string{goMod}) {
+		t.Errorf("WalkDirectories() = %v, want [%s]", foundPaths, goMod)
+	}
+}
+
+func TestWalkDirectories_Limits(t *testing.T) {
+	tmpDir := t.TempDir()
+	rootFile := createFile(t, tmpDir, "package.json")
+	levelOne := createDir(t, tmpDir, "one")
+	levelOneFile := createFile(t, levelOne, "go.mod")
+	levelTwo := createDir(t, levelOne, "two")
+	_ = createFile(t, levelTwo, "pubspec.yaml")
+	// Directories without manifests skipped by the maximum depth only add to
+	// the count of the single max-depth warning
+	levelOther := createDir(t, levelOne, "other")
+	bigFile := filepath.Join(tmpDir, "requirements.txt")
+	if err := os.WriteFile(bigFile, make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		opts          WalkOptions
+		expectedPaths []string
+		expectedSkips []string
+	}{
+		{
+			name:          "max depth",
+			opts:          WalkOptions{MaxDepth: 2},
+			expectedPaths: []string{rootFile, levelOneFile, bigFile},
+			expectedSkips: []string{string(diagnostic.CodeMaxDepth) + " " + levelOther},
+		},
+		{
+			name:          "max file size",
+			opts:          WalkOptions{MaxFileSize: 1024},
+			expectedPaths: []string{rootFile, levelOneFile, filepath.Join(levelTwo, "pubspec.yaml")},
+			expectedSkips: []string{string(diagnostic.CodeMaxFileSize) + " " + bigFile},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Diagnostics = &diagnostic.Collector{}
+			filePathChan := make(chan string)
+			var foundPaths []string
+
+			go WalkDirectories(context.Background(), []string{tmpDir}, tt.opts, filePathChan)
+
+			for path := range filePathChan {
+				foundPaths = append(foundPaths, path)
+			}
+
+			slices.Sort(foundPaths)
+			slices.Sort(tt.expectedPaths)
+			if !slices.Equal(foundPaths, tt.expectedPaths) {
+				t.Errorf("WalkDirectories() = %v, want %v", foundPaths, tt.expectedPaths)
+			}
+			var skips []string
+			for _, d := range tt.opts.Diagnostics.Diagnostics() {
+				skips = append(skips, string(d.Code)+" "+d.Path)
+			}
+			if !slices.Equal(skips, tt.expectedSkips) {
+				t.Errorf("skipped = %v, want %v", skips, tt.expectedSkips)
+			}
+		})
+	}
+
+	t.Run("max files", func(t *testing.T) {
+		diags := &diagnostic.Collector{}
+		opts := WalkOptions{MaxFiles: 2, Diagnostics: diags}
+		filePathChan := make(chan string)
+		count := 0
+
+		go WalkDirectories(context.Background(), []string{tmpDir}, opts, filePathChan)
+
+		for range filePathChan {
+			count++
+		}
+
+		if count != 2 {
+			t.Errorf("expected 2 files, got %d", count)
+		}
+		if skips := diags.Diagnostics(); len(skips) != 1 || skips[0].Code != diagnostic.CodeMaxFiles {
+			t.Errorf("expected a single max-files warning, got %v", skips)
+		}
+	})
+}
+
+func TestWalkDirectories_MissingRoot(t *testing.T) {
+	diags := &diagnostic.Collector{}
+	missing := filepath.Join(t.TempDir(), "missing")
+	filePathChan := make(chan string)
+
+	go WalkDirectories(context.Background(), []string{missing}, WalkOptions{Diagnostics: diags}, filePathChan)
+	for range filePathChan {
+	}
+
+	got := diags.Diagnostics()
+	if len(got) != 1 || got[0].Code != diagnostic.CodeWalk || got[0].Severity != diagnostic.Error || got[0].Path != missing {
+		t.Errorf("expected a walk error for the missing root, got %+v", got)
+	}
+}
+
+func TestWalkDirectories_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"a", "b", "c"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, dir, "package.json"), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	diags := &diagnostic.Collector{}
+	ctx, cancel := context.WithCancel(context.Background())
+	filePathChan := make(chan string)
+
+	go WalkDirectories(ctx, []string{tmpDir}, WalkOptions{Diagnostics: diags}, filePathChan)
+	<-filePathChan
+	cancel()
+	// The channel is closed once the walk notices the cancellation
+	for range filePathChan {
+	}
+
+	if got := diags.Diagnostics(); len(got) != 0 {
+		t.Errorf("expected no diagnostics after cancellation, got %+v", got)
+	}
+}
+
+func TestWalkDirectories_Jobs(t *testing.T) {
+	tmpDir := t.TempDir()
+	var roots, want []string
+	for _, dir := range []string{"a", "b", "c", "d", "e"} {
+		root := filepath.Join(tmpDir, dir)
+		if err := os.MkdirAll(root, 0755); err != nil {
+			t.Fatal(err)
+		}
+		manifest := filepath.Join(root, "package.json")
+		if err := os.WriteFile(manifest, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, root)
+		want = append(want, manifest)
+	}
+
+	for _, jobs := range []int{0, 1, 2, 10} {
+		filePathChan := make(chan string)
+		go WalkDirectories(context.Background(), roots, WalkOptions{Jobs: jobs}, filePathChan)
+		var found []string
+		for path := range filePathChan {
+			found = append(found, path)
+		}
+		slices.Sort(found)
+		if !slices.Equal(found, want) {
+			t.Errorf("jobs %d: WalkDirectories() = %v, want %v", jobs, found, want)
+		}
+	}
+}
+
+func TestWalkDirectories_Deduplicate(t *testing.T) {
+	tmpDir := t.TempDir()
+	packageJSON := createFile(t, tmpDir, "package.json")
+	subDir := createDir(t, tmpDir, "sub")
+	goMod := createFile(t, subDir, "go.mod")
+
+	filePathChan := make(chan string)
+	var foundPaths []string
+
+	go WalkDirectories(context.Background(), []string{tmpDir, subDir}, WalkOptions{
+		Files: []string{packageJSON, filepath.Join(subDir, "..", "package.json")},
+	}, filePathChan)
+
+	for path := range filePathChan {
+		foundPaths = append(foundPaths, filepath.Clean(path))
+	}
+
+	slices.Sort(foundPaths)
+	want := []string{packageJSON, goMod}
+	slices.Sort(want)
+	if !slices.Equal(foundPaths, want) {
+		t.Errorf("WalkDirectories() = %v, want %v", foundPaths, want)
+	}
+}
@@ -0,0 +1,322 @@
+// Package clingy runs the clingy command line tool. Programs embedding clingy
+// can register additional ecosystems with the ecosystem package before
+// calling Main.
+package clingy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/flarebyte/clingy-code-detective/internal/aggregator"
+	"github.com/flarebyte/clingy-code-detective/internal/cli"
+	"github.com/flarebyte/clingy-code-detective/internal/config"
+	"github.com/flarebyte/clingy-code-detective/internal/diagnostic"
+	"github.com/flarebyte/clingy-code-detective/internal/parser"
+	"github.com/flarebyte/clingy-code-detective/internal/scanner"
+)
+
+// fail prints a diagnostic which prevents the scan from completing and exits
+// with its exit code.
+func fail(d diagnostic.Diagnostic) {
+	fmt.Fprintln(os.Stderr, d)
+	os.Exit(d.Code.ExitCode())
+}
+
+// scanContext returns the context of the scan, cancelled once the timeout
+// elapses, when set, or by the first interrupt or termination signal. A
+// second signal terminates the process as usual.
+func scanContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			cancel(fmt.Errorf("interrupted by %v", sig))
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+	if timeout <= 0 {
+		return ctx, func() { cancel(nil) }
+	}
+	timeoutCtx, cancelTimeout := context.WithTimeoutCause(ctx, timeout, fmt.Errorf("timed out after %s", timeout))
+	return timeoutCtx, func() {
+		cancelTimeout()
+		cancel(nil)
+	}
+}
+
+// Main parses the command line arguments, scans the dependency files and
+// prints the report with its diagnostics, exiting the process on error.
+func Main() {
+	cfg, err := cli.ParseArgs()
+	if err != nil {
+		fail(diagnostic.Errorf(diagnostic.CodeUsage, "", "%v", err))
+	}
+
+	if cfg.ShowHelp || cfg.ShowVer {
+		// Help/version already displayed by cli package
+		os.Exit(0)
+	}
+
+	if err := cfg.ResolveInputs(os.Stdin); err != nil {
+		fail(diagnostic.Errorf(diagnostic.CodeUsage, "", "%v", err))
+	}
+
+	if cfg.ConfigPath != "" {
+		fileCfg, err := config.Load(cfg.ConfigPath)
+		if err == nil {
+			err = fileCfg.Register()
+		}
+		if err != nil {
+			fail(diagnostic.Errorf(diagnostic.CodeUsage, cfg.ConfigPath, "%v", err))
+		}
+	}
+
+	var flatRenderer aggregator.FlatRenderer
+	var aggregateRenderer aggregator.AggregateRenderer
+	var driftRenderer aggregator.DriftRenderer
+	var projectRenderer aggregator.ProjectRenderer
+	var internalRenderer aggregator.InternalRenderer
+	var graphRenderer aggregator.GraphRenderer
+	var treeRenderer aggregator.TreeRenderer
+	var whyRenderer aggregator.WhyRenderer
+	var duplicateRenderer aggregator.DuplicateRenderer
+	var newStream func(io.Writer) aggregator.FlatStream
+
+	switch cfg.Format {
+	case "json":
+		flatRenderer = &aggregator.JSONRenderer{}
+		aggregateRenderer = &aggregator.JSONAggregateRenderer{}
+		driftRenderer = &aggregator.JSONDriftRenderer{}
+		projectRenderer = &aggregator.JSONProjectRenderer{}
+		internalRenderer = &aggregator.JSONInternalRenderer{}
+		treeRenderer = &aggregator.JSONTreeRenderer{}
+		whyRenderer = &aggregator.JSONWhyRenderer{Package: cfg.Why}
+		duplicateRenderer = &aggregator.JSONDuplicateRenderer{}
+		newStream = aggregator.NewJSONStream
+	case "csv":
+		flatRenderer = &aggregator.CSVRenderer{}
+		aggregateRenderer = &aggregator.CSVAggregateRenderer{GroupBy: cfg.GroupBy}
+		driftRenderer = &aggregator.CSVDriftRenderer{GroupBy: cfg.GroupBy}
+		projectRenderer = &aggregator.CSVProjectRenderer{}
+		internalRenderer = &aggregator.CSVInternalRenderer{}
+		treeRenderer = &aggregator.CSVTreeRenderer{}
+		whyRenderer = &aggregator.CSVWhyRenderer{}
+		duplicateRenderer = &aggregator.CSVDuplicateRenderer{}
+		newStream = aggregator.NewCSVStream
+	case "md":
+		flatRenderer = &aggregator.MarkdownRenderer{}
+		aggregateRenderer = &aggregator.MarkdownAggregateRenderer{GroupBy: cfg.GroupBy}
+		driftRenderer = &aggregator.MarkdownDriftRenderer{GroupBy: cfg.GroupBy}
+		projectRenderer = &aggregator.MarkdownProjectRenderer{}
+		internalRenderer = &aggregator.MarkdownInternalRenderer{}
+		treeRenderer = &aggregator.MarkdownTreeRenderer{}
+		whyRenderer = &aggregator.MarkdownWhyRenderer{Package: cfg.Why}
+		duplicateRenderer = &aggregator.MarkdownDuplicateRenderer{}
+		newStream = aggregator.NewMarkdownStream
+	case "dot":
+		graphRenderer = &aggregator.DOTRenderer{}
+	case "mermaid":
+		graphRenderer = &aggregator.MermaidRenderer{}
+	case "graphml":
+		graphRenderer = &aggregator.GraphMLRenderer{}
+	default:
+		fail(diagnostic.Errorf(diagnostic.CodeUsage, "", "unknown format: %s", cfg.Format))
+	}
+
+	diags := &diagnostic.Collector{}
+	ctx, stop := scanContext(cfg.Timeout)
+
+	numWorkers := cfg.Jobs
+	if numWorkers <= 0 {
+		numWorkers = runtime.NumCPU()
+	}
+
+	// Small buffers keep the walk and the workers busy without holding many
+	// results in memory
+	filePathChan := make(chan string, numWorkers)
+	resultChan := make(chan parser.DependencyFile, numWorkers)
+
+	var wg sync.WaitGroup
+	var read parser.ContentReader
+	var exists parser.FileExists
+	walkOpts := scanner.WalkOptions{
+		Includes:    cfg.Includes,
+		Excludes:    cfg.Excludes,
+		Files:       cfg.Files,
+		Sniff:       cfg.Sniff,
+		MaxDepth:    cfg.MaxDepth,
+		MaxFileSize: cfg.MaxFileSize,
+		MaxFiles:    cfg.MaxFiles,
+		Jobs:        cfg.WalkJobs,
+		Diagnostics: diags,
+	}
+	if cfg.GitRev != "" {
+		rev := scanner.GitRevision{Dir: cfg.GitDir, Rev: cfg.GitRev}
+		if err := rev.Verify(); err != nil {
+			fail(diagnostic.Errorf(diagnostic.CodeUsage, cfg.GitDir, "%v", err))
+		}
+		read = func(name string) ([]byte, error) { return rev.ReadFile(ctx, name) }
+		exists = func(name string) bool { return rev.Exists(ctx, name) }
+		go rev.WalkTree(ctx, cfg.Paths, walkOpts, filePathChan)
+	} else {
+		archives := scanner.NewArchives(cfg.Archives)
+		archives.ReadLocal = parser.ReadFileLimit(cfg.MaxFileSize)
+		read = archives.ReadFile
+		exists = archives.Exists
+		walkOpts.Archives = archives
+		go scanner.WalkDirectories(ctx, cfg.Paths, walkOpts, filePathChan)
+	}
+
+	parseOpts := parser.Options{Read: read, Exists: exists, Tolerant: cfg.Tolerant}
+
+	//Parse each file with a pool of workers
+	for range numWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parser.ProduceDependencyFile(ctx, parseOpts, filePathChan, resultChan)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	// Workers blocked on a file are not waited for once the scan is cancelled
+	if cfg.Stream {
+		out := bufio.NewWriter(os.Stdout)
+		stream := newStream(out)
+		err := aggregator.StreamDependencies(ctx, resultChan, diags, stream)
+		stop()
+		diagnostics := printDiagnostics(diags)
+		if err == nil {
+			err = stream.Close(diagnostics)
+		}
+		if err == nil {
+			_, err = fmt.Fprintln(out)
+		}
+		if err == nil {
+			err = out.Flush()
+		}
+		if err != nil {
+			fail(diagnostic.Errorf(diagnostic.CodeOutput, "", "failed to stream dependencies: %v", err))
+		}
+		os.Exit(diagnostic.ExitCode(diagnostics, cfg.Strict))
+	}
+
+	if cfg.Tree || cfg.Why != "" || cfg.Duplicates {
+		done := make(chan []aggregator.ProjectDependencies, 1)
+		go aggregator.CollectProjects(ctx, resultChan, diags, done)
+		projects := <-done
+		// Lockfiles are read once the scan has found the projects, within
+		// the same timeout
+		resolved := aggregator.ResolveProjects(ctx, projects, parseOpts, diags)
+		stop()
+
+		diagnostics := printDiagnostics(diags)
+		var output []byte
+		var err error
+		switch {
+		case cfg.Tree:
+			output, err = treeRenderer.Render(aggregator.DependencyTrees(resolved), diagnostics)
+		case cfg.Duplicates:
+			output, err = duplicateRenderer.Render(aggregator.FindDuplicates(resolved), diagnostics)
+		default:
+			output, err = whyRenderer.Render(aggregator.WhyPackage(resolved, cfg.Why), diagnostics)
+		}
+		if err != nil {
+			fail(diagnostic.Errorf(diagnostic.CodeOutput, "", "failed to render resolved dependencies: %v", err))
+		}
+
+		fmt.Println(string(output))
+		os.Exit(diagnostic.ExitCode(diagnostics, cfg.Strict))
+	}
+
+	if cfg.ByProject || cfg.Internal || graphRenderer != nil {
+		done := make(chan []aggregator.ProjectDependencies, 1)
+		go aggregator.CollectProjects(ctx, resultChan, diags, done)
+		projects := <-done
+		stop()
+
+		diagnostics := printDiagnostics(diags)
+		var output []byte
+		var err error
+		switch {
+		case graphRenderer != nil:
+			output, err = graphRenderer.Render(aggregator.BuildGraph(projects, cfg.Graph), diagnostics)
+		case cfg.Internal:
+			output, err = internalRenderer.Render(aggregator.InternalDependencies(projects), diagnostics)
+		default:
+			output, err = projectRenderer.Render(projects, diagnostics)
+		}
+		if err != nil {
+			fail(diagnostic.Errorf(diagnostic.CodeOutput, "", "failed to render dependencies per project: %v", err))
+		}
+
+		fmt.Println(string(output))
+		os.Exit(diagnostic.ExitCode(diagnostics, cfg.Strict))
+	}
+
+	done := make(chan []aggregator.FlatDependency, 1)
+	go aggregator.CollectDependencies(ctx, resultChan, diags, done)
+	flatDependencies := <-done
+	stop()
+
+	// Render output
+	diagnostics := printDiagnostics(diags)
+
+	grouping := aggregator.Grouping{Keys: cfg.GroupBy, Roots: cfg.Paths}
+
+	if cfg.Drift {
+		drifts := aggregator.DetectDrift(aggregator.GroupDependencies(flatDependencies, grouping), aggregator.DriftTarget(cfg.DriftTarget))
+
+		output, err := driftRenderer.Render(drifts, diagnostics)
+		if err != nil {
+			fail(diagnostic.Errorf(diagnostic.CodeOutput, "", "failed to render version drift: %v", err))
+		}
+
+		fmt.Println(string(output))
+
+	} else if cfg.Aggregate {
+		aggegateDependencies := aggregator.GroupDependencies(flatDependencies, grouping)
+
+		output, err := aggregateRenderer.Render(aggegateDependencies, diagnostics)
+		if err != nil {
+			fail(diagnostic.Errorf(diagnostic.CodeOutput, "", "failed to render dependencies after aggregation: %v", err))
+		}
+
+		fmt.Println(string(output))
+
+	} else {
+		output, err := flatRenderer.Render(flatDependencies, diagnostics)
+		if err != nil {
+			fail(diagnostic.Errorf(diagnostic.CodeOutput, "", "failed to render dependencies: %v", err))
+		}
+
+		fmt.Println(string(output))
+
+	}
+
+	os.Exit(diagnostic.ExitCode(diagnostics, cfg.Strict))
+}
+
+// printDiagnostics prints the diagnostics of the scan on stderr and returns
+// them.
+func printDiagnostics(diags *diagnostic.Collector) []diagnostic.Diagnostic {
+	diagnostics := diags.Diagnostics()
+	for _, d := range diagnostics {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	return diagnostics
+}
@@ -0,0 +1,318 @@
+// Package ecosystem is the registry of the dependency file formats understood
+// by clingy. The built-in ecosystems are registered when the clingy packages
+// are imported, and programs embedding clingy may register their own formats
+// before running it.
+package ecosystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Dependency represents a single declared dependency.
+type Dependency struct {
+	Name     string
+	Version  string
+	Category string // e.g., "prod", "dev"
+	Line     int    // 1-based line of the declaration, 0 when unknown
+	Column   int    // 1-based byte column of the declaration, 0 when unknown
+	// LocalPath is the directory of a local package used instead of a
+	// published version, e.g. ../lib for a path dependency.
+	LocalPath string
+}
+
+// Parser is implemented by each language-specific dependency file parser.
+type Parser interface {
+	Parse(content []byte) ([]Dependency, error)
+}
+
+// PathParser is implemented by parsers which need the path of the file in
+// addition to its content. It is used instead of Parse when available.
+type PathParser interface {
+	ParsePath(path string, content []byte) ([]Dependency, error)
+}
+
+// ContextParser is implemented by parsers which may block, such as parsers
+// running external commands, so that a cancelled scan does not wait for them.
+// It is used instead of ParsePath and Parse when available.
+type ContextParser interface {
+	ParseContext(ctx context.Context, path string, content []byte) ([]Dependency, error)
+}
+
+// Recoverer is implemented by parsers able to extract the dependencies still
+// readable from a file their Parse method rejects, such as a manifest with
+// merge conflict markers. It is used in tolerant mode only.
+type Recoverer interface {
+	Recover(content []byte) ([]Dependency, error)
+}
+
+// Identity is the name and version a manifest declares for its own project.
+type Identity struct {
+	Name    string
+	Version string
+}
+
+// IdentityReader is implemented by parsers able to read the identity of the
+// project declared by a manifest, such as the module of a go.mod.
+type IdentityReader interface {
+	ReadIdentity(content []byte) (Identity, error)
+}
+
+// ResolvedPackage is a package installed by a lockfile.
+type ResolvedPackage struct {
+	ID           string // Unique within the lockfile, e.g. node_modules/a/node_modules/b
+	Name         string
+	Version      string
+	Dependencies []string // IDs of the packages it depends on
+}
+
+// Resolution is the dependency graph resolved by a lockfile.
+type Resolution struct {
+	Roots    []string // IDs of the direct dependencies of the project
+	Packages []ResolvedPackage
+	// Unlinked is true when the lockfile lists the installed packages
+	// without the dependencies between them, as pubspec.lock and go.sum do.
+	Unlinked bool
+}
+
+// LockfileReader is implemented by parsers able to read the lockfiles of
+// their ecosystem. The dependencies declared by the manifests of the project
+// give the direct dependencies of lockfiles which do not record them.
+type LockfileReader interface {
+	ReadLockfile(path string, content []byte, declared []Dependency) (Resolution, error)
+}
+
+// Ecosystem describes the dependency files of one ecosystem and how to parse
+// them.
+type Ecosystem struct {
+	// Name is the canonical name, used by --include and reported as the
+	// packaging of each dependency, e.g. "node".
+	Name string
+	// Aliases are alternative names accepted by --include, e.g. "js".
+	Aliases []string
+	// Patterns are matched case-insensitively with path.Match against the
+	// base name of a file, or against its trailing path segments when they
+	// contain a /, e.g. "package.json" or "requirements/*.txt".
+	Patterns []string
+	// CategoryFromName replaces the category of every dependency with the
+	// one inferred from the filename, for formats which do not declare
+	// categories themselves.
+	CategoryFromName bool
+	// Lockfiles are the names of the lockfiles which may be found next to a
+	// manifest, in order of preference, e.g. "package-lock.json".
+	Lockfiles []string
+	// SniffExtensions lists the extensions, e.g. ".json", of files whose
+	// content is passed to Sniff when their name matches no pattern.
+	SniffExtensions []string
+	// Sniff returns true if the content is a dependency file of the
+	// ecosystem. It is optional.
+	Sniff func(content []byte) bool
+	// NewParser returns the parser of the dependency files.
+	NewParser func() Parser
+}
+
+var (
+	mu         sync.RWMutex
+	ecosystems = map[string]Ecosystem{}
+	aliases    = map[string]string{}
+	registered []string // Names in registration order
+)
+
+// Register adds an ecosystem to the registry. It fails if the name or an
+// alias is already registered, or if the ecosystem cannot match any file.
+func Register(e Ecosystem) error {
+	if err := validate(e); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	name := strings.ToLower(e.Name)
+	names := append([]string{name}, lowerAll(e.Aliases)...)
+	for _, n := range names {
+		if _, ok := aliases[n]; ok {
+			return fmt.Errorf("ecosystem %q: %q is already registered", e.Name, n)
+		}
+	}
+	for _, n := range names {
+		aliases[n] = name
+	}
+	e.Name = name
+	e.Patterns = lowerAll(e.Patterns)
+	e.SniffExtensions = lowerAll(e.SniffExtensions)
+	ecosystems[name] = e
+	registered = append(registered, name)
+	return nil
+}
+
+// MustRegister is like Register but panics on error. It is meant for
+// registrations in init functions.
+func MustRegister(e Ecosystem) {
+	if err := Register(e); err != nil {
+		panic(err)
+	}
+}
+
+func validate(e Ecosystem) error {
+	if e.Name == "" {
+		return errors.New("ecosystem name is required")
+	}
+	if e.NewParser == nil {
+		return fmt.Errorf("ecosystem %q: NewParser is required", e.Name)
+	}
+	if len(e.Patterns) == 0 && (e.Sniff == nil || len(e.SniffExtensions) == 0) {
+		return fmt.Errorf("ecosystem %q: patterns or sniffing are required", e.Name)
+	}
+	for _, pattern := range e.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("ecosystem %q: pattern %q: %w", e.Name, pattern, err)
+		}
+	}
+	return nil
+}
+
+// All returns the registered ecosystems sorted by name.
+func All() []Ecosystem {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	all := make([]Ecosystem, 0, len(ecosystems))
+	for _, e := range ecosystems {
+		all = append(all, e)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// Lookup returns the ecosystem registered under a name or an alias.
+func Lookup(nameOrAlias string) (Ecosystem, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	name, ok := aliases[strings.ToLower(nameOrAlias)]
+	if !ok {
+		return Ecosystem{}, false
+	}
+	return ecosystems[name], true
+}
+
+// Select returns the ecosystems named by includes, resolving aliases and
+// ignoring unknown names, or all of them if includes is empty.
+func Select(includes []string) []Ecosystem {
+	if len(includes) == 0 {
+		return All()
+	}
+	var selected []Ecosystem
+	seen := make(map[string]struct{})
+	for _, inc := range includes {
+		e, ok := Lookup(inc)
+		if !ok {
+			continue
+		}
+		if _, dup := seen[e.Name]; dup {
+			continue
+		}
+		seen[e.Name] = struct{}{}
+		selected = append(selected, e)
+	}
+	return selected
+}
+
+// byPrecedence returns the registered ecosystems, the last registered first,
+// so that the ecosystems registered by a configuration file or a program
+// embedding clingy take precedence over the built-in ones.
+func byPrecedence() []Ecosystem {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	ordered := make([]Ecosystem, 0, len(registered))
+	for i := len(registered) - 1; i >= 0; i-- {
+		ordered = append(ordered, ecosystems[registered[i]])
+	}
+	return ordered
+}
+
+// Match returns the ecosystem whose patterns match the path, the last
+// registered first, so that plugins may override the built-in patterns.
+func Match(filePath string) (Ecosystem, bool) {
+	for _, e := range byPrecedence() {
+		if e.MatchPath(filePath) {
+			return e, true
+		}
+	}
+	return Ecosystem{}, false
+}
+
+// Sniff returns the ecosystem recognising the content of a file with one of
+// its sniff extensions, the last registered first.
+func Sniff(filePath string, content []byte) (Ecosystem, bool) {
+	for _, e := range byPrecedence() {
+		if e.Sniffable(filePath) && e.Sniff(content) {
+			return e, true
+		}
+	}
+	return Ecosystem{}, false
+}
+
+// MatchPath returns true if one of the patterns matches the path.
+func (e Ecosystem) MatchPath(filePath string) bool {
+	return e.matchDepth(filePath) > 0
+}
+
+// Root returns the root directory of the project owning a dependency file:
+// the directory above the trailing segments matched by the deepest pattern,
+// e.g. api for api/requirements/dev.txt, or the directory of the file when no
+// pattern matches it.
+func (e Ecosystem) Root(filePath string) string {
+	root := filepath.Dir(filePath)
+	for range e.matchDepth(filePath) - 1 {
+		root = filepath.Dir(root)
+	}
+	return root
+}
+
+// matchDepth returns the number of trailing path segments matched by the
+// deepest matching pattern, 0 when none matches.
+func (e Ecosystem) matchDepth(filePath string) int {
+	segments := strings.Split(strings.ToLower(filepath.ToSlash(filePath)), "/")
+	matched := 0
+	for _, pattern := range e.Patterns {
+		depth := strings.Count(pattern, "/") + 1
+		if depth > len(segments) || depth <= matched {
+			continue
+		}
+		if ok, _ := path.Match(pattern, path.Join(segments[len(segments)-depth:]...)); ok {
+			matched = depth
+		}
+	}
+	return matched
+}
+
+// Sniffable returns true if the content of the file may be sniffed.
+func (e Ecosystem) Sniffable(filePath string) bool {
+	if e.Sniff == nil {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(filePath))
+	for _, candidate := range e.SniffExtensions {
+		if ext == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(v)
+	}
+	return lowered
+}
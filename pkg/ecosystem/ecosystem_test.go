@@ -0,0 +1,143 @@
+package ecosystem
+
+import (
+	"strings"
+	"testing"
+)
+
+type lineParser struct{}
+
+func (lineParser) Parse(content []byte) ([]Dependency, error) {
+	var deps []Dependency
+	for _, line := range strings.Fields(string(content)) {
+		deps = append(deps, Dependency{Name: line, Category: "prod"})
+	}
+	return deps, nil
+}
+
+func TestRegister(t *testing.T) {
+	newParser := func() Parser { return lineParser{} }
+
+	tests := []struct {
+		name    string
+		e       Ecosystem
+		wantErr string
+	}{
+		{
+			name: "valid ecosystem",
+			e:    Ecosystem{Name: "Acme", Aliases: []string{"acme-deps"}, Patterns: []string{"acme.deps"}, NewParser: newParser},
+		},
+		{
+			name:    "duplicate name",
+			e:       Ecosystem{Name: "acme", Patterns: []string{"other.deps"}, NewParser: newParser},
+			wantErr: "already registered",
+		},
+		{
+			name:    "duplicate alias",
+			e:       Ecosystem{Name: "acme2", Aliases: []string{"ACME-DEPS"}, Patterns: []string{"other.deps"}, NewParser: newParser},
+			wantErr: "already registered",
+		},
+		{
+			name:    "missing name",
+			e:       Ecosystem{Patterns: []string{"x"}, NewParser: newParser},
+			wantErr: "name is required",
+		},
+		{
+			name:    "missing parser",
+			e:       Ecosystem{Name: "noparser", Patterns: []string{"x"}},
+			wantErr: "NewParser is required",
+		},
+		{
+			name:    "nothing to match",
+			e:       Ecosystem{Name: "nomatch", NewParser: newParser},
+			wantErr: "patterns or sniffing are required",
+		},
+		{
+			name:    "invalid pattern",
+			e:       Ecosystem{Name: "badpattern", Patterns: []string{"[x"}, NewParser: newParser},
+			wantErr: "syntax error in pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Register(tt.e)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Register() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Register() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	e, ok := Lookup("ACME-deps")
+	if !ok || e.Name != "acme" {
+		t.Fatalf("Lookup() = %q, %v; want acme", e.Name, ok)
+	}
+	if _, ok := Lookup("acme2"); ok {
+		t.Error("Lookup() found an ecosystem whose registration failed")
+	}
+
+	matched, ok := Match("tools/ACME.deps")
+	if !ok || matched.Name != "acme" {
+		t.Errorf("Match() = %q, %v; want acme", matched.Name, ok)
+	}
+}
+
+func TestMatch_Precedence(t *testing.T) {
+	MustRegister(Ecosystem{Name: "builtin-a", Patterns: []string{"shared.deps"}, NewParser: func() Parser { return lineParser{} }})
+	MustRegister(Ecosystem{Name: "override-a", Patterns: []string{"shared.deps"}, NewParser: func() Parser { return lineParser{} }})
+
+	matched, ok := Match("shared.deps")
+	if !ok || matched.Name != "override-a" {
+		t.Errorf("Match() = %q, %v; want override-a, the last registered", matched.Name, ok)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	MustRegister(Ecosystem{Name: "select-a", Aliases: []string{"sa"}, Patterns: []string{"a.deps"}, NewParser: func() Parser { return lineParser{} }})
+	MustRegister(Ecosystem{Name: "select-b", Patterns: []string{"b.deps"}, NewParser: func() Parser { return lineParser{} }})
+
+	selected := Select([]string{"sa", "select-a", "unknown", "select-b"})
+	if len(selected) != 2 || selected[0].Name != "select-a" || selected[1].Name != "select-b" {
+		t.Errorf("Select() = %v, want select-a and select-b", selected)
+	}
+	if got := Select([]string{"unknown"}); len(got) != 0 {
+		t.Errorf("Select(unknown) = %v, want none", got)
+	}
+	if got, all := Select(nil), All(); len(got) != len(all) {
+		t.Errorf("Select(nil) returned %d ecosystems, want all %d", len(got), len(all))
+	}
+}
+
+func TestEcosystem_MatchPath(t *testing.T) {
+	e := Ecosystem{Patterns: []string{"deps.txt", "deps/*.txt", "*.deps"}}
+
+	tests := map[string]bool{
+		"deps.txt":               true,
+		"project/DEPS.TXT":       true,
+		"project/deps/base.txt":  true,
+		"project/other/base.txt": false,
+		"base.txt":               false,
+		"x/tool.deps":            true,
+	}
+	for path, want := range tests {
+		if got := e.MatchPath(path); got != want {
+			t.Errorf("MatchPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestEcosystem_Sniffable(t *testing.T) {
+	e := Ecosystem{SniffExtensions: []string{".cfg"}, Sniff: func([]byte) bool { return true }}
+	if !e.Sniffable("tools.CFG") {
+		t.Error("expected .CFG to be sniffable")
+	}
+	if e.Sniffable("tools.txt") {
+		t.Error("expected .txt not to be sniffable")
+	}
+	if (Ecosystem{SniffExtensions: []string{".cfg"}}).Sniffable("tools.cfg") {
+		t.Error("expected no sniffing without a Sniff function")
+	}
+}
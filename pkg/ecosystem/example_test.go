@@ -0,0 +1,44 @@
+package ecosystem_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flarebyte/clingy-code-detective/pkg/ecosystem"
+)
+
+// toolsParser parses a file listing one "name version" pair per line.
+type toolsParser struct{}
+
+func (toolsParser) Parse(content []byte) ([]ecosystem.Dependency, error) {
+	var deps []ecosystem.Dependency
+	for i, line := range strings.Split(string(content), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			deps = append(deps, ecosystem.Dependency{Name: fields[0], Version: fields[1], Category: "dev", Line: i + 1})
+		}
+	}
+	return deps, nil
+}
+
+// An in-house format is registered before calling clingy.Main from the
+// main function of a custom binary.
+func ExampleRegister() {
+	err := ecosystem.Register(ecosystem.Ecosystem{
+		Name:      "acme-tools",
+		Aliases:   []string{"tools"},
+		Patterns:  []string{".tool-versions", "tools/*.versions"},
+		NewParser: func() ecosystem.Parser { return toolsParser{} },
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	e, _ := ecosystem.Match("repo/.tool-versions")
+	deps, _ := e.NewParser().Parse([]byte("golang 1.24.1\nnodejs 22.0.0\n"))
+	for _, d := range deps {
+		fmt.Println(e.Name, d.Name, d.Version, d.Line)
+	}
+	// Output:
+	// acme-tools golang 1.24.1 1
+	// acme-tools nodejs 22.0.0 2
+}